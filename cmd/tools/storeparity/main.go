@@ -0,0 +1,167 @@
+// storeparity runs the same scenario against the storage backends and
+// reports where they answer differently. The postgres backend writes to the
+// configured database, point it at a scratch one. go test runs the scenario
+// too, on postgres only when WSRS_PARITY_POSTGRES is set.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/config"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	rawBackends := flag.String("backends", "memory,sqlite", "comma separated backends to compare: memory, sqlite and postgres")
+	verbose := flag.Bool("v", false, "print the results of every backend")
+	flag.Parse()
+
+	backends := strings.Split(*rawBackends, ",")
+	if len(backends) < 2 {
+		fmt.Fprintln(os.Stderr, "at least two backends are needed")
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	// slugs and join codes are unique, the suffix lets the scenario run
+	// again on a database that kept the rows of a previous run
+	suffix := strings.Split(uuid.NewString(), "-")[0]
+
+	results := make([][]string, len(backends))
+	for i, backend := range backends {
+		store, close, err := openStore(ctx, backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not open %s: %v\n", backend, err)
+			os.Exit(2)
+		}
+
+		results[i] = newRecorder().run(ctx, store, suffix)
+		close()
+
+		if *verbose {
+			fmt.Printf("== %s\n%s\n", backend, strings.Join(results[i], "\n"))
+		}
+	}
+
+	differences := 0
+	for i := 1; i < len(backends); i++ {
+		differences += compare(backends[0], results[0], backends[i], results[i])
+	}
+
+	if differences > 0 {
+		fmt.Printf("%d differences\n", differences)
+		os.Exit(1)
+	}
+	fmt.Printf("%s agree on %d steps\n", strings.Join(backends, ", "), len(results[0]))
+}
+
+func openStore(ctx context.Context, backend string) (repositories.Store, func(), error) {
+	switch backend {
+	case "memory":
+		return repositories.NewMemoryStore(), func() {}, nil
+	case "sqlite":
+		dir, err := os.MkdirTemp("", "storeparity")
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+
+		db, err := sqlitestore.Open(filepath.Join(dir, "wsrs.db"))
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+
+		close := func() {
+			db.Close()
+			os.RemoveAll(dir)
+		}
+		if err := sqlitestore.Migrate(ctx, db); err != nil {
+			close()
+			return repositories.Store{}, nil, err
+		}
+		return repositories.NewSQLiteStore(db), close, nil
+	case "postgres":
+		cfg, _, err := config.Load(nil)
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+
+		pool, err := pgxpool.New(ctx, cfg.Database.ConnString())
+
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return repositories.Store{}, nil, err
+		}
+		return repositories.NewPgStore(pool), pool.Close, nil
+	default:
+		return repositories.Store{}, nil, fmt.Errorf("unknown backend %q", backend)
+	}
+}
+
+func compare(leftName string, left []string, rightName string, right []string) int {
+	differences := 0
+	for i := 0; i < len(left) || i < len(right); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if l != r {
+			fmt.Printf("%s: %s\n%s: %s\n\n", leftName, l, rightName, r)
+			differences++
+		}
+	}
+	return differences
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T[0-9:.]+(Z|[+-]\d{2}:\d{2})`)
+)
+
+// recorder turns the result of every step into a line that doesn't depend
+// on the backend: ids are numbered in the order they show up and times are
+// left out, only whether they are set is compared.
+type recorder struct {
+	lines []string
+	ids   map[string]string
+}
+
+func newRecorder() *recorder {
+	return &recorder{ids: make(map[string]string)}
+}
+
+func (r *recorder) record(step string, value any, err error) {
+	var line string
+	if err != nil {
+		line = fmt.Sprintf("%s: %T %v", step, err, err)
+	} else {
+		encoded, encodeErr := json.Marshal(value)
+		if encodeErr != nil {
+			panic(encodeErr)
+		}
+		line = fmt.Sprintf("%s: %s", step, encoded)
+	}
+
+	line = uuidPattern.ReplaceAllStringFunc(line, func(id string) string {
+		if _, ok := r.ids[id]; !ok {
+			r.ids[id] = fmt.Sprintf("#%d", len(r.ids)+1)
+		}
+		return r.ids[id]
+	})
+	r.lines = append(r.lines, timePattern.ReplaceAllString(line, "<time>"))
+}
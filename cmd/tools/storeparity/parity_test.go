@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// postgresEnv turns the postgres backend on, it writes to the database of
+// the configuration, which should be a scratch one.
+const postgresEnv = "WSRS_PARITY_POSTGRES"
+
+// TestStoreParity runs the scenario on every backend and compares it step by
+// step with the memory one.
+func TestStoreParity(t *testing.T) {
+	ctx := context.Background()
+	suffix := strings.Split(uuid.NewString(), "-")[0]
+
+	reference := runScenario(ctx, t, "memory", suffix)
+	if len(reference) == 0 {
+		t.Fatal("the scenario recorded no steps")
+	}
+
+	tests := []struct {
+		backend string
+		env     string
+	}{
+		{backend: "sqlite"},
+		{backend: "postgres", env: postgresEnv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			if tt.env != "" && os.Getenv(tt.env) == "" {
+				t.Skipf("set %s to run against %s", tt.env, tt.backend)
+			}
+
+			results := runScenario(ctx, t, tt.backend, suffix)
+			for i := 0; i < len(reference) || i < len(results); i++ {
+				var want, got string
+				if i < len(reference) {
+					want = reference[i]
+				}
+				if i < len(results) {
+					got = results[i]
+				}
+				if got != want {
+					t.Errorf("step %d\nmemory: %s\n%s: %s", i+1, want, tt.backend, got)
+				}
+			}
+		})
+	}
+}
+
+func runScenario(ctx context.Context, t *testing.T, backend string, suffix string) []string {
+	t.Helper()
+
+	store, close, err := openStore(ctx, backend)
+	if err != nil {
+		t.Fatalf("opening %s: %v", backend, err)
+	}
+	t.Cleanup(close)
+
+	return newRecorder().run(ctx, store, suffix)
+}
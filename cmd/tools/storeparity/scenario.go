@@ -0,0 +1,493 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// run goes through what the services ask of the repositories, including
+// the failures, and returns a line per step.
+func (r *recorder) run(ctx context.Context, store repositories.Store, suffix string) []string {
+	rooms, polls := store.Rooms, store.Polls
+	now := time.Now()
+	startsAt, endsAt := now.Add(-2*time.Hour), now.Add(-time.Hour)
+
+	room, err := rooms.SaveRoom(ctx, &models.Room{
+		Subject:          "Parity",
+		Description:      "Comparing the storage backends",
+		StartsAt:         &startsAt,
+		EndsAt:           &endsAt,
+		HostName:         "Host",
+		Slug:             "parity-" + suffix,
+		JoinCode:         "P" + suffix,
+		ReactionKinds:    []string{"like", "love"},
+		DownvotesEnabled: true,
+		Tags:             []string{"question", "meta"},
+	})
+	r.record("save room", room, err)
+
+	other, err := rooms.SaveRoom(ctx, &models.Room{
+		Subject:  "Other",
+		Slug:     "other-" + suffix,
+		JoinCode: "O" + suffix,
+	})
+	r.record("save other room", other, err)
+
+	_, err = rooms.SaveRoom(ctx, &models.Room{Subject: "Taken", Slug: room.Slug, JoinCode: "T" + suffix})
+	r.record("save room with a taken slug", nil, err)
+
+	_, err = rooms.SaveRoom(ctx, &models.Room{Subject: "Taken", Slug: "taken-" + suffix, JoinCode: room.JoinCode})
+	r.record("save room with a taken join code", nil, err)
+
+	_, err = rooms.SaveRoom(ctx, &models.Room{Subject: "Backwards", Slug: "backwards-" + suffix, JoinCode: "B" + suffix, StartsAt: &endsAt, EndsAt: &startsAt})
+	r.record("save room ending before it starts", nil, err)
+
+	room.Subject = "Parity check"
+	updated, err := rooms.UpdateRoom(ctx, room)
+	r.record("update room", updated, err)
+
+	other.Slug = room.Slug
+	_, err = rooms.UpdateRoom(ctx, other)
+	r.record("update room to a taken slug", nil, err)
+
+	_, err = rooms.UpdateRoom(ctx, &models.Room{ID: uuid.New(), Slug: "missing-" + suffix})
+	r.record("update missing room", nil, err)
+
+	taken, err := rooms.IsRoomSlugTaken(ctx, room.Slug, other.ID)
+	r.record("slug taken by another room", taken, err)
+	taken, err = rooms.IsRoomSlugTaken(ctx, room.Slug, room.ID)
+	r.record("slug taken by the room itself", taken, err)
+	slugs, err := rooms.FindRoomSlugs(ctx, room.Slug, other.ID)
+	r.record("find room slugs", slugs, err)
+
+	found, err := rooms.FindRoomByJoinCode(ctx, room.JoinCode)
+	r.record("find room by join code", found, err)
+	_, err = rooms.FindRoomByJoinCode(ctx, "missing-"+suffix)
+	r.record("find room by missing join code", nil, err)
+
+	found, err = rooms.UpdateRoomJoinCode(ctx, other.ID, "N"+suffix)
+	r.record("update join code", found, err)
+	_, err = rooms.UpdateRoomJoinCode(ctx, other.ID, room.JoinCode)
+	r.record("update join code to a taken one", nil, err)
+
+	first, err := rooms.SaveMessage(ctx, &request.MessageRequest{RoomID: room.ID, Message: "first", Tag: "question"}, []string{"pii"})
+	r.record("save message", first, err)
+	second, err := rooms.SaveMessage(ctx, &request.MessageRequest{RoomID: room.ID, Message: "second"}, nil)
+	r.record("save untagged message", second, err)
+	_, err = rooms.SaveMessage(ctx, &request.MessageRequest{RoomID: uuid.New(), Message: "lost"}, nil)
+	r.record("save message in a missing room", nil, err)
+
+	imported, err := rooms.SaveMessages(ctx, room.ID, []string{"one", "two", "three"}, []string{"meta", "", "question"}, [][]string{nil, {"links", "profanity"}, nil})
+	r.record("save messages", imported, err)
+	_, err = rooms.SaveMessages(ctx, uuid.New(), []string{"lost"}, []string{""}, [][]string{nil})
+	r.record("save messages in a missing room", nil, err)
+
+	count, err := rooms.ReactToMessage(ctx, first, "like")
+	r.record("like", count, err)
+	count, err = rooms.ReactToMessage(ctx, first, "like")
+	r.record("like again", count, err)
+	count, err = rooms.ReactToMessage(ctx, first, "love")
+	r.record("love", count, err)
+	count, err = rooms.RemoveReactionFromMessage(ctx, first, "like")
+	r.record("remove like", count, err)
+	count, err = rooms.RemoveReactionFromMessage(ctx, second, "love")
+	r.record("remove reaction never given", count, err)
+	_, err = rooms.ReactToMessage(ctx, uuid.New(), "like")
+	r.record("like missing message", nil, err)
+
+	count, err = rooms.DownvoteMessage(ctx, second)
+	r.record("downvote", count, err)
+	count, err = rooms.RemoveDownvoteFromMessage(ctx, second)
+	r.record("remove downvote", count, err)
+	count, err = rooms.RemoveDownvoteFromMessage(ctx, second)
+	r.record("remove downvote never given", count, err)
+	_, err = rooms.DownvoteMessage(ctx, uuid.New())
+	r.record("downvote missing message", nil, err)
+
+	message, err := rooms.SetMessagePinned(ctx, second, true)
+	r.record("pin message", message, err)
+	_, err = rooms.SetMessagePinned(ctx, uuid.New(), true)
+	r.record("pin missing message", nil, err)
+	message, err = rooms.SetMessageHidden(ctx, second, true)
+	r.record("hide message", message, err)
+	_, err = rooms.SetMessageHidden(ctx, uuid.New(), true)
+	r.record("hide missing message", nil, err)
+	err = rooms.MarkMessageAsAnswered(ctx, first)
+	r.record("mark message as answered", nil, err)
+	recounted, err := rooms.RecountMessageLikes(ctx, room.ID)
+	r.record("recount likes", recounted, err)
+
+	message, err = rooms.FindMessage(ctx, first)
+	r.record("find message", message, err)
+	_, err = rooms.FindMessage(ctx, uuid.New())
+	r.record("find missing message", nil, err)
+	reactions, err := rooms.FindMessageReactions(ctx, second)
+	r.record("find reactions of message without any", reactions, err)
+
+	found, err = rooms.UpdateRoomSpotlight(ctx, room.ID, &first)
+	r.record("spotlight message", found, err)
+	missing := uuid.New()
+	_, err = rooms.UpdateRoomSpotlight(ctx, room.ID, &missing)
+	r.record("spotlight missing message", nil, err)
+
+	closedRoom, err := rooms.CloseRoom(ctx, room.ID)
+	r.record("close room", closedRoom, err)
+	found, err = rooms.CloseRoom(ctx, room.ID)
+	r.record("close closed room", found.ClosedAt != nil && closedRoom.ClosedAt != nil && found.ClosedAt.Equal(*closedRoom.ClosedAt), err)
+	found, err = rooms.ReopenRoom(ctx, room.ID)
+	r.record("reopen room", found, err)
+	_, err = rooms.CloseRoom(ctx, uuid.New())
+	r.record("close missing room", nil, err)
+	_, err = rooms.ReopenRoom(ctx, uuid.New())
+	r.record("reopen missing room", nil, err)
+
+	searched, err := rooms.SearchRooms(ctx, "PARITY-"+suffix, "")
+	r.record("search rooms", searched, err)
+	searched, err = rooms.SearchRooms(ctx, "nothing-"+suffix, "N"+suffix)
+	r.record("search rooms by join code", searched, err)
+
+	messages, err := rooms.FindAllRoomMessages(ctx, room.ID, "")
+	r.record("find room messages", messages, err)
+	messages, err = rooms.FindAllRoomMessages(ctx, room.ID, "question")
+	r.record("find room messages by tag", messages, err)
+
+	streamed := []string{}
+	err = rooms.StreamRoomMessages(ctx, room.ID, func(message *models.Message) error {
+		streamed = append(streamed, message.Message)
+		return nil
+	})
+	r.record("stream room messages", streamed, err)
+
+	tagCounts, err := rooms.FindRoomsTagCounts(ctx, []uuid.UUID{room.ID, other.ID})
+	r.record("count room tags", tagCounts, err)
+
+	poll, err := polls.SavePoll(ctx, room.ID, "Which backend?", []string{"postgres", "sqlite", "memory"})
+	r.record("save poll", poll, err)
+	_, err = polls.SavePoll(ctx, uuid.New(), "Lost?", []string{"yes", "no"})
+	r.record("save poll in a missing room", nil, err)
+
+	if len(poll.Options) > 1 {
+		voted, err := polls.SaveVote(ctx, poll.ID, poll.Options[1].ID, "participant")
+		r.record("vote", voted, err)
+		voted, err = polls.SaveVote(ctx, poll.ID, poll.Options[0].ID, "participant")
+		r.record("vote again", voted, err)
+	}
+	_, err = polls.SaveVote(ctx, uuid.New(), uuid.New(), "participant")
+	r.record("vote in a missing poll", nil, err)
+
+	closedPoll, err := polls.ClosePoll(ctx, poll.ID)
+	r.record("close poll", closedPoll, err)
+	_, err = polls.ClosePoll(ctx, uuid.New())
+	r.record("close missing poll", nil, err)
+	roomPolls, err := polls.FindAllRoomPolls(ctx, room.ID)
+	r.record("find room polls", roomPolls, err)
+
+	rollback := errors.New("rollback")
+	var rolledBack uuid.UUID
+	err = store.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		locked, err := rooms.LockRoom(ctx, room.ID)
+		if err != nil {
+			return err
+		}
+		rolledBack, err = rooms.SaveMessage(ctx, &request.MessageRequest{RoomID: locked.ID, Message: "rolled back"}, nil)
+		if err != nil {
+			return err
+		}
+		return rollback
+	})
+	r.record("unit of work failing", errors.Is(err, rollback), nil)
+	_, err = rooms.FindMessage(ctx, rolledBack)
+	r.record("find message of a failed unit of work", nil, err)
+
+	var committed uuid.UUID
+	err = store.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		// the nested unit of work joins the outer one
+		return store.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			var err error
+			committed, err = rooms.SaveMessage(ctx, &request.MessageRequest{RoomID: room.ID, Message: "committed"}, nil)
+			return err
+		})
+	})
+	r.record("nested unit of work", nil, err)
+	message, err = rooms.FindMessage(ctx, committed)
+	r.record("find message of a nested unit of work", message, err)
+
+	err = store.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := store.Outbox.SaveEvent(ctx, room.ID, "rolled_back", []byte(`{}`)); err != nil {
+			return err
+		}
+		return rollback
+	})
+	r.record("save event in a failing unit of work", errors.Is(err, rollback), nil)
+
+	err = store.Outbox.SaveEvent(ctx, room.ID, "first", []byte(`{"n":1}`))
+	r.record("save event", nil, err)
+	err = store.Outbox.SaveEvent(ctx, room.ID, "second", []byte(`{"n":2}`))
+	r.record("save another event", nil, err)
+
+	events, err := pendingEvents(ctx, store.Outbox, room.ID)
+	r.record("find pending events", events, err)
+	claimedEvents, err := claimEvents(ctx, store.Outbox, room.ID)
+	r.record("claim pending events", claimedEvents, err)
+	claimedEvents, err = claimEvents(ctx, store.Outbox, room.ID)
+	r.record("claim claimed events", claimedEvents, err)
+	if len(events) == 2 {
+		err = store.Outbox.ReleaseEvents(ctx, []int64{events[1].ID})
+		r.record("release event", nil, err)
+		claimedEvents, err = claimEvents(ctx, store.Outbox, room.ID)
+		r.record("claim released event", claimedEvents, err)
+
+		err = store.Outbox.MarkEventFailed(ctx, events[0].ID, 0)
+		r.record("mark event failed", nil, err)
+		err = store.Outbox.MarkEventFailed(ctx, events[0].ID, 0)
+		r.record("mark event failed again", nil, err)
+		err = store.Outbox.MarkEventFailed(ctx, events[0].ID, 2)
+		r.record("mark event failed for a later subscriber", nil, err)
+		claimedEvents, err = claimEvents(ctx, store.Outbox, room.ID)
+		r.record("claim failed event", claimedEvents, err)
+		err = store.Outbox.MarkEventDispatched(ctx, events[1].ID)
+		r.record("mark event dispatched", nil, err)
+
+		events, err = pendingEvents(ctx, store.Outbox, room.ID)
+		r.record("find pending events after dispatching", events, err)
+	}
+	_, err = store.Outbox.DeleteDispatchedEvents(ctx, time.Now().Add(time.Minute))
+	r.record("delete dispatched events", nil, err)
+
+	webhook, err := store.Webhooks.SaveWebhook(ctx, &models.Webhook{RoomID: &room.ID, URL: "http://localhost/hook", EventKinds: []string{"message_created"}, Secret: "room-secret"})
+	r.record("save room webhook", webhook, err)
+	global, err := store.Webhooks.SaveWebhook(ctx, &models.Webhook{URL: "http://localhost/global", EventKinds: []string{}, Secret: "global-secret"})
+	r.record("save global webhook", global, err)
+	otherWebhook, err := store.Webhooks.SaveWebhook(ctx, &models.Webhook{RoomID: &other.ID, URL: "http://localhost/other", EventKinds: []string{}, Secret: "other-secret"})
+	r.record("save webhook of other room", otherWebhook, err)
+	missingRoom := uuid.New()
+	_, err = store.Webhooks.SaveWebhook(ctx, &models.Webhook{RoomID: &missingRoom, URL: "http://localhost/missing", EventKinds: []string{}, Secret: "secret"})
+	r.record("save webhook of missing room", nil, err)
+
+	ownWebhooks := []uuid.UUID{webhook.ID, global.ID, otherWebhook.ID}
+	foundWebhook, err := store.Webhooks.FindWebhook(ctx, webhook.ID)
+	r.record("find webhook", foundWebhook, err)
+	webhooks, err := store.Webhooks.FindWebhooks(ctx, &room.ID)
+	r.record("find room webhooks", webhooks, err)
+	webhooks, err = store.Webhooks.FindWebhooks(ctx, nil)
+	r.record("find global webhooks", ownOf(webhooks, ownWebhooks), err)
+	webhooks, err = store.Webhooks.FindEventWebhooks(ctx, room.ID)
+	r.record("find event webhooks", ownOf(webhooks, ownWebhooks), err)
+
+	err = store.Webhooks.SaveDelivery(ctx, webhook.ID, "message_created", []byte(`{"n":1}`))
+	r.record("save delivery", nil, err)
+	err = store.Webhooks.SaveDelivery(ctx, otherWebhook.ID, "room_updated", []byte(`{"n":2}`))
+	r.record("save delivery of other webhook", nil, err)
+	err = store.Webhooks.SaveDelivery(ctx, uuid.New(), "message_created", []byte(`{}`))
+	r.record("save delivery of missing webhook", nil, err)
+
+	claimed, err := store.Webhooks.ClaimDueDeliveries(ctx, time.Now().Add(time.Minute), 1000)
+	deliveries := ownDeliveries(claimed, ownWebhooks)
+	r.record("claim due deliveries", deliveries, err)
+	claimed, err = store.Webhooks.ClaimDueDeliveries(ctx, time.Now().Add(time.Minute), 1000)
+	r.record("claim leased deliveries", ownDeliveries(claimed, ownWebhooks), err)
+	if len(deliveries) == 2 {
+		status := int32(500)
+		err = store.Webhooks.RetryDelivery(ctx, deliveries[0].ID, &status, "receiver answered 500", time.Now().Add(-time.Second))
+		r.record("retry delivery", nil, err)
+		claimed, err = store.Webhooks.ClaimDueDeliveries(ctx, time.Now().Add(time.Minute), 1000)
+		r.record("claim retried deliveries", ownDeliveries(claimed, ownWebhooks), err)
+
+		status = 204
+		err = store.Webhooks.CompleteDelivery(ctx, deliveries[0].ID, models.WebhookDeliveryDelivered, &status, "")
+		r.record("complete delivery", nil, err)
+	}
+	logged, err := store.Webhooks.FindDeliveries(ctx, webhook.ID, "", 10)
+	r.record("find deliveries", ownDeliveries(logged, ownWebhooks), err)
+	logged, err = store.Webhooks.FindDeliveries(ctx, webhook.ID, models.WebhookDeliveryPending, 10)
+	r.record("find pending deliveries", ownDeliveries(logged, ownWebhooks), err)
+	_, err = store.Webhooks.DeleteCompletedDeliveries(ctx, time.Now().Add(time.Minute))
+	r.record("delete completed deliveries", nil, err)
+	logged, err = store.Webhooks.FindDeliveries(ctx, webhook.ID, "", 10)
+	r.record("find deliveries after deleting completed ones", ownDeliveries(logged, ownWebhooks), err)
+
+	err = store.Webhooks.DeleteWebhook(ctx, global.ID)
+	r.record("delete webhook", nil, err)
+	err = store.Webhooks.DeleteWebhook(ctx, global.ID)
+	r.record("delete deleted webhook", nil, err)
+
+	apiKey, err := store.APIKeys.SaveAPIKey(ctx, &models.APIKey{Name: "parity-" + suffix, Prefix: "wsrs_" + suffix, KeyHash: "hash-" + suffix})
+	r.record("save API key", apiKey, err)
+	_, err = store.APIKeys.SaveAPIKey(ctx, &models.APIKey{Name: "taken", Prefix: "wsrs_" + suffix, KeyHash: "hash-" + suffix})
+	r.record("save API key with a taken hash", nil, err)
+	foundKey, err := store.APIKeys.FindAPIKeyByHash(ctx, "hash-"+suffix)
+	r.record("find API key by hash", foundKey, err)
+	_, err = store.APIKeys.FindAPIKeyByHash(ctx, "missing-"+suffix)
+	r.record("find API key by missing hash", nil, err)
+	apiKeys, err := store.APIKeys.FindAPIKeys(ctx)
+	r.record("find API keys", slices.ContainsFunc(apiKeys, func(key models.APIKey) bool { return key.ID == apiKey.ID }), err)
+	err = store.APIKeys.DeleteAPIKey(ctx, apiKey.ID)
+	r.record("delete API key", nil, err)
+	err = store.APIKeys.DeleteAPIKey(ctx, apiKey.ID)
+	r.record("delete deleted API key", nil, err)
+
+	err = rooms.DeleteRoom(ctx, other.ID)
+	r.record("delete room", nil, err)
+	err = rooms.DeleteRoom(ctx, other.ID)
+	r.record("delete deleted room", nil, err)
+	_, err = store.Webhooks.FindWebhook(ctx, otherWebhook.ID)
+	r.record("find webhook of deleted room", nil, err)
+	logged, err = store.Webhooks.FindDeliveries(ctx, otherWebhook.ID, "", 10)
+	r.record("find deliveries of deleted webhook", logged, err)
+
+	// the lifecycle statements also touch the rows other runs left behind,
+	// so only their effect on this room is compared
+	closed, err := rooms.CloseEndedRooms(ctx, now)
+	found, findErr := rooms.FindRoom(ctx, room.ID)
+	r.record("close ended rooms", found.ClosedAt != nil && slices.Contains(closed, room.ID), firstError(err, findErr))
+
+	archivedBefore := time.Now().Add(time.Minute)
+	_, err = rooms.AnonymizeArchivedRooms(ctx, archivedBefore)
+	found, findErr = rooms.FindRoom(ctx, room.ID)
+	r.record("anonymize archived rooms", found, firstError(err, findErr))
+	messages, err = rooms.FindAllRoomMessages(ctx, room.ID, "")
+	r.record("find anonymized messages", messages, err)
+	anonymizedPoll, err := polls.FindPoll(ctx, poll.ID)
+	r.record("find anonymized poll", anonymizedPoll, err)
+
+	deleted, err := rooms.DeleteArchivedRooms(ctx, archivedBefore)
+	r.record("delete archived rooms", slices.Contains(deleted, room.ID), err)
+	_, err = rooms.FindRoom(ctx, room.ID)
+	r.record("find deleted room", nil, err)
+	_, err = polls.FindPoll(ctx, poll.ID)
+	r.record("find poll of deleted room", nil, err)
+
+	// a cutoff ahead of the clock, so the rooms created now count as idle
+	idle, err := rooms.SaveRoom(ctx, &models.Room{Subject: "Idle", Slug: "idle-" + suffix, JoinCode: "I" + suffix})
+	r.record("save idle room", idle, err)
+	upcomingStart, upcomingEnd := now.Add(30*time.Minute), now.Add(2*time.Hour)
+	upcoming, err := rooms.SaveRoom(ctx, &models.Room{Subject: "Upcoming", Slug: "upcoming-" + suffix, JoinCode: "U" + suffix, StartsAt: &upcomingStart, EndsAt: &upcomingEnd})
+	r.record("save upcoming room", upcoming, err)
+	closed, err = rooms.CloseInactiveRooms(ctx, now.Add(time.Hour))
+	r.record("close inactive rooms", []bool{slices.Contains(closed, idle.ID), slices.Contains(closed, upcoming.ID)}, err)
+	r.record("delete idle room", nil, rooms.DeleteRoom(ctx, idle.ID))
+	r.record("delete upcoming room", nil, rooms.DeleteRoom(ctx, upcoming.ID))
+
+	return r.lines
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parityEvent leaves out the id and the time of an event, the id sequence
+// of a database keeps counting across runs. The payload is compacted when
+// recorded, Postgres reformats JSONB.
+type parityEvent struct {
+	ID       int64 `json:"-"`
+	Kind     string
+	Payload  json.RawMessage
+	Attempts int32
+	Handled  int32
+}
+
+// pendingEvents leaves out the events other runs left behind in the
+// database.
+func pendingEvents(ctx context.Context, outbox repositories.OutboxRepository, roomId uuid.UUID) ([]parityEvent, error) {
+	events, err := outbox.FindPendingEvents(ctx, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	roomEvents := []parityEvent{}
+	for _, event := range events {
+		if event.RoomID == roomId {
+			roomEvents = append(roomEvents, parityEvent{
+				ID:       event.ID,
+				Kind:     event.Kind,
+				Payload:  event.Payload,
+				Attempts: event.Attempts,
+				Handled:  event.Handled,
+			})
+		}
+	}
+	return roomEvents, nil
+}
+
+// claimEvents claims the pending events, those of other runs included, and
+// keeps the ones of the room.
+func claimEvents(ctx context.Context, outbox repositories.OutboxRepository, roomId uuid.UUID) ([]parityEvent, error) {
+	events, err := outbox.ClaimPendingEvents(ctx, time.Now().Add(time.Minute), 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	roomEvents := []parityEvent{}
+	for _, event := range events {
+		if event.RoomID == roomId {
+			roomEvents = append(roomEvents, parityEvent{
+				ID:       event.ID,
+				Kind:     event.Kind,
+				Payload:  event.Payload,
+				Attempts: event.Attempts,
+				Handled:  event.Handled,
+			})
+		}
+	}
+	return roomEvents, nil
+}
+
+// parityDelivery leaves out when a delivery is due, the lease is counted
+// from the clock of each run. The payload is compacted like the one of
+// parityEvent.
+type parityDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventKind      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	ResponseStatus *int32
+	LastError      string
+	CompletedAt    *time.Time
+}
+
+// ownOf and ownDeliveries leave out the rows other runs left behind in the
+// database.
+func ownOf(webhooks []models.Webhook, own []uuid.UUID) []models.Webhook {
+	ownWebhooks := []models.Webhook{}
+	for _, webhook := range webhooks {
+		if slices.Contains(own, webhook.ID) {
+			ownWebhooks = append(ownWebhooks, webhook)
+		}
+	}
+	return ownWebhooks
+}
+
+func ownDeliveries(deliveries []models.WebhookDelivery, own []uuid.UUID) []parityDelivery {
+	ownDeliveries := []parityDelivery{}
+	for _, delivery := range deliveries {
+		if slices.Contains(own, delivery.WebhookID) {
+			ownDeliveries = append(ownDeliveries, parityDelivery{
+				ID:             delivery.ID,
+				WebhookID:      delivery.WebhookID,
+				EventKind:      delivery.EventKind,
+				Payload:        delivery.Payload,
+				Status:         delivery.Status,
+				Attempts:       delivery.Attempts,
+				ResponseStatus: delivery.ResponseStatus,
+				LastError:      delivery.LastError,
+				CompletedAt:    delivery.CompletedAt,
+			})
+		}
+	}
+	return ownDeliveries
+}
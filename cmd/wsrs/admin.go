@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/config"
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/exporter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/importer"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+	"github.com/JulioZittei/wsrs-ama-go/internal/outbox"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/JulioZittei/wsrs-ama-go/internal/services"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errInvalidArgument = errors.New("invalid argument")
+
+// admin runs the admin commands with the services the server uses, so the
+// events they publish reach the participants once the server dispatches
+// them.
+type admin struct {
+	rooms       *services.RoomsService
+	apiKeys     *services.APIKeysService
+	grantSigner *access.GrantSigner
+	output      *output
+}
+
+func runAdmin(ctx context.Context, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("admin", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	args = flags.Args()
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	store, release, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	filters, err := contentfilter.New(cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	messageMapper := mappers.MessageMapper{}
+	roomMapper := mappers.RoomMapper{}
+	grantSigner := access.NewGrantSigner([]byte(cfg.AccessSecret))
+	a := &admin{
+		rooms: services.NewRoomsService(store.Rooms, outbox.NewPublisher(store.Outbox), store.UnitOfWork,
+			&roomMapper, &messageMapper, grantSigner, filters),
+		apiKeys:     services.NewAPIKeysService(store.APIKeys, &mappers.APIKeyMapper{}),
+		grantSigner: grantSigner,
+		output:      &output{w: os.Stdout, json: *asJSON},
+	}
+
+	command, args := args[0]+" "+args[1], args[2:]
+	switch command {
+	case "rooms list":
+		if len(args) != 0 {
+			return errUsage
+		}
+		return a.searchRooms(ctx, "")
+	case "rooms search":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return a.searchRooms(ctx, args[0])
+	case "rooms stats":
+		return a.roomStats(ctx, args)
+	case "rooms close":
+		return a.closeRoom(ctx, args, false)
+	case "rooms reopen":
+		return a.closeRoom(ctx, args, true)
+	case "messages hide":
+		return a.hideMessage(ctx, args, true)
+	case "messages unhide":
+		return a.hideMessage(ctx, args, false)
+	case "likes rebuild":
+		return a.rebuildLikes(ctx, args)
+	case "rooms export":
+		return a.exportRoom(ctx, args)
+	case "rooms import":
+		return a.importRoom(ctx, args)
+	case "keys create":
+		return a.createKey(ctx, args)
+	case "keys list":
+		return a.listKeys(ctx, args)
+	case "keys revoke":
+		return a.revokeKey(ctx, args)
+	default:
+		return errUsage
+	}
+}
+
+func (a *admin) searchRooms(ctx context.Context, query string) error {
+	rooms, err := a.rooms.SearchRooms(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(rooms))
+	for i, room := range rooms {
+		rows[i] = []string{room.ID, room.Subject, room.Slug, room.JoinCode, strconv.FormatBool(room.Private), formatTime(room.ClosedAt)}
+	}
+	return a.output.print(rooms, []string{"ID", "SUBJECT", "SLUG", "JOIN CODE", "PRIVATE", "CLOSED AT"}, rows)
+}
+
+func (a *admin) roomStats(ctx context.Context, args []string) error {
+	roomId, err := parseIds(args, "room")
+	if err != nil {
+		return err
+	}
+
+	stats, err := a.rooms.GetRoomStats(ctx, roomId[0])
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{
+		{"messages", strconv.FormatInt(stats.MessagesCount, 10)},
+		{"answered", strconv.FormatInt(stats.AnsweredCount, 10)},
+		{"pinned", strconv.FormatInt(stats.PinnedCount, 10)},
+		{"flagged", strconv.FormatInt(stats.FlaggedCount, 10)},
+		{"hidden", strconv.FormatInt(stats.HiddenCount, 10)},
+		{"likes", strconv.FormatInt(stats.LikesCount, 10)},
+		{"downvotes", strconv.FormatInt(stats.DownvotesCount, 10)},
+	}
+	kinds := make([]string, 0, len(stats.Reactions))
+	for kind := range stats.Reactions {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		rows = append(rows, []string{"reactions " + kind, strconv.FormatInt(stats.Reactions[kind], 10)})
+	}
+	rows = append(rows, []string{"last message at", formatTime(stats.LastMessageAt)})
+	return a.output.print(stats, []string{"STAT", "VALUE"}, rows)
+}
+
+func (a *admin) closeRoom(ctx context.Context, args []string, reopen bool) error {
+	roomId, err := parseIds(args, "room")
+	if err != nil {
+		return err
+	}
+
+	var room *response.RoomResponse
+	if reopen {
+		room, err = a.rooms.ReopenRoom(ctx, roomId[0])
+	} else {
+		room, err = a.rooms.CloseRoom(ctx, roomId[0])
+	}
+	if err != nil {
+		return err
+	}
+	return a.output.print(room, []string{"ID", "SUBJECT", "CLOSED AT"},
+		[][]string{{room.ID, room.Subject, formatTime(room.ClosedAt)}})
+}
+
+func (a *admin) hideMessage(ctx context.Context, args []string, hidden bool) error {
+	ids, err := parseIds(args, "room", "message")
+	if err != nil {
+		return err
+	}
+
+	message, err := a.rooms.HideRoomMessage(ctx, ids[0], ids[1], hidden)
+	if err != nil {
+		return err
+	}
+	return a.output.print(message, []string{"ID", "MESSAGE", "HIDDEN"},
+		[][]string{{message.ID, message.Message, strconv.FormatBool(message.Hidden)}})
+}
+
+// rebuildLikes rebuilds the like counts of a room, or of every room when
+// none is given.
+func (a *admin) rebuildLikes(ctx context.Context, args []string) error {
+	var roomIds []uuid.UUID
+	if len(args) == 0 {
+		rooms, err := a.rooms.SearchRooms(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, room := range rooms {
+			roomIds = append(roomIds, uuid.MustParse(room.ID))
+		}
+	} else {
+		ids, err := parseIds(args, "room")
+		if err != nil {
+			return err
+		}
+		roomIds = ids
+	}
+
+	type rebuilt struct {
+		RoomID        string `json:"room_id"`
+		MessagesFixed int64  `json:"messages_fixed"`
+	}
+	results := make([]rebuilt, 0, len(roomIds))
+	rows := make([][]string, 0, len(roomIds))
+	for _, roomId := range roomIds {
+		fixed, err := a.rooms.RebuildLikeCounts(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		results = append(results, rebuilt{RoomID: roomId.String(), MessagesFixed: fixed})
+		rows = append(rows, []string{roomId.String(), strconv.FormatInt(fixed, 10)})
+	}
+	return a.output.print(results, []string{"ROOM", "MESSAGES FIXED"}, rows)
+}
+
+func (a *admin) exportRoom(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	rawFormat := flags.String("format", "json", "export format: json, csv or md")
+	outputPath := flags.String("out", "", "file to write the export to (defaults to stdout)")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	roomId, err := parseIds(flags.Args(), "room")
+	if err != nil {
+		return err
+	}
+	format, ok := exporter.ParseFormat(*rawFormat)
+	if !ok {
+		return fmt.Errorf("%w: export format %q", errInvalidArgument, *rawFormat)
+	}
+
+	var w io.Writer = a.output.w
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	// the command has direct database access anyway, so it grants itself
+	// access to passcode protected rooms
+	ctx = context.WithValue(ctx, middlewares.RoomAccessKey, a.grantSigner.Issue(roomId[0], access.RoleParticipant, time.Now().Add(time.Hour)))
+	return a.rooms.ExportRoom(ctx, roomId[0], format, w)
+}
+
+// importRoom imports the questions of a file into a room. It fails when a
+// row of the file was left out.
+func (a *admin) importRoom(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	rawFormat := flags.String("format", "", "import format: json or csv (defaults to the file extension)")
+	notify := flags.Bool("notify", false, "record message_created events, the running server delivers them to the subscribers")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+
+	inputPath := flags.Arg(0)
+	roomId, err := parseIds(flags.Args()[1:], "room")
+	if err != nil {
+		return err
+	}
+
+	if *rawFormat == "" {
+		*rawFormat = strings.TrimPrefix(filepath.Ext(inputPath), ".")
+	}
+	format, ok := importer.ParseFormat(*rawFormat)
+	if !ok {
+		return fmt.Errorf("%w: import format %q", errInvalidArgument, *rawFormat)
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// the command has direct database access anyway, so it grants itself
+	// access to passcode protected rooms
+	ctx = context.WithValue(ctx, middlewares.RoomAccessKey, a.grantSigner.Issue(roomId[0], access.RoleParticipant, time.Now().Add(time.Hour)))
+	result, err := a.rooms.ImportRoomMessages(ctx, roomId[0], format, file, *notify)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{"imported", strconv.Itoa(result.Imported)}, {"failed", strconv.Itoa(result.Failed)}}
+	for _, rowError := range result.Errors {
+		for _, param := range rowError.InvalidParams {
+			rows = append(rows, []string{"row " + strconv.Itoa(rowError.Row), param.Param + " " + param.Message})
+		}
+	}
+	if err := a.output.print(result, []string{"ROWS", "RESULT"}, rows); err != nil {
+		return err
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d rows were not imported", result.Failed, result.Failed+result.Imported)
+	}
+	return nil
+}
+
+func (a *admin) createKey(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	apiKey, err := a.apiKeys.CreateAPIKey(ctx, &request.APIKeyRequest{Name: args[0]})
+	if err != nil {
+		return err
+	}
+	return a.output.print(apiKey, []string{"ID", "NAME", "KEY"},
+		[][]string{{apiKey.ID, apiKey.Name, apiKey.Key}})
+}
+
+func (a *admin) listKeys(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	apiKeys, err := a.apiKeys.GetAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		rows[i] = []string{apiKey.ID, apiKey.Name, apiKey.Prefix + "...", formatTime(&apiKey.CreatedAt)}
+	}
+	return a.output.print(apiKeys, []string{"ID", "NAME", "KEY", "CREATED AT"}, rows)
+}
+
+func (a *admin) revokeKey(ctx context.Context, args []string) error {
+	apiKeyId, err := parseIds(args, "API key")
+	if err != nil {
+		return err
+	}
+
+	if err := a.apiKeys.DeleteAPIKey(ctx, apiKeyId[0]); err != nil {
+		return err
+	}
+
+	revoked := struct {
+		ID      string `json:"id"`
+		Revoked bool   `json:"revoked"`
+	}{ID: apiKeyId[0].String(), Revoked: true}
+	return a.output.print(revoked, []string{"ID", "REVOKED"}, [][]string{{revoked.ID, "true"}})
+}
+
+// parseIds parses one id per name given, args must hold exactly that many.
+func parseIds(args []string, names ...string) ([]uuid.UUID, error) {
+	if len(args) != len(names) {
+		return nil, errUsage
+	}
+
+	ids := make([]uuid.UUID, len(args))
+	for i, arg := range args {
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s id %q", errInvalidArgument, names[i], arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// output prints the result of a command as a table, or as the JSON the API
+// would answer with.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func (o *output) print(value any, header []string, rows [][]string) error {
+	if o.json {
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	table := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
+
+// openStore opens the store of the configuration without migrating it. The
+// memory store is left out, it would only hold what the command creates.
+func openStore(ctx context.Context, cfg config.Config) (repositories.Store, func(), error) {
+	switch cfg.Store {
+	case config.StorePostgres:
+		pool, err := pgxpool.New(ctx, cfg.Database.ConnString())
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+		return repositories.NewPgStore(pool), pool.Close, nil
+	case config.StoreSQLite:
+		db, err := sqlitestore.Open(cfg.SQLite.Path)
+		if err != nil {
+			return repositories.Store{}, nil, err
+		}
+		return repositories.NewSQLiteStore(db), func() { db.Close() }, nil
+	default:
+		return repositories.Store{}, nil, fmt.Errorf("the %s store is not shared with the server, use postgres or sqlite", cfg.Store)
+	}
+}
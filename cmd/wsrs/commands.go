@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/config"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/migrate"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const commandsUsage = `
+Without a command the server is started. The flags go before the command.
+
+  migrate up          apply the migrations not applied yet
+  migrate down        revert the last migration
+  migrate to N        apply or revert the migrations until version N
+  migrate status      list the migrations and the version of the schema
+
+The admin commands work on the postgres or sqlite store the server uses and
+print a table, or JSON with admin -json.
+
+  admin rooms list                     list every room, the private ones too
+  admin rooms search TEXT              find rooms by subject, slug, host or join code
+  admin rooms stats ROOM               count the messages of a room and their reactions
+  admin rooms close ROOM               close a room
+  admin rooms reopen ROOM              reopen a closed room
+  admin rooms export [-format F] [-out FILE] ROOM
+                                       export a room as json, csv or md
+  admin rooms import [-format F] [-notify] FILE ROOM
+                                       import the questions of a json or csv file
+  admin messages hide ROOM MESSAGE     take a message out of its room
+  admin messages unhide ROOM MESSAGE   put a hidden message back
+  admin likes rebuild [ROOM]           recount the likes of a room, or of every room
+  admin keys create NAME               create an API key, shown only this once
+  admin keys list                      list the API keys
+  admin keys revoke KEY                delete an API key
+`
+
+var errUsage = errors.New("unknown command, run wsrs -h to list them")
+
+// runCommand runs the command given after the flags and returns the exit
+// code.
+func runCommand(ctx context.Context, cfg config.Config, args []string) int {
+	var err error
+	switch args[0] {
+	case "migrate":
+		err = runMigrate(ctx, cfg, args[1:])
+	case "admin":
+		err = runAdmin(ctx, cfg, args[1:])
+	default:
+		err = errUsage
+	}
+
+	if errors.Is(err, errUsage) || errors.Is(err, errInvalidArgument) || errors.Is(err, migrate.ErrInvalidVersion) {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		var errValidation *internal_errors.ErrorValidation
+		if errors.As(err, &errValidation) {
+			for _, param := range errValidation.ErrorsParam {
+				fmt.Fprintf(os.Stderr, "  %s %s\n", param.Param, param.Message)
+			}
+		}
+		return 1
+	}
+	return 0
+}
+
+func runMigrate(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	var target int
+	switch args[0] {
+	case "up", "down", "status":
+		if len(args) != 1 {
+			return errUsage
+		}
+	case "to":
+		if len(args) != 2 {
+			return errUsage
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("%w: %q", migrate.ErrInvalidVersion, args[1])
+		}
+		target = version
+	default:
+		return errUsage
+	}
+
+	migrator, release, err := openMigrator(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	from, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		err = migrator.To(ctx, target)
+	case "status":
+		printStatus(migrator, from)
+		return nil
+	}
+
+	// the migrations applied before a failure stay applied
+	to, versionErr := migrator.Version(ctx)
+	if versionErr != nil {
+		return errors.Join(err, versionErr)
+	}
+	if from != to {
+		fmt.Printf("schema migrated from version %d to %d of %d\n", from, to, migrator.Latest())
+	} else if err == nil {
+		fmt.Printf("schema at version %d of %d, nothing to migrate\n", to, migrator.Latest())
+	}
+	return err
+}
+
+func printStatus(migrator *migrate.Migrator, version int) {
+	for _, migration := range migrator.Migrations() {
+		state := "pending"
+		if migration.Version <= version {
+			state = "applied"
+		}
+		fmt.Printf("%-8s %s\n", state, migration.Name)
+	}
+	fmt.Printf("schema at version %d of %d\n", version, migrator.Latest())
+}
+
+// openMigrator connects to the database of the configured store. release
+// closes the connection.
+func openMigrator(ctx context.Context, cfg config.Config) (*migrate.Migrator, func(), error) {
+	switch cfg.Store {
+	case config.StorePostgres:
+		pool, err := pgxpool.New(ctx, cfg.Database.ConnString())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		migrator, release, err := pgstore.NewMigrator(ctx, pool)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		return migrator, func() {
+			release()
+			pool.Close()
+		}, nil
+	case config.StoreSQLite:
+		db, err := sqlitestore.Open(cfg.SQLite.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		migrator, err := sqlitestore.NewMigrator(ctx, db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return migrator, func() { db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("the %s store has no schema to migrate", cfg.Store)
+	}
+}
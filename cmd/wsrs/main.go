@@ -3,18 +3,20 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	_ "github.com/JulioZittei/wsrs-ama-go/docs"
 	"github.com/JulioZittei/wsrs-ama-go/internal/app"
+	"github.com/JulioZittei/wsrs-ama-go/internal/config"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
 	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 )
 
 // @title Ask Me Anything API
@@ -32,31 +34,71 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 func main() {
-	if err := godotenv.Load(); err != nil {
-		panic(err)
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, commandsUsage)
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
 	ctx := context.Background()
 
-	pool, err := pgxpool.New(ctx, fmt.Sprintf(
-		"user=%s password=%s host=%s port=%s dbname=%s",
-		os.Getenv("WSRS_DATABASE_USER"), os.Getenv("WSRS_DATABASE_PASSWORD"), os.Getenv("WSRS_DATABASE_HOST"), os.Getenv("WSRS_DATABASE_PORT"), os.Getenv("WSRS_DATABASE_NAME")))
-
-	if err != nil {
-		panic(err)
+	if len(args) > 0 {
+		os.Exit(runCommand(ctx, cfg, args))
 	}
 
-	defer pool.Close()
+	var store repositories.Store
+	switch cfg.Store {
+	case config.StorePostgres:
+		pool, err := pgxpool.New(ctx, cfg.Database.ConnString())
 
-	if err := pool.Ping(ctx); err != nil {
-		panic(err)
+		if err != nil {
+			panic(err)
+		}
+
+		defer pool.Close()
+
+		if err := pool.Ping(ctx); err != nil {
+			panic(err)
+		}
+
+		if cfg.Database.AutoMigrate {
+			if err := pgstore.Migrate(ctx, pool); err != nil {
+				panic(err)
+			}
+		}
+
+		store = repositories.NewPgStore(pool)
+	case config.StoreSQLite:
+		db, err := sqlitestore.Open(cfg.SQLite.Path)
+		if err != nil {
+			panic(err)
+		}
+
+		defer db.Close()
+
+		if err := sqlitestore.Migrate(ctx, db); err != nil {
+			panic(err)
+		}
+
+		store = repositories.NewSQLiteStore(db)
+	case config.StoreMemory:
+		// nothing is persisted, meant for demos and local development
+		store = repositories.NewMemoryStore()
 	}
 
-	app := app.NewApplication(pgstore.New(pool))
+	app, err := app.NewApplication(store, cfg)
+	if err != nil {
+		panic(err)
+	}
 	app.Init()
+	app.StartJobs()
 
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.Server.Addr,
 		Handler: app.GetHandler(),
 	}
 
@@ -69,19 +111,44 @@ func main() {
 		}
 	}()
 
+	// the metrics have a listener of their own, meant for the internal
+	// network only
+	var metricsServer *http.Server
+	if cfg.Server.MetricsAddr != "" {
+		metricsServer = &http.Server{
+			Addr:    cfg.Server.MetricsAddr,
+			Handler: app.GetMetricsHandler(),
+		}
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil {
+				if !errors.Is(err, http.ErrServerClosed) {
+					panic(err)
+				}
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, os.Interrupt, syscall.SIGINT)
 
 	fmt.Println("Started")
 	<-quit
 
-	context, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	context, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	fmt.Println("ServerStopping...")
 
 	if err := server.Shutdown(context); err != nil {
 		panic(err)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(context); err != nil {
+			panic(err)
+		}
+	}
+
+	app.StopJobs(context)
 
 	fmt.Println("Server Stopped")
 }
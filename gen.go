@@ -1,4 +1,5 @@
 package gen
 
-//go:generate go run ./cmd/tools/terndotenv/main.go
+//go:generate go run ./cmd/wsrs migrate up
 //go:generate sqlc generate -f ./internal/store/pgstore/sqlc.yml
+//go:generate sqlc generate -f ./internal/store/sqlitestore/sqlc.yml
@@ -0,0 +1,82 @@
+package access
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is what a grant lets its holder do in the room.
+type Role string
+
+const (
+	// RoleParticipant reads, posts and votes in the room
+	RoleParticipant Role = "participant"
+	// RoleHost manages the room as well: changes, closes or deletes it,
+	// moderates its messages and runs its polls
+	RoleHost Role = "host"
+)
+
+// GrantSigner issues and verifies room access grants. A grant is a token of
+// the form "<room id>.<role>.<expiry>.<signature>" handed out to whoever
+// presented the passcode of a room, or to the host that created it.
+type GrantSigner struct {
+	secret []byte
+}
+
+// NewGrantSigner creates a signer for the given secret. When the secret is
+// empty a random one is used, so grants don't survive a restart.
+func NewGrantSigner(secret []byte) *GrantSigner {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	}
+	return &GrantSigner{secret: secret}
+}
+
+func (gs *GrantSigner) Issue(roomId uuid.UUID, role Role, expiresAt time.Time) string {
+	payload := roomId.String() + "." + string(role) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + gs.sign(payload)
+}
+
+// Verify returns the role a grant gives in the room, and false when it
+// isn't a valid grant for the room.
+func (gs *GrantSigner) Verify(token string, roomId uuid.UUID) (Role, bool) {
+	index := strings.LastIndex(token, ".")
+	if index < 0 {
+		return "", false
+	}
+	payload, signature := token[:index], token[index+1:]
+	if !hmac.Equal([]byte(signature), []byte(gs.sign(payload))) {
+		return "", false
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 || parts[0] != roomId.String() {
+		return "", false
+	}
+	role := Role(parts[1])
+	if role != RoleParticipant && role != RoleHost {
+		return "", false
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return role, time.Now().Unix() < expiresAt
+}
+
+func (gs *GrantSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, gs.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,15 +1,26 @@
 package app
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/audit"
+	"github.com/JulioZittei/wsrs-ama-go/internal/config"
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers"
 	"github.com/JulioZittei/wsrs-ama-go/internal/exception_handler"
+	"github.com/JulioZittei/wsrs-ama-go/internal/hub"
+	"github.com/JulioZittei/wsrs-ama-go/internal/jobs"
 	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/metrics"
 	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+	"github.com/JulioZittei/wsrs-ama-go/internal/outbox"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ratelimit"
 	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
 	"github.com/JulioZittei/wsrs-ama-go/internal/services"
-	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/webhooks"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/cors"
@@ -18,14 +29,28 @@ import (
 )
 
 type App struct {
-	db      *pgstore.Queries
-	handler *chi.Mux
+	store      repositories.Store
+	config     config.Config
+	filters    contentfilter.Chain
+	handler    *chi.Mux
+	scheduler  *jobs.Scheduler
+	dispatcher *outbox.Dispatcher
+	sender     *webhooks.Sender
 }
 
-func NewApplication(queries *pgstore.Queries) App {
-	return App{
-		db: queries,
+// NewApplication fails when the content filters can't be built, e.g. when
+// a word list can't be read.
+func NewApplication(store repositories.Store, config config.Config) (App, error) {
+	filters, err := contentfilter.New(config.Filters)
+	if err != nil {
+		return App{}, err
 	}
+
+	return App{
+		store:   store,
+		config:  config,
+		filters: filters,
+	}, nil
 }
 
 func (app *App) Init() {
@@ -37,48 +62,127 @@ func (app *App) Init() {
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 	router.Use(middlewares.LanguageMiddleware)
+	router.Use(middlewares.RoomAccessMiddleware)
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
+		AllowedOrigins:   app.config.Server.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Room-Access", "X-Api-Key", ratelimit.ParticipantHeader},
+		ExposedHeaders:   []string{"Link", "Retry-After"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	messageMapper := mappers.MessageMapper{}
 	roomMapper := mappers.RoomMapper{}
-
-	// init repositories
-	roomsRepository := repositories.NewRoomsRepository(app.db, &roomMapper, &messageMapper)
+	pollMapper := mappers.PollMapper{}
+	webhookMapper := mappers.WebhookMapper{}
+	apiKeyMapper := mappers.APIKeyMapper{}
 
 	// init services
-	roomService := services.NewRoomsService(roomsRepository, &roomMapper, &messageMapper)
+	roomService := services.NewRoomsService(app.store.Rooms, outbox.NewPublisher(app.store.Outbox), app.store.UnitOfWork, &roomMapper, &messageMapper, access.NewGrantSigner([]byte(app.config.AccessSecret)), app.filters)
+	pollService := services.NewPollsService(app.store.Polls, roomService, &pollMapper)
+	webhookService := services.NewWebhooksService(app.store.Webhooks, roomService, &webhookMapper)
+	apiKeyService := services.NewAPIKeysService(app.store.APIKeys, &apiKeyMapper)
+
+	// the keys are looked up in the store, so the middleware comes with it
+	router.Use(middlewares.APIKeyMiddleware(apiKeyService.Authenticate))
+
+	// init background jobs
+	app.scheduler = jobs.NewScheduler(roomService, app.config.Jobs)
+
+	// init rate limiters, one per route group. The participant header is
+	// chosen by the client, so the routes limited per participant are
+	// limited per address too.
+	limitRooms := ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.Rooms), ratelimit.ByIP)
+	limitMessages := chi.Chain(
+		ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.MessagesPerIP), ratelimit.ByIP),
+		ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.Messages), ratelimit.ByParticipant))
+	limitVotes := chi.Chain(
+		ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.VotesPerIP), ratelimit.ByIP),
+		ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.Votes), ratelimit.ByParticipant))
+	limitPollVotes := append(chi.Chain(
+		ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.PollVotesPerIP), ratelimit.ByIPAndParam("poll_id"))),
+		limitVotes...)
+	limitSubscriptions := ratelimit.Middleware(ratelimit.NewLimiter(app.config.RateLimits.Subscriptions), ratelimit.ByIP)
 
 	// init controllers
+	roomsHub := hub.NewHub()
 	roomsController := controllers.NewRoomsController(roomService, websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
 			return true
 		},
-	})
+	}, roomsHub, ratelimit.NewLimiter(app.config.RateLimits.SocketCommands))
+	pollsController := controllers.NewPollsController(pollService)
+	webhooksController := controllers.NewWebhooksController(webhookService)
+	slashCommandsController := controllers.NewSlashCommandsController(roomService, []byte(app.config.SlashCommandSecret))
+
+	// init the delivery of the events the services publish
+	app.dispatcher = outbox.NewDispatcher(app.store.Outbox, outbox.DefaultInterval,
+		roomsHub, audit.NewLog(slog.Default()), metrics.NewEventCounter("room_events"),
+		webhooks.NewSubscriber(app.store.Webhooks, app.store.UnitOfWork))
+	app.sender = webhooks.NewSender(app.store.Webhooks, webhooks.DefaultInterval)
 
 	// config routes and handlers
 	router.Mount("/swagger", httpSwagger.WrapHandler)
 	router.Route("/api/v1", func(r chi.Router) {
-		r.Get("/subscribe/{room_id}", roomsController.SubscribeRoom)
+		r.With(limitSubscriptions).Get("/subscribe/{room_id}", roomsController.SubscribeRoom)
+		r.Get("/join/{code}", exception_handler.ExceptionHandler(roomsController.JoinRoom))
+		// the slash commands are only served once a secret verifies them
+		if app.config.SlashCommandSecret != "" {
+			r.Post("/integrations/slash-commands", exception_handler.ExceptionHandler(slashCommandsController.HandleCommand))
+		}
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Post("/", exception_handler.ExceptionHandler(webhooksController.CreateWebhook))
+			r.Get("/", exception_handler.ExceptionHandler(webhooksController.GetWebhooks))
+			r.Get("/{webhook_id}", exception_handler.ExceptionHandler(webhooksController.GetWebhook))
+			r.Delete("/{webhook_id}", exception_handler.ExceptionHandler(webhooksController.DeleteWebhook))
+			r.Get("/{webhook_id}/deliveries", exception_handler.ExceptionHandler(webhooksController.GetWebhookDeliveries))
+		})
 		r.Route("/rooms", func(r chi.Router) {
-			r.Post("/", exception_handler.ExceptionHandler(roomsController.CreateRoom))
+			r.With(limitRooms).Post("/", exception_handler.ExceptionHandler(roomsController.CreateRoom))
 			r.Get("/", exception_handler.ExceptionHandler(roomsController.GetRooms))
 			r.Get("/{room_id}", exception_handler.ExceptionHandler(roomsController.GetRoom))
+			r.Patch("/{room_id}", exception_handler.ExceptionHandler(roomsController.UpdateRoom))
+			r.Delete("/{room_id}", exception_handler.ExceptionHandler(roomsController.DeleteRoom))
+			r.With(limitRooms).Post("/{room_id}/join-code", exception_handler.ExceptionHandler(roomsController.RegenerateRoomJoinCode))
+			r.With(limitRooms).Post("/{room_id}/access", exception_handler.ExceptionHandler(roomsController.GrantRoomAccess))
+			r.Get("/{room_id}/export", exception_handler.ExceptionHandler(roomsController.ExportRoom))
+			r.Delete("/{room_id}/spotlight", exception_handler.ExceptionHandler(roomsController.ClearRoomSpotlight))
+			r.Post("/{room_id}/webhooks", exception_handler.ExceptionHandler(webhooksController.CreateRoomWebhook))
+			r.Get("/{room_id}/webhooks", exception_handler.ExceptionHandler(webhooksController.GetRoomWebhooks))
+
+			r.Route("/{room_id}/polls", func(r chi.Router) {
+				r.Get("/", exception_handler.ExceptionHandler(pollsController.GetRoomPolls))
+				r.Post("/", exception_handler.ExceptionHandler(pollsController.CreatePoll))
+
+				r.Route("/{poll_id}", func(r chi.Router) {
+					r.Get("/", exception_handler.ExceptionHandler(pollsController.GetPoll))
+					r.With(limitPollVotes...).Post("/votes", exception_handler.ExceptionHandler(pollsController.VotePoll))
+					r.Patch("/close", exception_handler.ExceptionHandler(pollsController.ClosePoll))
+				})
+			})
 
 			r.Route("/{room_id}/messages", func(r chi.Router) {
 				r.Get("/", exception_handler.ExceptionHandler(roomsController.GetRoomMessages))
-				r.Post("/", exception_handler.ExceptionHandler(roomsController.CreateRoomMessage))
+				r.With(limitMessages...).Post("/", exception_handler.ExceptionHandler(roomsController.CreateRoomMessage))
+				r.With(limitMessages...).Post("/import", exception_handler.ExceptionHandler(roomsController.ImportRoomMessages))
 
 				r.Route("/{message_id}", func(r chi.Router) {
 					r.Get("/", exception_handler.ExceptionHandler(roomsController.GetRoomMessage))
-					r.Patch("/like", exception_handler.ExceptionHandler(roomsController.LikeRoomMessage))
-					r.Delete("/like", exception_handler.ExceptionHandler(roomsController.RemoveLikeRoomMessage))
+
+					r.Group(func(r chi.Router) {
+						r.Use(limitVotes...)
+						r.Patch("/like", exception_handler.ExceptionHandler(roomsController.LikeRoomMessage))
+						r.Delete("/like", exception_handler.ExceptionHandler(roomsController.RemoveLikeRoomMessage))
+						r.Patch("/downvote", exception_handler.ExceptionHandler(roomsController.DownvoteRoomMessage))
+						r.Delete("/downvote", exception_handler.ExceptionHandler(roomsController.RemoveRoomMessageDownvote))
+						r.Patch("/reactions/{kind}", exception_handler.ExceptionHandler(roomsController.ReactToRoomMessage))
+						r.Delete("/reactions/{kind}", exception_handler.ExceptionHandler(roomsController.RemoveRoomMessageReaction))
+					})
+
+					r.Patch("/pin", exception_handler.ExceptionHandler(roomsController.PinRoomMessage))
+					r.Delete("/pin", exception_handler.ExceptionHandler(roomsController.UnpinRoomMessage))
+					r.Patch("/spotlight", exception_handler.ExceptionHandler(roomsController.SpotlightRoomMessage))
 					r.Patch("/answer", exception_handler.ExceptionHandler(roomsController.AnswerRoomMessage))
 				})
 			})
@@ -91,3 +195,24 @@ func (app *App) Init() {
 func (app *App) GetHandler() http.Handler {
 	return app.handler
 }
+
+// GetMetricsHandler serves the metrics, to be listened on apart from the
+// API.
+func (app *App) GetMetricsHandler() http.Handler {
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+	router.Handle("/debug/vars", metrics.Handler())
+	return router
+}
+
+func (app *App) StartJobs() {
+	app.scheduler.Start()
+	app.dispatcher.Start()
+	app.sender.Start()
+}
+
+func (app *App) StopJobs(ctx context.Context) {
+	app.scheduler.Stop(ctx)
+	app.dispatcher.Stop(ctx)
+	app.sender.Stop(ctx)
+}
@@ -0,0 +1,24 @@
+// Package audit keeps a trail of what happened to the rooms in the log.
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+)
+
+// Log writes an entry per event. The entries are only as durable as the log
+// output they go to.
+type Log struct {
+	logger *slog.Logger
+}
+
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+func (l *Log) HandleEvent(ctx context.Context, event events.Event) error {
+	l.logger.InfoContext(ctx, "room event", "kind", event.Kind(), "room_id", event.Room(), "event", event)
+	return nil
+}
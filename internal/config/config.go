@@ -0,0 +1,224 @@
+// Package config gathers the settings of the server. They are read, each
+// layer over the one before, from the defaults, an optional YAML or TOML
+// file, the environment and the command line flags, and checked before
+// anything starts.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/jobs"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ratelimit"
+	"github.com/joho/godotenv"
+)
+
+const (
+	StorePostgres = "postgres"
+	StoreSQLite   = "sqlite"
+	StoreMemory   = "memory"
+)
+
+type Config struct {
+	// Store is the storage backend: postgres, sqlite or memory
+	Store    string
+	Server   Server
+	Database Database
+	SQLite   SQLite
+	// AccessSecret signs the room access grants, a random one is used when
+	// it is empty
+	AccessSecret string
+	// SlashCommandSecret verifies the slash commands of the chat platforms,
+	// they are turned off when it is empty
+	SlashCommandSecret string
+	Jobs               jobs.Policy
+	Filters            contentfilter.Config
+	RateLimits         ratelimit.Config
+}
+
+type Server struct {
+	Addr string
+	// ShutdownTimeout is how long the requests and the background jobs
+	// are given to finish on shutdown
+	ShutdownTimeout time.Duration
+	// CORSOrigins are the origins browsers may call the API from
+	CORSOrigins []string
+	// MetricsAddr is the address the metrics are served on, apart from the
+	// API so it can be kept off the public network. They are not served
+	// when it is empty.
+	MetricsAddr string
+}
+
+type Database struct {
+	Host     string
+	Port     int
+	Name     string
+	User     string
+	Password string
+	// AutoMigrate applies the migrations on startup
+	AutoMigrate bool
+}
+
+// ConnString returns the connection string pgx takes, as a URL so that
+// empty values and spaces survive.
+func (d Database) ConnString() string {
+	conn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(d.User, d.Password),
+		Host:   net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:   d.Name,
+	}
+	return conn.String()
+}
+
+type SQLite struct {
+	Path string
+}
+
+func Default() Config {
+	return Config{
+		Store: StorePostgres,
+		Server: Server{
+			Addr:            ":8080",
+			ShutdownTimeout: 5 * time.Second,
+			CORSOrigins:     []string{"https://*", "http://*"},
+			MetricsAddr:     "localhost:9090",
+		},
+		Database: Database{
+			Host: "localhost",
+			Port: 5432,
+			Name: "wsrs",
+			User: "postgres",
+		},
+		SQLite: SQLite{
+			Path: "wsrs.db",
+		},
+		Jobs:       jobs.DefaultPolicy(),
+		Filters:    contentfilter.DefaultConfig(),
+		RateLimits: ratelimit.DefaultConfig(),
+	}
+}
+
+// Load reads the config for a command started with args and returns the
+// arguments left after the flags. The variables of a .env file in the
+// working directory are added to the environment when the file exists,
+// without replacing the ones already set. The file to read is given by the
+// -config flag or the WSRS_CONFIG variable. A variable that is set
+// overrides the file even when it is empty.
+//
+// flag.ErrHelp is returned when the flags were asked for.
+func Load(args []string) (Config, []string, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil, fmt.Errorf("reading .env: %w", err)
+	}
+
+	config := Default()
+
+	flags := flag.NewFlagSet("wsrs", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	path := flags.String("config", os.Getenv("WSRS_CONFIG"), "YAML or TOML file to read the settings from (WSRS_CONFIG)")
+	values := make(map[string]*string, len(settings))
+	for _, setting := range settings {
+		values[setting.key] = flags.String(setting.key, "", fmt.Sprintf("%s (%s)", setting.usage, setting.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+		}
+		return config, nil, err
+	}
+
+	if *path != "" {
+		fileValues, err := readFile(*path)
+		if err != nil {
+			return config, nil, err
+		}
+		for key, value := range fileValues {
+			setting, ok := settingsByKey[key]
+			if !ok {
+				return config, nil, fmt.Errorf("unknown setting %s in %s", key, *path)
+			}
+			if err := setting.apply(&config, fmt.Sprintf("%s in %s", key, *path), value); err != nil {
+				return config, nil, err
+			}
+		}
+	}
+
+	for _, setting := range settings {
+		if value, ok := os.LookupEnv(setting.env); ok {
+			if err := setting.apply(&config, setting.env, value); err != nil {
+				return config, nil, err
+			}
+		}
+	}
+
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if setting, ok := settingsByKey[f.Name]; ok && err == nil {
+			err = setting.apply(&config, "-"+f.Name, *values[f.Name])
+		}
+	})
+	if err != nil {
+		return config, nil, err
+	}
+
+	return config, flags.Args(), config.Validate()
+}
+
+// minAccessSecretLength keeps the grants from being forged by guessing the
+// secret.
+const minAccessSecretLength = 32
+
+// exampleAccessSecrets were shipped in the example settings, a server
+// using one signs grants anyone can forge.
+var exampleAccessSecrets = []string{"change-me"}
+
+// Validate checks the settings that depend on each other. The value of
+// each setting is checked when it is read.
+func (c Config) Validate() error {
+	var errs []error
+
+	switch c.Store {
+	case StorePostgres:
+		if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+			errs = append(errs, errors.New("the postgres store needs database.host, database.name and database.user"))
+		}
+	case StoreSQLite:
+		if c.SQLite.Path == "" {
+			errs = append(errs, errors.New("the sqlite store needs sqlite.path"))
+		}
+	case StoreMemory:
+	default:
+		errs = append(errs, fmt.Errorf("invalid store %q, it must be postgres, sqlite or memory", c.Store))
+	}
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr must not be empty"))
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		errs = append(errs, errors.New("server.cors_origins must not be empty"))
+	}
+	if c.AccessSecret != "" {
+		if slices.Contains(exampleAccessSecrets, c.AccessSecret) {
+			errs = append(errs, errors.New("access.secret is the example one, set a secret of your own or leave it empty"))
+		} else if len(c.AccessSecret) < minAccessSecretLength {
+			errs = append(errs, fmt.Errorf("access.secret must be at least %d characters long", minAccessSecretLength))
+		}
+	}
+	if c.Filters.MinLength > c.Filters.MaxLength {
+		errs = append(errs, fmt.Errorf("filter.min_length %d is greater than filter.max_length %d", c.Filters.MinLength, c.Filters.MaxLength))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile returns the values of a YAML or TOML file by the key of their
+// setting. The values go through the same parsing as the environment, so
+// a duration is written "5m" in every layer.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	document := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("reading config %s: unknown format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(values, "", document); err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten joins the keys of the nested tables with dots.
+func flatten(values map[string]string, prefix string, table map[string]any) error {
+	for key, value := range table {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(values, key, value); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				if !isScalar(item) {
+					return fmt.Errorf("%s must be a list of values", key)
+				}
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			// an empty value keeps the default, like an empty variable
+		default:
+			if !isScalar(value) {
+				return fmt.Errorf("%s must be a value", key)
+			}
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case string, bool, int, int64, uint64, float64:
+		return true
+	default:
+		return false
+	}
+}
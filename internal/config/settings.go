@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/jobs"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ratelimit"
+)
+
+// setting is a single value of the config. Its key names it in the files,
+// where the dots nest it, and as a flag.
+type setting struct {
+	key   string
+	env   string
+	usage string
+	set   func(config *Config, value string) error
+}
+
+// apply sets the value read from source, which names where it came from in
+// the errors.
+func (s setting) apply(config *Config, source string, value string) error {
+	if err := s.set(config, value); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", source, value, err)
+	}
+	return nil
+}
+
+func field[T any](key string, env string, usage string, parse func(string) (T, error), target func(*Config) *T) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		usage: usage,
+		set: func(config *Config, value string) error {
+			parsed, err := parse(value)
+			if err != nil {
+				return err
+			}
+			*target(config) = parsed
+			return nil
+		},
+	}
+}
+
+var settings = []setting{
+	field("store", "WSRS_STORE", "storage backend: postgres, sqlite or memory", parseString,
+		func(c *Config) *string { return &c.Store }),
+
+	field("server.addr", "WSRS_SERVER_ADDR", "address the server listens on", parseString,
+		func(c *Config) *string { return &c.Server.Addr }),
+	field("server.shutdown_timeout", "WSRS_SERVER_SHUTDOWN_TIMEOUT", "time given to the requests and jobs to finish on shutdown", parseDuration,
+		func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	field("server.cors_origins", "WSRS_SERVER_CORS_ORIGINS", "comma separated origins browsers may call the API from", parseList,
+		func(c *Config) *[]string { return &c.Server.CORSOrigins }),
+	field("server.metrics_addr", "WSRS_SERVER_METRICS_ADDR", "address the metrics are served on, none when empty", parseString,
+		func(c *Config) *string { return &c.Server.MetricsAddr }),
+
+	field("database.host", "WSRS_DATABASE_HOST", "postgres host", parseString,
+		func(c *Config) *string { return &c.Database.Host }),
+	field("database.port", "WSRS_DATABASE_PORT", "postgres port", parsePort,
+		func(c *Config) *int { return &c.Database.Port }),
+	field("database.name", "WSRS_DATABASE_NAME", "postgres database", parseString,
+		func(c *Config) *string { return &c.Database.Name }),
+	field("database.user", "WSRS_DATABASE_USER", "postgres user", parseString,
+		func(c *Config) *string { return &c.Database.User }),
+	field("database.password", "WSRS_DATABASE_PASSWORD", "postgres password", parseString,
+		func(c *Config) *string { return &c.Database.Password }),
+	field("database.auto_migrate", "WSRS_DATABASE_AUTO_MIGRATE", "apply the postgres migrations on startup, one replica at a time", strconv.ParseBool,
+		func(c *Config) *bool { return &c.Database.AutoMigrate }),
+
+	field("sqlite.path", "WSRS_SQLITE_PATH", "sqlite database file", parseString,
+		func(c *Config) *string { return &c.SQLite.Path }),
+
+	field("access.secret", "WSRS_ACCESS_SECRET", "secret signing the room access grants", parseString,
+		func(c *Config) *string { return &c.AccessSecret }),
+	field("slash_commands.secret", "WSRS_SLASH_COMMAND_SECRET", "signing secret or token of the slash commands", parseString,
+		func(c *Config) *string { return &c.SlashCommandSecret }),
+
+	field("jobs.interval", "WSRS_JOBS_INTERVAL", "how often the background jobs run", parseDuration,
+		func(c *Config) *time.Duration { return &c.Jobs.Interval }),
+	field("jobs.close_ended_rooms", "WSRS_JOBS_CLOSE_ENDED_ROOMS", "close the rooms once they end", strconv.ParseBool,
+		func(c *Config) *bool { return &c.Jobs.CloseEndedRooms }),
+	field("jobs.inactivity_hours", "WSRS_JOBS_INACTIVITY_HOURS", "hours without questions before a room is closed, 0 turns it off", parseUnits(time.Hour),
+		func(c *Config) *time.Duration { return &c.Jobs.InactivityTimeout }),
+	field("jobs.retention_days", "WSRS_JOBS_RETENTION_DAYS", "days closed rooms are kept, 0 turns it off", parseUnits(24*time.Hour),
+		func(c *Config) *time.Duration { return &c.Jobs.RetentionPeriod }),
+	field("jobs.retention_mode", "WSRS_JOBS_RETENTION_MODE", "what happens to rooms past retention: purge or anonymize",
+		parseOneOf(jobs.RetentionModePurge, jobs.RetentionModeAnonymize),
+		func(c *Config) *jobs.RetentionMode { return &c.Jobs.RetentionMode }),
+
+	field("filter.min_length", "WSRS_FILTER_MIN_LENGTH", "shortest question accepted", parseCount,
+		func(c *Config) *int { return &c.Filters.MinLength }),
+	field("filter.max_length", "WSRS_FILTER_MAX_LENGTH", "longest question accepted", parseCount,
+		func(c *Config) *int { return &c.Filters.MaxLength }),
+	field("filter.links", "WSRS_FILTER_LINKS", "links in questions: off, strip, mask, reject or flag",
+		parseOneOf(contentfilter.ModeOff, contentfilter.ModeStrip, contentfilter.ModeMask, contentfilter.ModeReject, contentfilter.ModeFlag),
+		func(c *Config) *contentfilter.Mode { return &c.Filters.LinkMode }),
+	field("filter.pii", "WSRS_FILTER_PII", "email addresses and phone numbers in questions: off, mask, reject or flag",
+		parseOneOf(contentfilter.ModeOff, contentfilter.ModeMask, contentfilter.ModeReject, contentfilter.ModeFlag),
+		func(c *Config) *contentfilter.Mode { return &c.Filters.PIIMode }),
+	field("filter.profanity", "WSRS_FILTER_PROFANITY", "offensive language in questions: off, mask, reject or flag",
+		parseOneOf(contentfilter.ModeOff, contentfilter.ModeMask, contentfilter.ModeReject, contentfilter.ModeFlag),
+		func(c *Config) *contentfilter.Mode { return &c.Filters.ProfanityMode }),
+	field("filter.wordlist_dir", "WSRS_FILTER_WORDLIST_DIR", "directory with extra profanity word lists", parseString,
+		func(c *Config) *string { return &c.Filters.WordlistDir }),
+
+	field("rate_limit.rooms", "WSRS_RATE_LIMIT_ROOMS", "room management per client address, like 30/1m, 0 turns it off", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.Rooms }),
+	field("rate_limit.messages", "WSRS_RATE_LIMIT_MESSAGES", "new questions per participant", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.Messages }),
+	field("rate_limit.messages_per_ip", "WSRS_RATE_LIMIT_MESSAGES_PER_IP", "new questions per client address", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.MessagesPerIP }),
+	field("rate_limit.votes", "WSRS_RATE_LIMIT_VOTES", "likes, reactions and votes per participant", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.Votes }),
+	field("rate_limit.votes_per_ip", "WSRS_RATE_LIMIT_VOTES_PER_IP", "likes, reactions and votes per client address", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.VotesPerIP }),
+	field("rate_limit.poll_votes_per_ip", "WSRS_RATE_LIMIT_POLL_VOTES_PER_IP", "votes on each poll per client address", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.PollVotesPerIP }),
+	field("rate_limit.subscriptions", "WSRS_RATE_LIMIT_SUBSCRIPTIONS", "new websocket connections per client address", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.Subscriptions }),
+	field("rate_limit.socket_commands", "WSRS_RATE_LIMIT_SOCKET_COMMANDS", "frames per websocket connection", ratelimit.ParseLimit,
+		func(c *Config) *ratelimit.Limit { return &c.RateLimits.SocketCommands }),
+}
+
+var settingsByKey = func() map[string]setting {
+	byKey := make(map[string]setting, len(settings))
+	for _, setting := range settings {
+		byKey[setting.key] = setting
+	}
+	return byKey
+}()
+
+func parseString(value string) (string, error) {
+	return value, nil
+}
+
+// parseList reads comma separated values, the files may use lists instead.
+func parseList(value string) ([]string, error) {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return duration, nil
+}
+
+func parseCount(value string) (int, error) {
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return count, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("must be between 1 and 65535")
+	}
+	return port, nil
+}
+
+// parseUnits reads a count of unit, like the hours of a timeout.
+func parseUnits(unit time.Duration) func(string) (time.Duration, error) {
+	return func(value string) (time.Duration, error) {
+		count, err := parseCount(value)
+		return time.Duration(count) * unit, err
+	}
+}
+
+func parseOneOf[T ~string](allowed ...T) func(string) (T, error) {
+	return func(value string) (T, error) {
+		if !slices.Contains(allowed, T(value)) {
+			return "", fmt.Errorf("must be one of %v", allowed)
+		}
+		return T(value), nil
+	}
+}
@@ -0,0 +1,40 @@
+package contentfilter
+
+import "fmt"
+
+// Config decides which filters run on incoming questions and how they treat
+// what they find.
+type Config struct {
+	MinLength     int
+	MaxLength     int
+	LinkMode      Mode
+	PIIMode       Mode
+	ProfanityMode Mode
+	WordlistDir   string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MinLength:     1,
+		MaxLength:     255,
+		LinkMode:      ModeStrip,
+		PIIMode:       ModeMask,
+		ProfanityMode: ModeMask,
+	}
+}
+
+// New builds the filter chain described by config. Links and personal data
+// are handled first so the length limits apply to what will be stored.
+func New(config Config) (Chain, error) {
+	wordlists, err := LoadWordlists(config.WordlistDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profanity wordlists: %w", err)
+	}
+
+	return Chain{
+		PIIFilter{Mode: config.PIIMode},
+		LinkFilter{Mode: config.LinkMode},
+		ProfanityFilter{Mode: config.ProfanityMode, Wordlists: wordlists},
+		LengthFilter{Min: config.MinLength, Max: config.MaxLength},
+	}, nil
+}
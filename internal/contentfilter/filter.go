@@ -0,0 +1,56 @@
+package contentfilter
+
+import "slices"
+
+// Mode tells a filter what to do with the content it looks for.
+type Mode string
+
+const (
+	ModeOff    Mode = "off"
+	ModeReject Mode = "reject"
+	ModeMask   Mode = "mask"
+	ModeStrip  Mode = "strip"
+	ModeFlag   Mode = "flag"
+)
+
+// Message is a question going through the filter chain. Filters may rewrite
+// its text and flag it for moderation.
+type Message struct {
+	Text  string
+	Lang  string
+	Flags []string
+}
+
+// Flag marks the message for moderation, once per reason.
+func (m *Message) Flag(reason string) {
+	if !slices.Contains(m.Flags, reason) {
+		m.Flags = append(m.Flags, reason)
+	}
+}
+
+// Rejection is returned by a filter that refuses a message. Key is the locale
+// key of the reason given to the asker, Param its optional parameter.
+type Rejection struct {
+	Key   string
+	Param string
+}
+
+func (r *Rejection) Error() string {
+	return "message rejected: " + r.Key
+}
+
+type Filter interface {
+	Apply(message *Message) error
+}
+
+// Chain runs its filters in order and stops at the first rejection.
+type Chain []Filter
+
+func (c Chain) Apply(message *Message) error {
+	for _, filter := range c {
+		if err := filter.Apply(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package contentfilter
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LengthFilter trims the message and rejects it when it ends up shorter than
+// Min or longer than Max characters. Zero turns a limit off.
+type LengthFilter struct {
+	Min int
+	Max int
+}
+
+func (f LengthFilter) Apply(message *Message) error {
+	message.Text = strings.TrimSpace(message.Text)
+
+	length := utf8.RuneCountInString(message.Text)
+	if f.Min > 0 && length < f.Min {
+		return &Rejection{Key: "MIN", Param: strconv.Itoa(f.Min)}
+	}
+	if f.Max > 0 && length > f.Max {
+		return &Rejection{Key: "MAX", Param: strconv.Itoa(f.Max)}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package contentfilter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linkPattern matches URLs and bare domains. The bare domains of a country
+// code top level domain need a path, as the likes of rua.br or hello.me are
+// as often words run together.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+` +
+	`|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|dev|app|info)(?:\.[a-z]{2})?\b(?:/\S*)?` +
+	`|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:br|co|me|ly|gg)/\S+`)
+
+var spaces = regexp.MustCompile(`\s{2,}`)
+
+// LinkFilter looks for URLs and bare domains. It strips them, replaces them
+// with a placeholder, rejects the message or flags it.
+type LinkFilter struct {
+	Mode Mode
+}
+
+func (f LinkFilter) Apply(message *Message) error {
+	if f.Mode == ModeOff || !linkPattern.MatchString(message.Text) {
+		return nil
+	}
+
+	switch f.Mode {
+	case ModeReject:
+		return &Rejection{Key: "LINKS_NOT_ALLOWED"}
+	case ModeFlag:
+		message.Flag("link")
+	case ModeMask:
+		message.Text = linkPattern.ReplaceAllString(message.Text, "[link]")
+	default:
+		message.Text = strings.TrimSpace(spaces.ReplaceAllString(linkPattern.ReplaceAllString(message.Text, ""), " "))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package contentfilter
+
+import "testing"
+
+func TestLinkFilterStripsLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "see https://example.com/docs?page=2 first", want: "see first"},
+		{text: "see http://example.com first", want: "see first"},
+		{text: "see www.example.org first", want: "see first"},
+		{text: "see example.com first", want: "see first"},
+		{text: "see docs.example.dev/guide first", want: "see first"},
+		{text: "see example.com.br first", want: "see first"},
+		{text: "see bit.ly/3xYz first", want: "see first"},
+
+		{text: "Moro na rua.br de cima", want: "Moro na rua.br de cima"},
+		{text: "rua.br", want: "rua.br"},
+		{text: "say hello.me later", want: "say hello.me later"},
+		{text: "Does v1.2.3 fix it?", want: "Does v1.2.3 fix it?"},
+		{text: "Is node.js still used?", want: "Is node.js still used?"},
+		{text: "Is it done. Or not?", want: "Is it done. Or not?"},
+	}
+	for _, tt := range tests {
+		message := &Message{Text: tt.text}
+		if err := (LinkFilter{Mode: ModeStrip}).Apply(message); err != nil {
+			t.Fatalf("Apply(%q): %v", tt.text, err)
+		}
+		if message.Text != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.text, message.Text, tt.want)
+		}
+	}
+}
+
+func TestLinkFilterMasksLinks(t *testing.T) {
+	message := &Message{Text: "see https://example.com and example.org/faq"}
+	if err := (LinkFilter{Mode: ModeMask}).Apply(message); err != nil {
+		t.Fatal(err)
+	}
+	if want := "see [link] and [link]"; message.Text != want {
+		t.Errorf("mask = %q, want %q", message.Text, want)
+	}
+}
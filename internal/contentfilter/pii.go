@@ -0,0 +1,67 @@
+package contentfilter
+
+import (
+	"regexp"
+	"unicode"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+	// phonePattern takes for a phone number the digits after a country code,
+	// an area code in parentheses followed by the number, or groups of digits
+	// ending in one of four or more, as in 555-123-4567 or 020 7946 0958.
+	// Dots only separate the groups after a country code, so versions and
+	// amounts don't look like phone numbers.
+	phonePattern = regexp.MustCompile(`\+\d[\d\s().-]{6,}\d|\(\d{2,4}\)\s?\d{3,5}[\s-]?\d{4}\b|\b\d{2,5}(?:[\s-]\d{2,5})*[\s-]\d{4,6}\b`)
+	// datePattern matches the dates written as groups of digits
+	datePattern = regexp.MustCompile(`^(?:\d{4}-\d{1,2}-\d{1,2}|\d{1,2}-\d{1,2}-\d{4})$`)
+)
+
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// PIIFilter looks for email addresses and phone numbers. It masks them,
+// rejects the message or flags it.
+type PIIFilter struct {
+	Mode Mode
+}
+
+func (f PIIFilter) Apply(message *Message) error {
+	if f.Mode == ModeOff {
+		return nil
+	}
+
+	found := false
+	text := emailPattern.ReplaceAllStringFunc(message.Text, func(string) string {
+		found = true
+		return "[email]"
+	})
+	text = phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+		digits := 0
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits || digits > maxPhoneDigits || datePattern.MatchString(match) {
+			return match
+		}
+		found = true
+		return "[phone]"
+	})
+	if !found {
+		return nil
+	}
+
+	switch f.Mode {
+	case ModeReject:
+		return &Rejection{Key: "PII_NOT_ALLOWED"}
+	case ModeFlag:
+		message.Flag("pii")
+	default:
+		message.Text = text
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package contentfilter
+
+import "testing"
+
+func TestPIIFilterMasksPhoneNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "call me at +55 11 91234-5678", want: "call me at [phone]"},
+		{text: "call me at +1 (555) 123-4567", want: "call me at [phone]"},
+		{text: "call me at +44 20 7946 0958", want: "call me at [phone]"},
+		{text: "call me at +1.555.123.4567", want: "call me at [phone]"},
+		{text: "call me at (11) 91234-5678", want: "call me at [phone]"},
+		{text: "call me at (555) 123-4567", want: "call me at [phone]"},
+		{text: "call me at 555-123-4567", want: "call me at [phone]"},
+		{text: "call me at 020 7946 0958", want: "call me at [phone]"},
+		{text: "call me at 91234-5678!", want: "call me at [phone]!"},
+
+		{text: "Will the release on 2024-10-20 ship?", want: "Will the release on 2024-10-20 ship?"},
+		{text: "Will the release on 20-10-2024 ship?", want: "Will the release on 20-10-2024 ship?"},
+		{text: "Does v1.22.10 fix it?", want: "Does v1.22.10 fix it?"},
+		{text: "Is 10.0.0.1 reachable?", want: "Is 10.0.0.1 reachable?"},
+		{text: "Where is order 123456789?", want: "Where is order 123456789?"},
+		{text: "Why R$ 1.234.567,89?", want: "Why R$ 1.234.567,89?"},
+		{text: "Why $1,234,567.89?", want: "Why $1,234,567.89?"},
+		{text: "Why 10 000 000 users?", want: "Why 10 000 000 users?"},
+		{text: "Is 555-0134 a short number?", want: "Is 555-0134 a short number?"},
+	}
+	for _, tt := range tests {
+		message := &Message{Text: tt.text}
+		if err := (PIIFilter{Mode: ModeMask}).Apply(message); err != nil {
+			t.Fatalf("Apply(%q): %v", tt.text, err)
+		}
+		if message.Text != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.text, message.Text, tt.want)
+		}
+	}
+}
+
+func TestPIIFilterModes(t *testing.T) {
+	const text = "mail me at someone@example.com"
+
+	message := &Message{Text: text}
+	if err := (PIIFilter{Mode: ModeMask}).Apply(message); err != nil {
+		t.Fatalf("mask: %v", err)
+	}
+	if message.Text != "mail me at [email]" {
+		t.Errorf("mask = %q, want the email masked", message.Text)
+	}
+
+	message = &Message{Text: text}
+	if err := (PIIFilter{Mode: ModeFlag}).Apply(message); err != nil {
+		t.Fatalf("flag: %v", err)
+	}
+	if message.Text != text || len(message.Flags) != 1 || message.Flags[0] != "pii" {
+		t.Errorf("flag = %q %v, want the text kept and flagged pii", message.Text, message.Flags)
+	}
+
+	message = &Message{Text: text}
+	if err := (PIIFilter{Mode: ModeReject}).Apply(message); err == nil {
+		t.Error("reject let the message through")
+	}
+}
@@ -0,0 +1,126 @@
+package contentfilter
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+//go:embed wordlists/*.txt
+var defaultWordlists embed.FS
+
+const defaultLang = "en"
+
+// Wordlists holds the profane words of each language, lowercased.
+type Wordlists map[string]map[string]struct{}
+
+// LoadWordlists reads the built-in wordlists. Files named <lang>.txt in dir,
+// when dir is set, replace the built-in list of that language or add a new
+// one. Files hold one word per line, lines starting with # are ignored.
+func LoadWordlists(dir string) (Wordlists, error) {
+	wordlists := Wordlists{}
+	if err := readWordlists(defaultWordlists, "wordlists", wordlists); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := readWordlists(os.DirFS(dir), ".", wordlists); err != nil {
+			return nil, err
+		}
+	}
+	return wordlists, nil
+}
+
+func readWordlists(fsys fs.FS, dir string, wordlists Wordlists) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.txt"))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+
+		words := make(map[string]struct{})
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if word != "" && !strings.HasPrefix(word, "#") {
+				words[word] = struct{}{}
+			}
+		}
+		err = errors.Join(scanner.Err(), file.Close())
+		if err != nil {
+			return err
+		}
+
+		wordlists[strings.TrimSuffix(path.Base(name), ".txt")] = words
+	}
+	return nil
+}
+
+// ProfanityFilter looks for the words of the wordlist matching the language
+// of the message, and of the English one since it is widely mixed in. It
+// masks them, rejects the message or flags it.
+type ProfanityFilter struct {
+	Mode      Mode
+	Wordlists Wordlists
+}
+
+func (f ProfanityFilter) Apply(message *Message) error {
+	if f.Mode == ModeOff {
+		return nil
+	}
+
+	languageWords := f.Wordlists[message.Lang]
+	defaultWords := f.Wordlists[defaultLang]
+	isProfane := func(word string) bool {
+		_, inLanguage := languageWords[word]
+		_, inDefault := defaultWords[word]
+		return inLanguage || inDefault
+	}
+
+	found := false
+	text := []rune(message.Text)
+	for start := 0; start < len(text); {
+		if !isWordRune(text[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(text) && isWordRune(text[end]) {
+			end++
+		}
+		if isProfane(strings.ToLower(string(text[start:end]))) {
+			found = true
+			for i := start; i < end; i++ {
+				text[i] = '*'
+			}
+		}
+		start = end
+	}
+	if !found {
+		return nil
+	}
+
+	switch f.Mode {
+	case ModeReject:
+		return &Rejection{Key: "PROFANITY_NOT_ALLOWED"}
+	case ModeFlag:
+		message.Flag("profanity")
+	default:
+		message.Text = string(text)
+	}
+	return nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
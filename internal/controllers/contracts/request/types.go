@@ -1,12 +1,69 @@
 package request
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type RoomRequest struct {
-	Subject string `json:"subject" validate:"required"`
+	Subject          string     `json:"subject" validate:"required,max=255"`
+	Description      string     `json:"description" validate:"max=2000"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at" validate:"omitnil,gtfield=StartsAt"`
+	HostName         string     `json:"host_name" validate:"max=255"`
+	CoverImageURL    string     `json:"cover_image_url" validate:"omitempty,url,max=2048"`
+	Slug             string     `json:"slug" validate:"max=255"`
+	Passcode         string     `json:"passcode" validate:"omitempty,min=4,max=72"`
+	Private          bool       `json:"private"`
+	ReactionKinds    []string   `json:"reaction_kinds" validate:"max=16,unique,dive,min=1,max=32"`
+	DownvotesEnabled bool       `json:"downvotes_enabled"`
+	Tags             []string   `json:"tags" validate:"max=32,unique,dive,min=1,max=32"`
+}
+
+type UpdateRoomRequest struct {
+	Subject          *string    `json:"subject" validate:"omitnil,min=1,max=255"`
+	Description      *string    `json:"description" validate:"omitnil,max=2000"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	HostName         *string    `json:"host_name" validate:"omitnil,max=255"`
+	CoverImageURL    *string    `json:"cover_image_url" validate:"omitempty,url,max=2048"`
+	Slug             *string    `json:"slug" validate:"omitnil,min=1,max=255"`
+	Passcode         *string    `json:"passcode" validate:"omitnil,max=72,passcode"`
+	Private          *bool      `json:"private"`
+	ReactionKinds    []string   `json:"reaction_kinds" validate:"omitempty,max=16,unique,dive,min=1,max=32"`
+	DownvotesEnabled *bool      `json:"downvotes_enabled"`
+	Tags             []string   `json:"tags" validate:"omitempty,max=32,unique,dive,min=1,max=32"`
+}
+
+type RoomAccessRequest struct {
+	Passcode string `json:"passcode" validate:"max=72"`
+	// Host asks for a new host grant, issued to the host and API key holders
+	Host bool `json:"host"`
 }
 
 type MessageRequest struct {
 	RoomID  uuid.UUID `json:"-"`
-	Message string    `json:"message" validate:"required"`
+	Message string    `json:"message" validate:"required,max=255"`
+	Tag     string    `json:"tag" validate:"max=32"`
+}
+
+type PollRequest struct {
+	Question string   `json:"question" validate:"required,max=255"`
+	Options  []string `json:"options" validate:"required,min=2,max=10,unique,dive,required,max=255"`
+}
+
+type PollVoteRequest struct {
+	OptionID      string `json:"option_id" validate:"required,uuid"`
+	ParticipantID string `json:"participant_id" validate:"required,max=64"`
+}
+
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventKinds []string `json:"event_kinds" validate:"unique,dive,oneof=message_created reaction_changed score_changed message_answered message_pinned message_hidden message_spotlighted poll_created poll_voted poll_closed room_updated room_closed room_reopened room_deleted"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+type APIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
 }
@@ -1,16 +1,148 @@
 package response
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type RoomResponse struct {
-	ID      string `json:"id"`
-	Subject string `json:"subject,omitempty"`
+	ID                 string            `json:"id"`
+	Subject            string            `json:"subject,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	StartsAt           *time.Time        `json:"starts_at,omitempty"`
+	EndsAt             *time.Time        `json:"ends_at,omitempty"`
+	HostName           string            `json:"host_name,omitempty"`
+	CoverImageURL      string            `json:"cover_image_url,omitempty"`
+	Slug               string            `json:"slug,omitempty"`
+	JoinCode           string            `json:"join_code,omitempty"`
+	HasPasscode        bool              `json:"has_passcode,omitempty"`
+	Private            bool              `json:"private,omitempty"`
+	ClosedAt           *time.Time        `json:"closed_at,omitempty"`
+	ReactionKinds      []string          `json:"reaction_kinds,omitempty"`
+	DownvotesEnabled   bool              `json:"downvotes_enabled,omitempty"`
+	SpotlightMessageID string            `json:"spotlight_message_id,omitempty"`
+	Tags               []RoomTagResponse `json:"tags,omitempty"`
+}
+
+// CreatedRoomResponse is a new room along with its host grant, only handed
+// out when the room is created.
+type CreatedRoomResponse struct {
+	RoomResponse
+	HostAccess RoomAccessResponse `json:"host_access"`
+}
+
+type RoomTagResponse struct {
+	Name          string `json:"name"`
+	MessagesCount int64  `json:"messages_count"`
+}
+
+type RoomAccessResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type MessageResponse struct {
+	ID              string           `json:"id"`
+	RoomID          string           `json:"room_id"`
+	Message         string           `json:"message,omitempty"`
+	LikesCount      int64            `json:"likes_count,omitempty"`
+	DownvotesCount  int64            `json:"downvotes_count,omitempty"`
+	Score           int64            `json:"score"`
+	Answered        bool             `json:"is_answered,omitempty"`
+	Pinned          bool             `json:"is_pinned,omitempty"`
+	Tag             string           `json:"tag,omitempty"`
+	ModerationFlags []string         `json:"moderation_flags,omitempty"`
+	Hidden          bool             `json:"is_hidden,omitempty"`
+	Reactions       map[string]int64 `json:"reactions,omitempty"`
+}
+
+// RoomStatsResponse counts the messages of a room, the hidden ones included.
+type RoomStatsResponse struct {
+	RoomID         string           `json:"room_id"`
+	MessagesCount  int64            `json:"messages_count"`
+	AnsweredCount  int64            `json:"answered_count"`
+	PinnedCount    int64            `json:"pinned_count"`
+	FlaggedCount   int64            `json:"flagged_count"`
+	HiddenCount    int64            `json:"hidden_count"`
+	LikesCount     int64            `json:"likes_count"`
+	DownvotesCount int64            `json:"downvotes_count"`
+	Reactions      map[string]int64 `json:"reactions"`
+	LastMessageAt  *time.Time       `json:"last_message_at,omitempty"`
+}
+
+type MessageScoreResponse struct {
+	ID             string `json:"id"`
+	LikesCount     int64  `json:"likes_count"`
+	DownvotesCount int64  `json:"downvotes_count"`
+	Score          int64  `json:"score"`
+}
+
+type PollResponse struct {
+	ID         string               `json:"id"`
+	RoomID     string               `json:"room_id"`
+	Question   string               `json:"question"`
+	Options    []PollOptionResponse `json:"options"`
+	TotalVotes int64                `json:"total_votes"`
+	CreatedAt  time.Time            `json:"created_at"`
+	ClosedAt   *time.Time           `json:"closed_at,omitempty"`
+}
+
+type PollOptionResponse struct {
 	ID         string `json:"id"`
-	RoomID     string `json:"room_id"`
-	Message    string `json:"message,omitempty"`
-	LikesCount int64  `json:"likes_count,omitempty"`
-	Answered   bool   `json:"is_answered,omitempty"`
+	Label      string `json:"label"`
+	VotesCount int64  `json:"votes_count"`
+}
+
+type MessageReactionResponse struct {
+	ID        string           `json:"id"`
+	Kind      string           `json:"kind"`
+	Count     int64            `json:"count"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+type MessageImportErrorResponse struct {
+	Row           int           `json:"row"`
+	InvalidParams []ErrorsParam `json:"invalid_params"`
+}
+
+type MessageImportResponse struct {
+	Imported int                          `json:"imported"`
+	Failed   int                          `json:"failed"`
+	Messages []MessageResponse            `json:"messages"`
+	Errors   []MessageImportErrorResponse `json:"errors"`
+}
+
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	RoomID     string    `json:"room_id,omitempty"`
+	URL        string    `json:"url"`
+	EventKinds []string  `json:"event_kinds"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventKind      string          `json:"event_kind"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus *int32          `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+}
+
+// APIKeyResponse holds the key itself only when it was just created, it
+// can't be read again afterwards.
+type APIKeyResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type ErrorsParam struct {
@@ -1,29 +1,135 @@
 package socket
 
+import "time"
+
 const (
 	MessageKindMessageCreated          = "message_created"
 	MessageKindMessageRactionIncreased = "message_reaction_increased"
 	MessageKindMessageRactionDecreased = "message_reaction_decreased"
+	MessageKindMessageScoreChanged     = "message_score_changed"
 	MessageKindMessageAnswered         = "message_answered"
+	MessageKindMessagePinned           = "message_pinned"
+	MessageKindMessageHidden           = "message_hidden"
+	MessageKindMessageSpotlighted      = "message_spotlighted"
+	MessageKindPollCreated             = "poll_created"
+	MessageKindPollVoted               = "poll_voted"
+	MessageKindPollClosed              = "poll_closed"
+	MessageKindRoomUpdated             = "room_updated"
+	MessageKindRoomClosed              = "room_closed"
+	MessageKindRoomReopened            = "room_reopened"
+	MessageKindRoomDeleted             = "room_deleted"
+	MessageKindRateLimited             = "rate_limited"
 )
 
 type MessageMessageReactionIncreased struct {
-	ID    string `json:"id"`
-	Count int64  `json:"count"`
+	ID        string           `json:"id"`
+	Kind      string           `json:"kind"`
+	Count     int64            `json:"count"`
+	Reactions map[string]int64 `json:"reactions"`
 }
 
 type MessageMessageReactionDecreased struct {
-	ID    string `json:"id"`
-	Count int64  `json:"count"`
+	ID        string           `json:"id"`
+	Kind      string           `json:"kind"`
+	Count     int64            `json:"count"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+type MessageMessageScoreChanged struct {
+	ID             string `json:"id"`
+	LikesCount     int64  `json:"likes_count"`
+	DownvotesCount int64  `json:"downvotes_count"`
+	Score          int64  `json:"score"`
 }
 
 type MessageMessageAnswered struct {
 	ID string `json:"id"`
 }
 
+type MessageMessagePinned struct {
+	ID     string `json:"id"`
+	Pinned bool   `json:"pinned"`
+}
+
+// MessageMessageHidden tells the clients to drop a message an admin hid, or
+// to load it again when Hidden is false.
+type MessageMessageHidden struct {
+	ID     string `json:"id"`
+	Hidden bool   `json:"hidden"`
+}
+
+// MessageMessageSpotlighted announces the question the host is answering now.
+// An empty id means the spotlight was cleared.
+type MessageMessageSpotlighted struct {
+	ID string `json:"id"`
+}
+
 type MessageMessageCreated struct {
 	ID      string `json:"id"`
 	Message string `json:"message"`
+	Tag     string `json:"tag,omitempty"`
+}
+
+type MessagePollOption struct {
+	ID         string `json:"id"`
+	Label      string `json:"label"`
+	VotesCount int64  `json:"votes_count"`
+}
+
+type MessagePollCreated struct {
+	ID       string              `json:"id"`
+	Question string              `json:"question"`
+	Options  []MessagePollOption `json:"options"`
+}
+
+type MessagePollVoted struct {
+	ID         string              `json:"id"`
+	Options    []MessagePollOption `json:"options"`
+	TotalVotes int64               `json:"total_votes"`
+}
+
+type MessagePollClosed struct {
+	ID         string              `json:"id"`
+	Options    []MessagePollOption `json:"options"`
+	TotalVotes int64               `json:"total_votes"`
+}
+
+type MessageRoomUpdated struct {
+	ID                 string     `json:"id"`
+	Subject            string     `json:"subject"`
+	Description        string     `json:"description"`
+	StartsAt           *time.Time `json:"starts_at,omitempty"`
+	EndsAt             *time.Time `json:"ends_at,omitempty"`
+	HostName           string     `json:"host_name"`
+	CoverImageURL      string     `json:"cover_image_url"`
+	Slug               string     `json:"slug"`
+	JoinCode           string     `json:"join_code"`
+	DownvotesEnabled   bool       `json:"downvotes_enabled"`
+	SpotlightMessageID string     `json:"spotlight_message_id,omitempty"`
+	Tags               []string   `json:"tags"`
+}
+
+// MessageRoomClosed tells the subscribers the room no longer takes questions.
+// Reason is "ended", "inactive" or "manual".
+type MessageRoomClosed struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type MessageRoomReopened struct {
+	ID string `json:"id"`
+}
+
+type MessageRoomDeleted struct {
+	ID string `json:"id"`
+}
+
+// MessageRateLimited is sent only to the connection that went over its
+// command limit.
+type MessageRateLimited struct {
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+	RetryAfter int    `json:"retry_after"`
 }
 
 type Message struct {
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/decoder"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/services"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// PollsController serves the polls of a room.
+type PollsController struct {
+	service *services.PollsService
+}
+
+func NewPollsController(service *services.PollsService) *PollsController {
+	return &PollsController{
+		service: service,
+	}
+}
+
+// @Summary Create Poll
+// @Description Create a poll in a room
+// @Tags Room Poll
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body request.PollRequest true "Request body"
+// @Success 201 {object} response.PollResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/polls [post]
+func (c *PollsController) CreatePoll(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	var requestBody = request.PollRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	poll, err := c.service.CreatePoll(r.Context(), roomId, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return poll, 201, nil
+}
+
+// @Summary Get Polls
+// @Description Get the polls of a room with their results
+// @Tags Room Poll
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {array} response.PollResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/polls [get]
+func (c *PollsController) GetRoomPolls(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	polls, err := c.service.GetRoomPolls(r.Context(), roomId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return polls, 200, nil
+}
+
+// @Summary Get Poll
+// @Description Get a poll of a room with its results
+// @Tags Room Poll
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param poll_id path string true "Poll ID"
+// @Success 200 {object} response.PollResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/polls/{poll_id} [get]
+func (c *PollsController) GetPoll(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	rawPollId := chi.URLParam(r, "poll_id")
+	pollId, err := uuid.Parse(rawPollId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_POLL_ID")
+	}
+
+	poll, err := c.service.GetPoll(r.Context(), roomId, pollId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return poll, 200, nil
+}
+
+// @Summary Vote Poll
+// @Description Vote for an option of an open poll. Each participant votes once per poll.
+// @Tags Room Poll
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param poll_id path string true "Poll ID"
+// @Param request body request.PollVoteRequest true "Request body"
+// @Success 200 {object} response.PollResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/polls/{poll_id}/votes [post]
+func (c *PollsController) VotePoll(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	rawPollId := chi.URLParam(r, "poll_id")
+	pollId, err := uuid.Parse(rawPollId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_POLL_ID")
+	}
+
+	var requestBody = request.PollVoteRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	poll, err := c.service.VotePoll(r.Context(), roomId, pollId, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return poll, 200, nil
+}
+
+// @Summary Close Poll
+// @Description Close a poll. Closed polls keep their results but take no more votes.
+// @Tags Room Poll
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param poll_id path string true "Poll ID"
+// @Success 200 {object} response.PollResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/polls/{poll_id}/close [patch]
+func (c *PollsController) ClosePoll(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	rawPollId := chi.URLParam(r, "poll_id")
+	pollId, err := uuid.Parse(rawPollId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_POLL_ID")
+	}
+
+	poll, err := c.service.ClosePoll(r.Context(), roomId, pollId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return poll, 200, nil
+}
@@ -3,48 +3,62 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
-	"sync"
+	"strconv"
 
 	_ "github.com/JulioZittei/wsrs-ama-go/docs"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/socket"
 	"github.com/JulioZittei/wsrs-ama-go/internal/decoder"
+	"github.com/JulioZittei/wsrs-ama-go/internal/exception_handler"
+	"github.com/JulioZittei/wsrs-ama-go/internal/exporter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/hub"
+	"github.com/JulioZittei/wsrs-ama-go/internal/importer"
 	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ratelimit"
 	"github.com/JulioZittei/wsrs-ama-go/internal/services"
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+const maxImportFileSize = 5 << 20
+
+const maxSocketCommandSize = 4 << 10
+
 type RoomsController struct {
-	service     *services.RoomsService
-	upgrader    websocket.Upgrader
-	subscribers map[string]map[*websocket.Conn]context.CancelFunc
-	mutex       *sync.Mutex
+	service  *services.RoomsService
+	upgrader websocket.Upgrader
+	hub      *hub.Hub
+	commands *ratelimit.Limiter
 }
 
-func NewRoomsController(service *services.RoomsService, upgrader websocket.Upgrader) *RoomsController {
+func NewRoomsController(service *services.RoomsService, upgrader websocket.Upgrader, hub *hub.Hub, commands *ratelimit.Limiter) *RoomsController {
 	return &RoomsController{
-		service:     service,
-		upgrader:    upgrader,
-		subscribers: make(map[string]map[*websocket.Conn]context.CancelFunc),
-		mutex:       &sync.Mutex{},
+		service:  service,
+		upgrader: upgrader,
+		hub:      hub,
+		commands: commands,
 	}
 }
 
 // @Summary Create room
-// @Description Create a new room
+// @Description Create a new room. The response carries the host grant of the room, send it in the X-Room-Access header to manage the room.
 // @Tags Room
 // @Accept json
 // @Produce json
 // @Param request body request.RoomRequest true "Request body"
-// @Success 201 {object} response.RoomResponse
+// @Success 201 {object} response.CreatedRoomResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /rooms [post]
 func (c *RoomsController) CreateRoom(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -53,13 +67,9 @@ func (c *RoomsController) CreateRoom(w http.ResponseWriter, r *http.Request) (in
 		return nil, 400, err
 	}
 
-	roomId, err := c.service.CreateRoom(r.Context(), &requestBody)
+	data, err := c.service.CreateRoom(r.Context(), &requestBody)
 	if err != nil {
-		return nil, 500, err
-	}
-	data := &response.RoomResponse{
-		ID:      roomId.String(),
-		Subject: requestBody.Subject,
+		return nil, errorStatus(err), err
 	}
 
 	return data, 201, err
@@ -74,8 +84,10 @@ func (c *RoomsController) CreateRoom(w http.ResponseWriter, r *http.Request) (in
 // @Param request body request.MessageRequest true "Request body"
 // @Success 201 {object} response.MessageResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /rooms/{room_id}/messages [post]
 func (c *RoomsController) CreateRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -94,26 +106,57 @@ func (c *RoomsController) CreateRoomMessage(w http.ResponseWriter, r *http.Reque
 
 	messageId, err := c.service.CreateRoomMessage(r.Context(), &requestBody)
 	if err != nil {
-		return nil, 500, err
+		return nil, errorStatus(err), err
 	}
 
 	data := &response.MessageResponse{
 		ID:      messageId.String(),
 		RoomID:  rawRoomId,
 		Message: requestBody.Message,
+		Tag:     requestBody.Tag,
+	}
+
+	return data, 201, err
+}
+
+// @Summary Import Messages
+// @Description Import pre-submitted questions into a room from a JSON array or a CSV file with a "message" column
+// @Tags Room Message
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param format query string false "Import format" Enums(json, csv) default(json)
+// @Param notify query bool false "Emit message_created events for imported messages"
+// @Param request body []request.MessageRequest true "Import file"
+// @Success 200 {object} response.MessageImportResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/import [post]
+func (c *RoomsController) ImportRoomMessages(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
-	go c.notifyClients(socket.Message{
-		Kind: socket.MessageKindMessageCreated,
+	format, ok := importer.ParseFormat(r.URL.Query().Get("format"))
+	if !ok {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_IMPORT_FORMAT")
+	}
 
-		RoomID: rawRoomId,
-		Value: socket.MessageMessageCreated{
-			ID:      data.ID,
-			Message: data.Message,
-		},
-	})
+	notify, _ := strconv.ParseBool(r.URL.Query().Get("notify"))
 
-	return data, 201, err
+	result, err := c.service.ImportRoomMessages(r.Context(), roomId, format, http.MaxBytesReader(w, r.Body, maxImportFileSize), notify)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return result, 200, nil
 }
 
 // @Summary Get Rooms
@@ -129,7 +172,11 @@ func (c *RoomsController) CreateRoomMessage(w http.ResponseWriter, r *http.Reque
 // @Router /rooms [get]
 func (c *RoomsController) GetRooms(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	room, err := c.service.GetRooms(r.Context())
-	return room, 200, err
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return room, 200, nil
 }
 
 // @Summary Get Room Messages
@@ -138,8 +185,11 @@ func (c *RoomsController) GetRooms(w http.ResponseWriter, r *http.Request) (inte
 // @Accept json
 // @Produce json
 // @Param room_id path string true "Room ID"
+// @Param sort query string false "Ordering: created (default), score, wilson or hot" Enums(created, score, wilson, hot)
+// @Param tag query string false "Only messages with this tag"
 // @Success 200 {array} response.MessageResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -151,12 +201,21 @@ func (c *RoomsController) GetRoomMessages(w http.ResponseWriter, r *http.Request
 		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
-	rooms, err := c.service.GetRoomMessages(r.Context(), roomId)
-	return rooms, 200, err
+	by, ok := ranking.ParseSort(r.URL.Query().Get("sort"))
+	if !ok {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_SORT")
+	}
+
+	rooms, err := c.service.GetRoomMessages(r.Context(), roomId, by, r.URL.Query().Get("tag"))
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return rooms, 200, nil
 }
 
 // @Summary Get Room
-// @Description Get room
+// @Description Get room. Without access to a private or passcode protected room only its id, has_passcode and private are returned.
 // @Tags Room
 // @Accept json
 // @Produce json
@@ -174,7 +233,195 @@ func (c *RoomsController) GetRoom(w http.ResponseWriter, r *http.Request) (inter
 		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 	room, err := c.service.GetRoom(r.Context(), roomId)
-	return room, 200, err
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return room, 200, nil
+}
+
+// @Summary Update Room
+// @Description Update the fields of a room that are present in the request body
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body request.UpdateRoomRequest true "Request body"
+// @Success 200 {object} response.RoomResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id} [patch]
+func (c *RoomsController) UpdateRoom(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	var requestBody = request.UpdateRoomRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	room, err := c.service.UpdateRoom(r.Context(), roomId, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return room, 200, nil
+}
+
+// @Summary Regenerate Room Join Code
+// @Description Replace the join code of a room with a new one. The previous code stops working.
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {object} response.RoomResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/join-code [post]
+func (c *RoomsController) RegenerateRoomJoinCode(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	room, err := c.service.RegenerateRoomJoinCode(r.Context(), roomId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return room, 200, nil
+}
+
+// @Summary Grant Room Access
+// @Description Exchange the passcode of a room for an access grant. Private rooms without a passcode only get grants issued with an API key, to be handed to the invited. With host set, the host grant or an API key gets a new host grant instead. Send the grant in the X-Room-Access header, or in the access_token query parameter when subscribing, to read and post messages.
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body request.RoomAccessRequest true "Request body"
+// @Success 200 {object} response.RoomAccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/access [post]
+func (c *RoomsController) GrantRoomAccess(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	var requestBody = request.RoomAccessRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	grant, err := c.service.GrantRoomAccess(r.Context(), roomId, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+	return grant, 200, nil
+}
+
+// @Summary Join Room
+// @Description Resolve a room join code such as K7P-2QX. Without access to a private or passcode protected room only its id, has_passcode and private are returned.
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param code path string true "Join code"
+// @Success 200 {object} response.RoomResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /join/{code} [get]
+func (c *RoomsController) JoinRoom(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	room, err := c.service.GetRoomByJoinCode(r.Context(), chi.URLParam(r, "code"))
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+	return room, 200, nil
+}
+
+// @Summary Delete Room
+// @Description Delete a room and all its messages
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id} [delete]
+func (c *RoomsController) DeleteRoom(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	if err := c.service.DeleteRoom(r.Context(), roomId); err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return nil, 204, nil
+}
+
+// @Summary Export Room
+// @Description Download a room with all its messages, like counts and answered state
+// @Tags Room
+// @Produce json
+// @Produce text/csv
+// @Produce text/markdown
+// @Param room_id path string true "Room ID"
+// @Param format query string false "Export format" Enums(json, csv, md) default(json)
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/export [get]
+func (c *RoomsController) ExportRoom(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	format, ok := exporter.ParseFormat(r.URL.Query().Get("format"))
+	if !ok {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_EXPORT_FORMAT")
+	}
+
+	if err := c.service.AuthorizeRoomAccess(r.Context(), roomId); err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="room-%s.%s"`, rawRoomId, format.Extension()))
+
+	return exception_handler.StreamFunc(func(out io.Writer) error {
+		return c.service.ExportRoom(r.Context(), roomId, format, out)
+	}), 200, nil
 }
 
 // @Summary Get Message
@@ -186,6 +433,7 @@ func (c *RoomsController) GetRoom(w http.ResponseWriter, r *http.Request) (inter
 // @Param message_id path string true "Message ID"
 // @Success 200 {object} response.MessageResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -204,8 +452,11 @@ func (c *RoomsController) GetRoomMessage(w http.ResponseWriter, r *http.Request)
 	}
 
 	message, err := c.service.GetRoomMessage(r.Context(), roomId, messageId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
 
-	return message, 200, err
+	return message, 200, nil
 }
 
 // @Summary Like Message
@@ -219,143 +470,372 @@ func (c *RoomsController) GetRoomMessage(w http.ResponseWriter, r *http.Request)
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /rooms/{room_id}/messages/{message_id}/like [patch]
 func (c *RoomsController) LikeRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	reaction, status, err := c.reactToRoomMessage(r, services.DefaultReactionKind)
+	if err != nil {
+		return 0, status, err
+	}
+	return reaction.Count, status, nil
+}
+
+// @Summary Unlike Message
+// @Description Unlike Room Message
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {integer} int
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/like [delete]
+func (c *RoomsController) RemoveLikeRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	reaction, status, err := c.removeRoomMessageReaction(r, services.DefaultReactionKind)
+	if err != nil {
+		return 0, status, err
+	}
+	return reaction.Count, status, nil
+}
+
+// @Summary React To Message
+// @Description Add a reaction of the given kind to a room message. The kind must be enabled in the room.
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Param kind path string true "Reaction kind"
+// @Success 200 {object} response.MessageReactionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/reactions/{kind} [patch]
+func (c *RoomsController) ReactToRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return c.reactToRoomMessage(r, chi.URLParam(r, "kind"))
+}
+
+// @Summary Remove Message Reaction
+// @Description Remove a reaction of the given kind from a room message
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Param kind path string true "Reaction kind"
+// @Success 200 {object} response.MessageReactionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/reactions/{kind} [delete]
+func (c *RoomsController) RemoveRoomMessageReaction(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return c.removeRoomMessageReaction(r, chi.URLParam(r, "kind"))
+}
+
+// @Summary Downvote Message
+// @Description Downvote a room message. Only available in rooms with downvotes enabled.
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.MessageScoreResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/downvote [patch]
+func (c *RoomsController) DownvoteRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	rawRoomId := chi.URLParam(r, "room_id")
 	roomId, err := uuid.Parse(rawRoomId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
 	rawMessageId := chi.URLParam(r, "message_id")
 	messageId, err := uuid.Parse(rawMessageId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
 	}
-	likeCount, err := c.service.LikeRoomMessage(r.Context(), roomId, messageId)
 
-	go c.notifyClients(socket.Message{
-		Kind:   socket.MessageKindMessageRactionIncreased,
-		RoomID: rawRoomId,
-		Value: socket.MessageMessageReactionIncreased{
-			ID:    rawMessageId,
-			Count: likeCount,
-		},
-	})
+	score, err := c.service.DownvoteRoomMessage(r.Context(), roomId, messageId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
 
-	return likeCount, 200, err
+	return score, 200, nil
 }
 
-// @Summary Unlike Message
-// @Description Unlike Room Message
+// @Summary Remove Message Downvote
+// @Description Remove a downvote from a room message
 // @Tags Room Message
 // @Accept json
 // @Produce json
 // @Param room_id path string true "Room ID"
 // @Param message_id path string true "Message ID"
-// @Success 200 {integer} int
+// @Success 200 {object} response.MessageScoreResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /rooms/{room_id}/messages/{message_id}/like [delete]
-func (c *RoomsController) RemoveLikeRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+// @Router /rooms/{room_id}/messages/{message_id}/downvote [delete]
+func (c *RoomsController) RemoveRoomMessageDownvote(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	rawRoomId := chi.URLParam(r, "room_id")
 	roomId, err := uuid.Parse(rawRoomId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
 	rawMessageId := chi.URLParam(r, "message_id")
 	messageId, err := uuid.Parse(rawMessageId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
 	}
-	likeCount, err := c.service.RemoveLikeRoomMessage(r.Context(), roomId, messageId)
 
-	go c.notifyClients(socket.Message{
-		Kind:   socket.MessageKindMessageRactionDecreased,
-		RoomID: rawRoomId,
-		Value: socket.MessageMessageReactionDecreased{
-			ID:    rawMessageId,
-			Count: likeCount,
-		},
-	})
+	score, err := c.service.RemoveRoomMessageDownvote(r.Context(), roomId, messageId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
 
-	return likeCount, 200, err
+	return score, 200, nil
 }
 
-// @Summary Mark Message As Answered
-// @Description Mark a message as answered
+func (c *RoomsController) reactToRoomMessage(r *http.Request, kind string) (*response.MessageReactionResponse, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	rawMessageId := chi.URLParam(r, "message_id")
+	messageId, err := uuid.Parse(rawMessageId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+	}
+
+	reaction, err := c.service.ReactToRoomMessage(r.Context(), roomId, messageId, kind)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return reaction, 200, nil
+}
+
+func (c *RoomsController) removeRoomMessageReaction(r *http.Request, kind string) (*response.MessageReactionResponse, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	rawMessageId := chi.URLParam(r, "message_id")
+	messageId, err := uuid.Parse(rawMessageId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+	}
+
+	reaction, err := c.service.RemoveRoomMessageReaction(r.Context(), roomId, messageId, kind)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return reaction, 200, nil
+}
+
+// @Summary Pin Message
+// @Description Pin a message to the top of the room
 // @Tags Room Message
 // @Accept json
 // @Produce json
 // @Param room_id path string true "Room ID"
 // @Param message_id path string true "Message ID"
-// @Success 200
+// @Success 200 {object} response.MessageResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /rooms/{room_id}/messages/{message_id}/answer [patch]
-func (c *RoomsController) AnswerRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+// @Router /rooms/{room_id}/messages/{message_id}/pin [patch]
+func (c *RoomsController) PinRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return c.pinRoomMessage(r, true)
+}
+
+// @Summary Unpin Message
+// @Description Unpin a message of the room
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/pin [delete]
+func (c *RoomsController) UnpinRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return c.pinRoomMessage(r, false)
+}
+
+func (c *RoomsController) pinRoomMessage(r *http.Request, pinned bool) (*response.MessageResponse, int, error) {
 	rawRoomId := chi.URLParam(r, "room_id")
 	roomId, err := uuid.Parse(rawRoomId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
 	rawMessageId := chi.URLParam(r, "message_id")
 	messageId, err := uuid.Parse(rawMessageId)
 	if err != nil {
-		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
 	}
 
-	go c.notifyClients(socket.Message{
-		Kind:   socket.MessageKindMessageAnswered,
-		RoomID: rawRoomId,
-		Value: socket.MessageMessageAnswered{
-			ID: rawMessageId,
-		},
-	})
+	message, err := c.service.PinRoomMessage(r.Context(), roomId, messageId, pinned)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
 
-	return nil, 200, c.service.AnswerRoomMessage(r.Context(), roomId, messageId)
+	return message, 200, nil
 }
 
-func (c *RoomsController) notifyClients(message socket.Message) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// @Summary Spotlight Message
+// @Description Mark the message the host is answering now. It replaces the previous spotlight of the room.
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} response.RoomResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/spotlight [patch]
+func (c *RoomsController) SpotlightRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
 
-	subscribers, ok := c.subscribers[message.RoomID]
-	if !ok || len(subscribers) == 0 {
-		return
+	rawMessageId := chi.URLParam(r, "message_id")
+	messageId, err := uuid.Parse(rawMessageId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
 	}
 
-	for conn, cancel := range subscribers {
-		if err := conn.WriteJSON(message); err != nil {
-			slog.Error("failed to send message to client", "error", err)
-			cancel()
-		}
+	room, err := c.service.SpotlightRoomMessage(r.Context(), roomId, &messageId)
+	if err != nil {
+		return nil, errorStatus(err), err
 	}
+
+	return room, 200, nil
 }
 
-func (c *RoomsController) SubscribeRoom(w http.ResponseWriter, r *http.Request) {
+// @Summary Clear Spotlight
+// @Description Clear the spotlight of a room
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {object} response.RoomResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/spotlight [delete]
+func (c *RoomsController) ClearRoomSpotlight(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	rawRoomId := chi.URLParam(r, "room_id")
 	roomId, err := uuid.Parse(rawRoomId)
 	if err != nil {
-		http.Error(w, "invalid room id", http.StatusBadRequest)
-		return
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	room, err := c.service.SpotlightRoomMessage(r.Context(), roomId, nil)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return room, 200, nil
+}
+
+// @Summary Mark Message As Answered
+// @Description Mark a message as answered
+// @Tags Room Message
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param message_id path string true "Message ID"
+// @Success 200
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/messages/{message_id}/answer [patch]
+func (c *RoomsController) AnswerRoomMessage(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
 	}
 
-	_, err = c.service.GetRoom(r.Context(), roomId)
+	rawMessageId := chi.URLParam(r, "message_id")
+	messageId, err := uuid.Parse(rawMessageId)
 	if err != nil {
-		if errors.Is(err, internal_errors.ErrNotFound) {
+		return 0, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_MESSAGE_ID")
+	}
+
+	if err := c.service.AnswerRoomMessage(r.Context(), roomId, messageId); err != nil {
+		return nil, errorStatus(err), err
+	}
+	return nil, 200, nil
+}
+
+func (c *RoomsController) SubscribeRoom(w http.ResponseWriter, r *http.Request) {
+	rawRoomId := chi.URLParam(r, "room_id")
+
+	// rooms can be joined either by id or by join code
+	var room *response.RoomResponse
+	var err error
+	if roomId, parseErr := uuid.Parse(rawRoomId); parseErr == nil {
+		room, err = c.service.GetRoom(r.Context(), roomId)
+	} else {
+		room, err = c.service.GetRoomByJoinCode(r.Context(), rawRoomId)
+	}
+	if err != nil {
+		switch errorStatus(err) {
+		case http.StatusBadRequest:
+			http.Error(w, "invalid room id", http.StatusBadRequest)
+		case http.StatusNotFound:
 			http.Error(w, "room not found", http.StatusBadRequest)
-			return
+		default:
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
 		}
+		return
+	}
+	roomId := uuid.MustParse(room.ID)
 
-		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	if err := c.service.AuthorizeRoomAccess(r.Context(), roomId); err != nil {
+		http.Error(w, "room access required", http.StatusForbidden)
 		return
 	}
 
@@ -370,17 +850,73 @@ func (c *RoomsController) SubscribeRoom(w http.ResponseWriter, r *http.Request)
 
 	ctx, cancel := context.WithCancel(r.Context())
 
-	c.mutex.Lock()
-	if _, ok := c.subscribers[rawRoomId]; !ok {
-		c.subscribers[rawRoomId] = make(map[*websocket.Conn]context.CancelFunc)
-	}
-	slog.Info("new client connected", "room_id", rawRoomId, "client_ip", r.RemoteAddr)
-	c.subscribers[rawRoomId][conn] = cancel
-	c.mutex.Unlock()
+	slog.Info("new client connected", "room_id", room.ID, "client_ip", r.RemoteAddr)
+	c.hub.Subscribe(roomId, conn, cancel)
+
+	go c.readCommands(ctx, conn, cancel, ratelimit.ByIP(r))
 
 	<-ctx.Done()
 
-	c.mutex.Lock()
-	delete(c.subscribers[rawRoomId], conn)
-	c.mutex.Unlock()
+	c.hub.Unsubscribe(roomId, conn)
+}
+
+// readCommands consumes the frames a subscriber sends until the connection
+// is closed. There are no commands yet, but every frame counts against the
+// command limit so a client can't flood the server through its socket.
+func (c *RoomsController) readCommands(ctx context.Context, conn *websocket.Conn, cancel context.CancelFunc, key string) {
+	defer cancel()
+
+	conn.SetReadLimit(maxSocketCommandSize)
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+
+		ok, retryAfter := c.commands.Allow(key)
+		if ok {
+			continue
+		}
+
+		errTooManyRequests := internal_errors.NewErrTooManyRequests(ctx, retryAfter)
+		err := c.hub.Send(conn, socket.Message{
+			Kind: socket.MessageKindRateLimited,
+			Value: socket.MessageRateLimited{
+				Title:      errTooManyRequests.Title,
+				Detail:     errTooManyRequests.Detail,
+				RetryAfter: internal_errors.RetryAfterSeconds(retryAfter),
+			},
+		})
+		if err != nil {
+			slog.Error("failed to send message to client", "error", err)
+			return
+		}
+	}
+}
+
+func errorStatus(err error) int {
+	var errBadRequest *internal_errors.ErrorBadRequest
+	var errNotFound *internal_errors.ErrorNotFound
+	var errValidation *internal_errors.ErrorValidation
+	var errForbidden *internal_errors.ErrorForbidden
+	var errTooManyRequests *internal_errors.ErrorTooManyRequests
+	var errConflict *internal_errors.ErrorConflict
+	var errServiceUnavailable *internal_errors.ErrorServiceUnavailable
+	switch {
+	case errors.As(err, &errBadRequest):
+		return 400
+	case errors.As(err, &errNotFound):
+		return 404
+	case errors.As(err, &errValidation):
+		return 422
+	case errors.As(err, &errForbidden):
+		return 403
+	case errors.As(err, &errTooManyRequests):
+		return 429
+	case errors.As(err, &errConflict):
+		return 409
+	case errors.As(err, &errServiceUnavailable):
+		return 503
+	default:
+		return 500
+	}
 }
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
+	"github.com/JulioZittei/wsrs-ama-go/internal/services"
+	"github.com/JulioZittei/wsrs-ama-go/internal/slashcommand"
+	"github.com/JulioZittei/wsrs-ama-go/internal/validator"
+	"github.com/google/uuid"
+)
+
+const (
+	// the platforms send a few short form fields
+	maxSlashCommandSize = 16 << 10
+	topQuestionsCount   = 5
+)
+
+// SlashCommandsController answers the slash commands of the chat
+// platforms. The platforms show nothing for error statuses, so once a
+// command is verified every outcome is a message with a 200 status.
+type SlashCommandsController struct {
+	service *services.RoomsService
+	secret  []byte
+}
+
+func NewSlashCommandsController(service *services.RoomsService, secret []byte) *SlashCommandsController {
+	return &SlashCommandsController{
+		service: service,
+		secret:  secret,
+	}
+}
+
+// @Summary Slash Command
+// @Description Answer a Slack or Mattermost slash command. `ask <join code> <question>` asks a question in a room and `top <join code>` lists its top questions. Slack requests are verified with their signature, Mattermost ones with the command token.
+// @Tags Integration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param text formData string true "Text typed after the command"
+// @Param token formData string false "Command token, sent by Mattermost"
+// @Param X-Slack-Signature header string false "Request signature, sent by Slack"
+// @Param X-Slack-Request-Timestamp header string false "Request timestamp, sent by Slack"
+// @Success 200 {object} slashcommand.Message
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /integrations/slash-commands [post]
+func (c *SlashCommandsController) HandleCommand(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSlashCommandSize))
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_SLASH_COMMAND")
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_SLASH_COMMAND")
+	}
+
+	if !slashcommand.Verify(c.secret, r.Header, body, form, time.Now()) {
+		return nil, 403, internal_errors.NewErrForbidden(r.Context(), "INVALID_SLASH_COMMAND_SIGNATURE")
+	}
+
+	command, err := slashcommand.Parse(form.Get("text"))
+	if err != nil {
+		usage, _ := locale.GetMessage(r.Context(), "SLASH_COMMAND_USAGE")
+		return slashcommand.Message{ResponseType: slashcommand.Ephemeral, Text: usage}, 200, nil
+	}
+
+	var message *slashcommand.Message
+	switch command.Action {
+	case slashcommand.ActionAsk:
+		message, err = c.ask(r.Context(), command)
+	case slashcommand.ActionTop:
+		message, err = c.top(r.Context(), command)
+	}
+	if err != nil {
+		return slashcommand.Message{ResponseType: slashcommand.Ephemeral, Text: commandErrorText(r.Context(), err)}, 200, nil
+	}
+
+	return message, 200, nil
+}
+
+func (c *SlashCommandsController) ask(ctx context.Context, command slashcommand.Command) (*slashcommand.Message, error) {
+	room, err := c.service.GetRoomByJoinCode(ctx, command.JoinCode)
+	if err != nil {
+		return nil, err
+	}
+
+	params := request.MessageRequest{RoomID: uuid.MustParse(room.ID), Message: command.Question}
+	if err := validator.ValidateStruct(ctx, &params); err != nil {
+		return nil, err
+	}
+	if _, err := c.service.CreateRoomMessage(ctx, &params); err != nil {
+		return nil, err
+	}
+
+	// the question is only confirmed to whoever asked it, as anonymous as
+	// on the web
+	text, _ := locale.GetMessage(ctx, "SLASH_COMMAND_QUESTION_POSTED", slashcommand.Escape(room.Subject))
+	return &slashcommand.Message{ResponseType: slashcommand.Ephemeral, Text: text}, nil
+}
+
+func (c *SlashCommandsController) top(ctx context.Context, command slashcommand.Command) (*slashcommand.Message, error) {
+	room, err := c.service.GetRoomByJoinCode(ctx, command.JoinCode)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := c.service.GetRoomMessages(ctx, uuid.MustParse(room.ID), ranking.SortScore, "")
+	if err != nil {
+		return nil, err
+	}
+
+	subject := slashcommand.Escape(room.Subject)
+	if len(messages) == 0 {
+		text, _ := locale.GetMessage(ctx, "SLASH_COMMAND_NO_QUESTIONS", subject)
+		return &slashcommand.Message{ResponseType: slashcommand.Ephemeral, Text: text}, nil
+	}
+
+	heading, _ := locale.GetMessage(ctx, "SLASH_COMMAND_TOP_QUESTIONS", subject)
+	lines := []string{heading}
+	for i, message := range messages[:min(len(messages), topQuestionsCount)] {
+		line, _ := locale.GetMessage(ctx, "SLASH_COMMAND_TOP_QUESTION",
+			fmt.Sprint(i+1), slashcommand.Escape(message.Message), fmt.Sprint(message.Score))
+		lines = append(lines, line)
+	}
+	return &slashcommand.Message{ResponseType: slashcommand.InChannel, Text: strings.Join(lines, "\n")}, nil
+}
+
+// commandErrorText tells why a command failed in the words of the error
+// responses of the API.
+func commandErrorText(ctx context.Context, err error) string {
+	var errValidation *internal_errors.ErrorValidation
+	var errBadRequest *internal_errors.ErrorBadRequest
+	var errNotFound *internal_errors.ErrorNotFound
+	var errForbidden *internal_errors.ErrorForbidden
+	var errTooManyRequests *internal_errors.ErrorTooManyRequests
+	var errConflict *internal_errors.ErrorConflict
+	var errServiceUnavailable *internal_errors.ErrorServiceUnavailable
+	switch {
+	case errors.As(err, &errValidation):
+		messages := make([]string, len(errValidation.ErrorsParam))
+		for i, param := range errValidation.ErrorsParam {
+			messages[i] = param.Param + " " + param.Message
+		}
+		return strings.Join(messages, "\n")
+	case errors.As(err, &errBadRequest):
+		return errorText(errBadRequest.Detail, errBadRequest.Title)
+	case errors.As(err, &errNotFound):
+		return errorText(errNotFound.Detail, errNotFound.Title)
+	case errors.As(err, &errForbidden):
+		return errorText(errForbidden.Detail, errForbidden.Title)
+	case errors.As(err, &errTooManyRequests):
+		return errorText(errTooManyRequests.Detail, errTooManyRequests.Title)
+	case errors.As(err, &errConflict):
+		return errorText(errConflict.Detail, errConflict.Title)
+	case errors.As(err, &errServiceUnavailable):
+		return errorText(errServiceUnavailable.Detail, errServiceUnavailable.Title)
+	default:
+		slog.Error("something went wrong while running a slash command", "error", err)
+		text, _ := locale.GetMessage(ctx, "INTERNAL_SERVER_ERROR")
+		return text
+	}
+}
+
+// errorText falls back to the title of errors without details.
+func errorText(detail string, title string) string {
+	if detail != "" {
+		return detail
+	}
+	return title
+}
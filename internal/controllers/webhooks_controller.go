@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/decoder"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/services"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+// WebhooksController serves the webhooks of the rooms and their delivery
+// log.
+type WebhooksController struct {
+	service *services.WebhooksService
+}
+
+func NewWebhooksController(service *services.WebhooksService) *WebhooksController {
+	return &WebhooksController{
+		service: service,
+	}
+}
+
+// @Summary Create Webhook
+// @Description Create a webhook for the events of every room, with an API key. The URL must resolve to a public address. The secret signing the deliveries is generated when none is sent and only returned here.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body request.WebhookRequest true "Request body"
+// @Success 201 {object} response.WebhookResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks [post]
+func (c *WebhooksController) CreateWebhook(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	var requestBody = request.WebhookRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	webhook, err := c.service.CreateWebhook(r.Context(), nil, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return webhook, 201, nil
+}
+
+// @Summary Get Webhooks
+// @Description Get the webhooks for the events of every room, with an API key
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Success 200 {array} response.WebhookResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks [get]
+func (c *WebhooksController) GetWebhooks(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	webhooks, err := c.service.GetWebhooks(r.Context(), nil)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return webhooks, 200, nil
+}
+
+// @Summary Create Room Webhook
+// @Description Create a webhook for the events of a room. The URL must resolve to a public address. The secret signing the deliveries is generated when none is sent and only returned here.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param request body request.WebhookRequest true "Request body"
+// @Success 201 {object} response.WebhookResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/webhooks [post]
+func (c *WebhooksController) CreateRoomWebhook(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	var requestBody = request.WebhookRequest{}
+	if err := decoder.DecodeJSON(r.Context(), r.Body, &requestBody); err != nil {
+		return nil, 400, err
+	}
+
+	webhook, err := c.service.CreateWebhook(r.Context(), &roomId, &requestBody)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return webhook, 201, nil
+}
+
+// @Summary Get Room Webhooks
+// @Description Get the webhooks for the events of a room
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Success 200 {array} response.WebhookResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /rooms/{room_id}/webhooks [get]
+func (c *WebhooksController) GetRoomWebhooks(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawRoomId := chi.URLParam(r, "room_id")
+	roomId, err := uuid.Parse(rawRoomId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_ROOM_ID")
+	}
+
+	webhooks, err := c.service.GetWebhooks(r.Context(), &roomId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return webhooks, 200, nil
+}
+
+// @Summary Get Webhook
+// @Description Get a webhook
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook ID"
+// @Success 200 {object} response.WebhookResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/{webhook_id} [get]
+func (c *WebhooksController) GetWebhook(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawWebhookId := chi.URLParam(r, "webhook_id")
+	webhookId, err := uuid.Parse(rawWebhookId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_WEBHOOK_ID")
+	}
+
+	webhook, err := c.service.GetWebhook(r.Context(), webhookId)
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return webhook, 200, nil
+}
+
+// @Summary Delete Webhook
+// @Description Delete a webhook and its delivery log. Pending deliveries are not sent.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/{webhook_id} [delete]
+func (c *WebhooksController) DeleteWebhook(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawWebhookId := chi.URLParam(r, "webhook_id")
+	webhookId, err := uuid.Parse(rawWebhookId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_WEBHOOK_ID")
+	}
+
+	if err := c.service.DeleteWebhook(r.Context(), webhookId); err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return nil, 204, nil
+}
+
+// @Summary Get Webhook Deliveries
+// @Description Get the latest deliveries of a webhook, newest first, with the outcome of their last attempt
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(pending, delivered, failed)
+// @Param limit query int false "Maximum number of deliveries, 50 by default and 200 at most"
+// @Success 200 {array} response.WebhookDeliveryResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/{webhook_id}/deliveries [get]
+func (c *WebhooksController) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	rawWebhookId := chi.URLParam(r, "webhook_id")
+	webhookId, err := uuid.Parse(rawWebhookId)
+	if err != nil {
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_WEBHOOK_ID")
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_DELIVERY_STATUS")
+	}
+
+	limit := defaultDeliveriesLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 {
+			return nil, 400, internal_errors.NewErrBadRequest(r.Context(), "INVALID_LIMIT")
+		}
+		limit = min(limit, maxDeliveriesLimit)
+	}
+
+	deliveries, err := c.service.GetWebhookDeliveries(r.Context(), webhookId, status, int32(limit))
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+
+	return deliveries, 200, nil
+}
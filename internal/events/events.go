@@ -0,0 +1,245 @@
+// Package events holds what happens to the rooms, as the services tell it.
+// The services publish an event along with the change it describes and the
+// subscribers, such as the websocket hub, learn about it once the change is
+// committed. None of them needs to know about the others.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Kind string
+
+const (
+	KindMessageCreated     Kind = "message_created"
+	KindReactionChanged    Kind = "reaction_changed"
+	KindScoreChanged       Kind = "score_changed"
+	KindMessageAnswered    Kind = "message_answered"
+	KindMessagePinned      Kind = "message_pinned"
+	KindMessageHidden      Kind = "message_hidden"
+	KindMessageSpotlighted Kind = "message_spotlighted"
+	KindPollCreated        Kind = "poll_created"
+	KindPollVoted          Kind = "poll_voted"
+	KindPollClosed         Kind = "poll_closed"
+	KindRoomUpdated        Kind = "room_updated"
+	KindRoomClosed         Kind = "room_closed"
+	KindRoomReopened       Kind = "room_reopened"
+	KindRoomDeleted        Kind = "room_deleted"
+)
+
+// Event is something that happened to a room. It is stored and sent as the
+// JSON of the struct that implements it.
+type Event interface {
+	Kind() Kind
+	Room() uuid.UUID
+}
+
+// Publisher takes the events of the changes the services make. Publish is
+// called in the unit of work of the change, an error rolls the change back.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Subscriber is told about the events of the committed changes. An event
+// may be handed to a subscriber again when delivering it failed.
+type Subscriber interface {
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+type MessageCreated struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Message   string    `json:"message"`
+	Tag       string    `json:"tag,omitempty"`
+}
+
+// ReactionChanged tells that a reaction of Reaction kind was added to a
+// message, or taken back when Added is false. Count is the number of
+// reactions of that kind the message has now.
+type ReactionChanged struct {
+	RoomID    uuid.UUID        `json:"room_id"`
+	MessageID uuid.UUID        `json:"message_id"`
+	Reaction  string           `json:"reaction"`
+	Added     bool             `json:"added"`
+	Count     int64            `json:"count"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+// ScoreChanged follows the likes and the downvotes of a message.
+type ScoreChanged struct {
+	RoomID         uuid.UUID `json:"room_id"`
+	MessageID      uuid.UUID `json:"message_id"`
+	LikesCount     int64     `json:"likes_count"`
+	DownvotesCount int64     `json:"downvotes_count"`
+	Score          int64     `json:"score"`
+}
+
+type MessageAnswered struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	MessageID uuid.UUID `json:"message_id"`
+}
+
+type MessagePinned struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Pinned    bool      `json:"pinned"`
+}
+
+// MessageHidden tells that an admin took a message out of the room, or put
+// it back when Hidden is false.
+type MessageHidden struct {
+	RoomID    uuid.UUID `json:"room_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Hidden    bool      `json:"hidden"`
+}
+
+// MessageSpotlighted tells which message the host is answering now. A nil
+// MessageID means the spotlight was cleared.
+type MessageSpotlighted struct {
+	RoomID    uuid.UUID  `json:"room_id"`
+	MessageID *uuid.UUID `json:"message_id"`
+}
+
+type PollOption struct {
+	ID         uuid.UUID `json:"id"`
+	Label      string    `json:"label"`
+	VotesCount int64     `json:"votes_count"`
+}
+
+type PollCreated struct {
+	RoomID   uuid.UUID    `json:"room_id"`
+	PollID   uuid.UUID    `json:"poll_id"`
+	Question string       `json:"question"`
+	Options  []PollOption `json:"options"`
+}
+
+type PollVoted struct {
+	RoomID     uuid.UUID    `json:"room_id"`
+	PollID     uuid.UUID    `json:"poll_id"`
+	Options    []PollOption `json:"options"`
+	TotalVotes int64        `json:"total_votes"`
+}
+
+type PollClosed struct {
+	RoomID     uuid.UUID    `json:"room_id"`
+	PollID     uuid.UUID    `json:"poll_id"`
+	Options    []PollOption `json:"options"`
+	TotalVotes int64        `json:"total_votes"`
+}
+
+// RoomUpdated carries the public details of a room after a change. JoinCode
+// is in the form shown to the participants.
+type RoomUpdated struct {
+	RoomID             uuid.UUID  `json:"room_id"`
+	Subject            string     `json:"subject"`
+	Description        string     `json:"description"`
+	StartsAt           *time.Time `json:"starts_at,omitempty"`
+	EndsAt             *time.Time `json:"ends_at,omitempty"`
+	HostName           string     `json:"host_name"`
+	CoverImageURL      string     `json:"cover_image_url"`
+	Slug               string     `json:"slug"`
+	JoinCode           string     `json:"join_code"`
+	DownvotesEnabled   bool       `json:"downvotes_enabled"`
+	SpotlightMessageID *uuid.UUID `json:"spotlight_message_id,omitempty"`
+	Tags               []string   `json:"tags"`
+}
+
+type CloseReason string
+
+const (
+	CloseReasonEnded    CloseReason = "ended"
+	CloseReasonInactive CloseReason = "inactive"
+	CloseReasonManual   CloseReason = "manual"
+)
+
+type RoomClosed struct {
+	RoomID uuid.UUID   `json:"room_id"`
+	Reason CloseReason `json:"reason"`
+}
+
+type RoomReopened struct {
+	RoomID uuid.UUID `json:"room_id"`
+}
+
+type RoomDeleted struct {
+	RoomID uuid.UUID `json:"room_id"`
+}
+
+func (MessageCreated) Kind() Kind     { return KindMessageCreated }
+func (ReactionChanged) Kind() Kind    { return KindReactionChanged }
+func (ScoreChanged) Kind() Kind       { return KindScoreChanged }
+func (MessageAnswered) Kind() Kind    { return KindMessageAnswered }
+func (MessagePinned) Kind() Kind      { return KindMessagePinned }
+func (MessageHidden) Kind() Kind      { return KindMessageHidden }
+func (MessageSpotlighted) Kind() Kind { return KindMessageSpotlighted }
+func (PollCreated) Kind() Kind        { return KindPollCreated }
+func (PollVoted) Kind() Kind          { return KindPollVoted }
+func (PollClosed) Kind() Kind         { return KindPollClosed }
+func (RoomUpdated) Kind() Kind        { return KindRoomUpdated }
+func (RoomClosed) Kind() Kind         { return KindRoomClosed }
+func (RoomReopened) Kind() Kind       { return KindRoomReopened }
+func (RoomDeleted) Kind() Kind        { return KindRoomDeleted }
+
+func (e MessageCreated) Room() uuid.UUID     { return e.RoomID }
+func (e ReactionChanged) Room() uuid.UUID    { return e.RoomID }
+func (e ScoreChanged) Room() uuid.UUID       { return e.RoomID }
+func (e MessageAnswered) Room() uuid.UUID    { return e.RoomID }
+func (e MessagePinned) Room() uuid.UUID      { return e.RoomID }
+func (e MessageHidden) Room() uuid.UUID      { return e.RoomID }
+func (e MessageSpotlighted) Room() uuid.UUID { return e.RoomID }
+func (e PollCreated) Room() uuid.UUID        { return e.RoomID }
+func (e PollVoted) Room() uuid.UUID          { return e.RoomID }
+func (e PollClosed) Room() uuid.UUID         { return e.RoomID }
+func (e RoomUpdated) Room() uuid.UUID        { return e.RoomID }
+func (e RoomClosed) Room() uuid.UUID         { return e.RoomID }
+func (e RoomReopened) Room() uuid.UUID       { return e.RoomID }
+func (e RoomDeleted) Room() uuid.UUID        { return e.RoomID }
+
+// Decode turns a stored event back into the struct of its kind.
+func Decode(kind Kind, payload []byte) (Event, error) {
+	switch kind {
+	case KindMessageCreated:
+		return decode[MessageCreated](payload)
+	case KindReactionChanged:
+		return decode[ReactionChanged](payload)
+	case KindScoreChanged:
+		return decode[ScoreChanged](payload)
+	case KindMessageAnswered:
+		return decode[MessageAnswered](payload)
+	case KindMessagePinned:
+		return decode[MessagePinned](payload)
+	case KindMessageHidden:
+		return decode[MessageHidden](payload)
+	case KindMessageSpotlighted:
+		return decode[MessageSpotlighted](payload)
+	case KindPollCreated:
+		return decode[PollCreated](payload)
+	case KindPollVoted:
+		return decode[PollVoted](payload)
+	case KindPollClosed:
+		return decode[PollClosed](payload)
+	case KindRoomUpdated:
+		return decode[RoomUpdated](payload)
+	case KindRoomClosed:
+		return decode[RoomClosed](payload)
+	case KindRoomReopened:
+		return decode[RoomReopened](payload)
+	case KindRoomDeleted:
+		return decode[RoomDeleted](payload)
+	default:
+		return nil, fmt.Errorf("unknown event kind %q", kind)
+	}
+}
+
+func decode[T Event](payload []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -1,7 +1,11 @@
 package exception_handler
 
 import (
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
 	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
@@ -10,24 +14,61 @@ import (
 
 type ControllerFunc func(w http.ResponseWriter, r *http.Request) (interface{}, int, error)
 
+// StreamFunc can be returned by a ControllerFunc that writes its own response
+// body, e.g. file downloads. It runs after the status code has been written,
+// so it must only fail on write or streaming errors. When it fails the
+// connection is dropped, the client gets a cut short response rather than
+// one that looks complete.
+type StreamFunc func(w io.Writer) error
+
 func ExceptionHandler(controllerFunc ControllerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		obj, status, err := controllerFunc(w, r)
 
 		if err != nil {
-			errorResponse := handleError(r, err)
-			w.WriteHeader(status)
-			render.JSON(w, r, errorResponse)
+			WriteError(w, r, status, err)
 			return
 		}
 
 		w.WriteHeader(status)
+		if stream, ok := obj.(StreamFunc); ok {
+			if err := stream(w); err != nil {
+				slog.Error("failed to stream response", "error", err)
+				abortResponse(w)
+			}
+			return
+		}
+
 		if obj != nil {
 			render.JSON(w, r, obj)
 		}
 	})
 }
 
+// abortResponse drops the connection of a response that can't be finished.
+// The Recoverer middleware swallows http.ErrAbortHandler, so the connection
+// is hijacked and closed where the protocol allows it.
+func abortResponse(w http.ResponseWriter) {
+	if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+		conn.Close()
+		return
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// WriteError writes err as an error response, for handlers and middlewares
+// that don't go through ExceptionHandler.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	var errTooManyRequests *internal_errors.ErrorTooManyRequests
+	if errors.As(err, &errTooManyRequests) {
+		w.Header().Set("Retry-After", strconv.Itoa(internal_errors.RetryAfterSeconds(errTooManyRequests.RetryAfter)))
+	}
+
+	errorResponse := handleError(r, err)
+	w.WriteHeader(status)
+	render.JSON(w, r, errorResponse)
+}
+
 func handleError(r *http.Request, err error) *response.ErrorResponse {
 	switch err := err.(type) {
 	case *internal_errors.ErrorValidation:
@@ -38,6 +79,14 @@ func handleError(r *http.Request, err error) *response.ErrorResponse {
 		return buildBadRequestResponse(r, err)
 	case *internal_errors.ErrorNotFound:
 		return buildNotFoundResponse(r, err)
+	case *internal_errors.ErrorForbidden:
+		return buildForbiddenResponse(r, err)
+	case *internal_errors.ErrorTooManyRequests:
+		return buildTooManyRequestsResponse(r, err)
+	case *internal_errors.ErrorConflict:
+		return buildConflictResponse(r, err)
+	case *internal_errors.ErrorServiceUnavailable:
+		return buildServiceUnavailableResponse(r, err)
 	default:
 		return buildDefaultErrorResponse(r)
 	}
@@ -88,6 +137,50 @@ func buildNotFoundResponse(r *http.Request, err *internal_errors.ErrorNotFound)
 	}
 }
 
+func buildForbiddenResponse(r *http.Request, err *internal_errors.ErrorForbidden) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		Code:          err.StatusCode,
+		Status:        http.StatusText(err.StatusCode),
+		Title:         err.Title,
+		Detail:        err.Detail,
+		Instance:      r.RequestURI,
+		InvalidParams: []response.ErrorsParam{},
+	}
+}
+
+func buildTooManyRequestsResponse(r *http.Request, err *internal_errors.ErrorTooManyRequests) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		Code:          err.StatusCode,
+		Status:        http.StatusText(err.StatusCode),
+		Title:         err.Title,
+		Detail:        err.Detail,
+		Instance:      r.RequestURI,
+		InvalidParams: []response.ErrorsParam{},
+	}
+}
+
+func buildConflictResponse(r *http.Request, err *internal_errors.ErrorConflict) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		Code:          err.StatusCode,
+		Status:        http.StatusText(err.StatusCode),
+		Title:         err.Title,
+		Detail:        err.Detail,
+		Instance:      r.RequestURI,
+		InvalidParams: []response.ErrorsParam{},
+	}
+}
+
+func buildServiceUnavailableResponse(r *http.Request, err *internal_errors.ErrorServiceUnavailable) *response.ErrorResponse {
+	return &response.ErrorResponse{
+		Code:          err.StatusCode,
+		Status:        http.StatusText(err.StatusCode),
+		Title:         err.Title,
+		Detail:        err.Detail,
+		Instance:      r.RequestURI,
+		InvalidParams: []response.ErrorsParam{},
+	}
+}
+
 func buildDefaultErrorResponse(r *http.Request) *response.ErrorResponse {
 	return &response.ErrorResponse{
 		Code:          http.StatusInternalServerError,
@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+)
+
+// csvHeader names the "message" and "tag" columns the way the importer reads
+// them, so an exported room can be imported again.
+var csvHeader = []string{"room_id", "room_subject", "message_id", "message", "likes_count", "is_answered", "tag", "reactions"}
+
+type csvExporter struct {
+	writer *csv.Writer
+	room   *models.Room
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	return &csvExporter{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (e *csvExporter) Begin(room *models.Room) error {
+	e.room = room
+	return e.writer.Write(csvHeader)
+}
+
+func (e *csvExporter) WriteMessage(message *models.Message) error {
+	err := e.writer.Write([]string{
+		e.room.ID.String(),
+		e.room.Subject,
+		message.ID.String(),
+		message.Message,
+		strconv.FormatInt(message.LikesCount, 10),
+		strconv.FormatBool(message.Answered),
+		message.Tag,
+		formatReactions(message.Reactions),
+	})
+	if err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExporter) End() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// formatReactions writes the reactions of a message as kind=count pairs
+// sorted by kind, e.g. "like=3;love=1".
+func formatReactions(reactions map[string]int64) string {
+	kinds := make([]string, 0, len(reactions))
+	for kind := range reactions {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+
+	pairs := make([]string, len(kinds))
+	for i, kind := range kinds {
+		pairs[i] = kind + "=" + strconv.FormatInt(reactions[kind], 10)
+	}
+	return strings.Join(pairs, ";")
+}
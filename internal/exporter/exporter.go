@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"io"
+	"strings"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+)
+
+// RoomExporter writes a room and its messages to an output one message at a
+// time, so a room never has to be held in memory as a whole.
+type RoomExporter interface {
+	Begin(room *models.Room) error
+	WriteMessage(message *models.Message) error
+	End() error
+}
+
+func ParseFormat(rawFormat string) (Format, bool) {
+	switch Format(strings.ToLower(rawFormat)) {
+	case "", FormatJSON:
+		return FormatJSON, true
+	case FormatCSV:
+		return FormatCSV, true
+	case FormatMarkdown, "markdown":
+		return FormatMarkdown, true
+	default:
+		return "", false
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+func NewRoomExporter(format Format, w io.Writer) RoomExporter {
+	switch format {
+	case FormatCSV:
+		return newCSVExporter(w)
+	case FormatMarkdown:
+		return newMarkdownExporter(w)
+	default:
+		return newJSONExporter(w)
+	}
+}
@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+type exportedRoom struct {
+	ID                 string     `json:"id"`
+	Subject            string     `json:"subject"`
+	Description        string     `json:"description"`
+	StartsAt           *time.Time `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+	HostName           string     `json:"host_name"`
+	CoverImageURL      string     `json:"cover_image_url"`
+	Slug               string     `json:"slug"`
+	SpotlightMessageID *uuid.UUID `json:"spotlight_message_id"`
+}
+
+type exportedMessage struct {
+	ID         string           `json:"id"`
+	Message    string           `json:"message"`
+	LikesCount int64            `json:"likes_count"`
+	Answered   bool             `json:"is_answered"`
+	Pinned     bool             `json:"is_pinned"`
+	Tag        string           `json:"tag,omitempty"`
+	Reactions  map[string]int64 `json:"reactions,omitempty"`
+}
+
+type jsonExporter struct {
+	w        io.Writer
+	messages int
+}
+
+func newJSONExporter(w io.Writer) *jsonExporter {
+	return &jsonExporter{
+		w: w,
+	}
+}
+
+func (e *jsonExporter) Begin(room *models.Room) error {
+	data, err := json.Marshal(exportedRoom{
+		ID:                 room.ID.String(),
+		Subject:            room.Subject,
+		Description:        room.Description,
+		StartsAt:           room.StartsAt,
+		EndsAt:             room.EndsAt,
+		HostName:           room.HostName,
+		CoverImageURL:      room.CoverImageURL,
+		Slug:               room.Slug,
+		SpotlightMessageID: room.SpotlightMessageID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// leave the room object open so the messages can be streamed into it
+	data = append(data[:len(data)-1], `,"messages":[`...)
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) WriteMessage(message *models.Message) error {
+	data, err := json.Marshal(exportedMessage{
+		ID:         message.ID.String(),
+		Message:    message.Message,
+		LikesCount: message.LikesCount,
+		Answered:   message.Answered,
+		Pinned:     message.Pinned,
+		Tag:        message.Tag,
+		Reactions:  message.Reactions,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.messages > 0 {
+		data = append([]byte(","), data...)
+	}
+	e.messages++
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) End() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
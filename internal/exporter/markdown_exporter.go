@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+type markdownExporter struct {
+	w        io.Writer
+	messages int
+}
+
+func newMarkdownExporter(w io.Writer) *markdownExporter {
+	return &markdownExporter{
+		w: w,
+	}
+}
+
+func (e *markdownExporter) Begin(room *models.Room) error {
+	if _, err := fmt.Fprintf(e.w, "# %s\n\n", room.Subject); err != nil {
+		return err
+	}
+
+	if room.Description != "" {
+		if _, err := fmt.Fprintf(e.w, "%s\n\n", room.Description); err != nil {
+			return err
+		}
+	}
+
+	details := []string{fmt.Sprintf("- Room ID: `%s`", room.ID)}
+	if room.HostName != "" {
+		details = append(details, "- Host: "+room.HostName)
+	}
+	if room.StartsAt != nil {
+		details = append(details, "- Starts at: "+room.StartsAt.Format(time.RFC3339))
+	}
+	if room.EndsAt != nil {
+		details = append(details, "- Ends at: "+room.EndsAt.Format(time.RFC3339))
+	}
+
+	_, err := fmt.Fprintf(e.w, "%s\n\n| # | Question | Likes | Answered |\n|---|---|---|---|\n", strings.Join(details, "\n"))
+	return err
+}
+
+func (e *markdownExporter) WriteMessage(message *models.Message) error {
+	e.messages++
+
+	answered := "no"
+	if message.Answered {
+		answered = "yes"
+	}
+
+	_, err := fmt.Fprintf(e.w, "| %d | %s | %d | %s |\n",
+		e.messages, markdownEscaper.Replace(message.Message), message.LikesCount, answered)
+	return err
+}
+
+func (e *markdownExporter) End() error {
+	_, err := fmt.Fprintf(e.w, "\nTotal questions: %d\n", e.messages)
+	return err
+}
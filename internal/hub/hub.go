@@ -0,0 +1,103 @@
+// Package hub keeps the websocket connections subscribed to each room and
+// sends them the events of their room.
+package hub
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/socket"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Hub writes to the connections behind a single lock, gorilla connections
+// supporting one concurrent writer only.
+type Hub struct {
+	subscribers map[uuid.UUID]map[*websocket.Conn]context.CancelFunc
+	mutex       *sync.Mutex
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[uuid.UUID]map[*websocket.Conn]context.CancelFunc),
+		mutex:       &sync.Mutex{},
+	}
+}
+
+// Subscribe adds a connection to a room. cancel is called when the
+// connection should be closed, once writing to it failed or the room is gone.
+func (h *Hub) Subscribe(roomId uuid.UUID, conn *websocket.Conn, cancel context.CancelFunc) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.subscribers[roomId]; !ok {
+		h.subscribers[roomId] = make(map[*websocket.Conn]context.CancelFunc)
+	}
+	h.subscribers[roomId][conn] = cancel
+}
+
+func (h *Hub) Unsubscribe(roomId uuid.UUID, conn *websocket.Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.subscribers[roomId], conn)
+	if len(h.subscribers[roomId]) == 0 {
+		delete(h.subscribers, roomId)
+	}
+}
+
+// Send writes a message to a single connection.
+func (h *Hub) Send(conn *websocket.Conn, message socket.Message) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return conn.WriteJSON(message)
+}
+
+// Broadcast writes a message to every connection of a room.
+func (h *Hub) Broadcast(roomId uuid.UUID, message socket.Message) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for conn, cancel := range h.subscribers[roomId] {
+		if err := conn.WriteJSON(message); err != nil {
+			slog.Error("failed to send message to client", "error", err)
+			cancel()
+		}
+	}
+}
+
+// Disconnect ends every subscription of a room. The subscribers close their
+// connections and unsubscribe themselves.
+func (h *Hub) Disconnect(roomId uuid.UUID) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, cancel := range h.subscribers[roomId] {
+		cancel()
+	}
+}
+
+// HandleEvent broadcasts an event to the subscribers of its room. The
+// subscribers of a deleted room are disconnected once they got it.
+func (h *Hub) HandleEvent(ctx context.Context, event events.Event) error {
+	kind, value := socketMessage(event)
+	if kind == "" {
+		return nil
+	}
+
+	roomId := event.Room()
+	h.Broadcast(roomId, socket.Message{
+		Kind:   kind,
+		Value:  value,
+		RoomID: roomId.String(),
+	})
+
+	if event.Kind() == events.KindRoomDeleted {
+		h.Disconnect(roomId)
+	}
+	return nil
+}
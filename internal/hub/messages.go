@@ -0,0 +1,128 @@
+package hub
+
+import (
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/socket"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/google/uuid"
+)
+
+// socketMessage returns the kind and the value of the message the clients
+// get for an event, an empty kind for the events they don't get.
+func socketMessage(event events.Event) (string, any) {
+	switch e := event.(type) {
+	case events.MessageCreated:
+		return socket.MessageKindMessageCreated, socket.MessageMessageCreated{
+			ID:      e.MessageID.String(),
+			Message: e.Message,
+			Tag:     e.Tag,
+		}
+	case events.ReactionChanged:
+		if e.Added {
+			return socket.MessageKindMessageRactionIncreased, socket.MessageMessageReactionIncreased{
+				ID:        e.MessageID.String(),
+				Kind:      e.Reaction,
+				Count:     e.Count,
+				Reactions: e.Reactions,
+			}
+		}
+		return socket.MessageKindMessageRactionDecreased, socket.MessageMessageReactionDecreased{
+			ID:        e.MessageID.String(),
+			Kind:      e.Reaction,
+			Count:     e.Count,
+			Reactions: e.Reactions,
+		}
+	case events.ScoreChanged:
+		return socket.MessageKindMessageScoreChanged, socket.MessageMessageScoreChanged{
+			ID:             e.MessageID.String(),
+			LikesCount:     e.LikesCount,
+			DownvotesCount: e.DownvotesCount,
+			Score:          e.Score,
+		}
+	case events.MessageAnswered:
+		return socket.MessageKindMessageAnswered, socket.MessageMessageAnswered{
+			ID: e.MessageID.String(),
+		}
+	case events.MessagePinned:
+		return socket.MessageKindMessagePinned, socket.MessageMessagePinned{
+			ID:     e.MessageID.String(),
+			Pinned: e.Pinned,
+		}
+	case events.MessageHidden:
+		return socket.MessageKindMessageHidden, socket.MessageMessageHidden{
+			ID:     e.MessageID.String(),
+			Hidden: e.Hidden,
+		}
+	case events.MessageSpotlighted:
+		// an empty id tells the clients the spotlight was cleared
+		return socket.MessageKindMessageSpotlighted, socket.MessageMessageSpotlighted{
+			ID: uuidString(e.MessageID),
+		}
+	case events.PollCreated:
+		return socket.MessageKindPollCreated, socket.MessagePollCreated{
+			ID:       e.PollID.String(),
+			Question: e.Question,
+			Options:  pollOptions(e.Options),
+		}
+	case events.PollVoted:
+		return socket.MessageKindPollVoted, socket.MessagePollVoted{
+			ID:         e.PollID.String(),
+			Options:    pollOptions(e.Options),
+			TotalVotes: e.TotalVotes,
+		}
+	case events.PollClosed:
+		return socket.MessageKindPollClosed, socket.MessagePollClosed{
+			ID:         e.PollID.String(),
+			Options:    pollOptions(e.Options),
+			TotalVotes: e.TotalVotes,
+		}
+	case events.RoomUpdated:
+		return socket.MessageKindRoomUpdated, socket.MessageRoomUpdated{
+			ID:                 e.RoomID.String(),
+			Subject:            e.Subject,
+			Description:        e.Description,
+			StartsAt:           e.StartsAt,
+			EndsAt:             e.EndsAt,
+			HostName:           e.HostName,
+			CoverImageURL:      e.CoverImageURL,
+			Slug:               e.Slug,
+			JoinCode:           e.JoinCode,
+			DownvotesEnabled:   e.DownvotesEnabled,
+			SpotlightMessageID: uuidString(e.SpotlightMessageID),
+			Tags:               e.Tags,
+		}
+	case events.RoomClosed:
+		return socket.MessageKindRoomClosed, socket.MessageRoomClosed{
+			ID:     e.RoomID.String(),
+			Reason: string(e.Reason),
+		}
+	case events.RoomReopened:
+		return socket.MessageKindRoomReopened, socket.MessageRoomReopened{
+			ID: e.RoomID.String(),
+		}
+	case events.RoomDeleted:
+		return socket.MessageKindRoomDeleted, socket.MessageRoomDeleted{
+			ID: e.RoomID.String(),
+		}
+	default:
+		return "", nil
+	}
+}
+
+func pollOptions(options []events.PollOption) []socket.MessagePollOption {
+	messageOptions := make([]socket.MessagePollOption, len(options))
+	for i, option := range options {
+		messageOptions[i] = socket.MessagePollOption{
+			ID:         option.ID.String(),
+			Label:      option.Label,
+			VotesCount: option.VotesCount,
+		}
+	}
+	return messageOptions
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+var ErrInvalidFile = errors.New("invalid import file")
+
+func ParseFormat(rawFormat string) (Format, bool) {
+	switch Format(strings.ToLower(rawFormat)) {
+	case "", FormatJSON:
+		return FormatJSON, true
+	case FormatCSV:
+		return FormatCSV, true
+	default:
+		return "", false
+	}
+}
+
+// ReadMessages reads the questions of an import file. JSON files hold an array
+// of message requests, CSV files need a header row with a "message" column and
+// may have a "tag" column.
+// Rows are returned in file order and are not validated.
+func ReadMessages(format Format, r io.Reader) ([]request.MessageRequest, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return readJSON(r)
+	}
+}
+
+func readJSON(r io.Reader) ([]request.MessageRequest, error) {
+	var messages []request.MessageRequest
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return nil, errors.Join(ErrInvalidFile, err)
+	}
+	return messages, nil
+}
+
+func readCSV(r io.Reader) ([]request.MessageRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(ErrInvalidFile, err)
+	}
+
+	column, tagColumn := -1, -1
+	for i, name := range header {
+		switch {
+		case strings.EqualFold(strings.TrimSpace(name), "message") && column < 0:
+			column = i
+		case strings.EqualFold(strings.TrimSpace(name), "tag") && tagColumn < 0:
+			tagColumn = i
+		}
+	}
+	if column < 0 {
+		return nil, errors.Join(ErrInvalidFile, errors.New(`missing "message" column`))
+	}
+
+	var messages []request.MessageRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, errors.Join(ErrInvalidFile, err)
+		}
+
+		var message, tag string
+		if column < len(record) {
+			message = record[column]
+		}
+		if tagColumn >= 0 && tagColumn < len(record) {
+			tag = strings.TrimSpace(record[tagColumn])
+		}
+		messages = append(messages, request.MessageRequest{Message: message, Tag: tag})
+	}
+}
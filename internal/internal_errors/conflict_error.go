@@ -0,0 +1,56 @@
+package internal_errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+)
+
+type ErrorConflict struct {
+	StatusCode int
+	StatusText string
+	Title      string
+	Detail     string
+	tag        string
+	message    string
+}
+
+func NewErrConflict(ctx context.Context, detailTag string) *ErrorConflict {
+	return newErrConflict(ctx, detailTag)
+}
+
+func newErrConflict(ctx context.Context, detailTag string) *ErrorConflict {
+	statusCode := http.StatusConflict
+	statusText := strings.ToUpper(http.StatusText(statusCode))
+	statusText = strings.Replace(statusText, " ", "_", -1)
+	title, _ := locale.GetMessage(ctx, statusText)
+	detail, _ := locale.GetMessage(ctx, detailTag)
+	message, _ := locale.GetMessage(context.WithValue(ctx, middlewares.LangKey, "en"), detailTag)
+
+	return &ErrorConflict{
+		StatusCode: statusCode,
+		StatusText: statusText,
+		Title:      title,
+		Detail:     detail,
+		tag:        detailTag,
+		message:    message,
+	}
+}
+
+func (ec *ErrorConflict) Error() string {
+	return fmt.Sprintf("conflict error: %s", ec.message)
+}
+
+var ErrConflict = newErrConflict(context.Background(), "")
+
+// IsConflict tells whether err is a conflict of detailTag, e.g. a write that
+// ran into a unique index.
+func IsConflict(err error, detailTag string) bool {
+	var errConflict *ErrorConflict
+	return errors.As(err, &errConflict) && errConflict.tag == detailTag
+}
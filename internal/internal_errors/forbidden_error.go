@@ -0,0 +1,46 @@
+package internal_errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+)
+
+type ErrorForbidden struct {
+	StatusCode int
+	StatusText string
+	Title      string
+	Detail     string
+	message    string
+}
+
+func NewErrForbidden(ctx context.Context, detailTag string) *ErrorForbidden {
+	return newErrForbidden(ctx, detailTag)
+}
+
+func newErrForbidden(ctx context.Context, detailTag string) *ErrorForbidden {
+	statusCode := http.StatusForbidden
+	statusText := strings.ToUpper(http.StatusText(statusCode))
+	statusText = strings.Replace(statusText, " ", "_", -1)
+	title, _ := locale.GetMessage(ctx, statusText)
+	detail, _ := locale.GetMessage(ctx, detailTag)
+	message, _ := locale.GetMessage(context.WithValue(ctx, middlewares.LangKey, "en"), detailTag)
+
+	return &ErrorForbidden{
+		StatusCode: statusCode,
+		StatusText: statusText,
+		Title:      title,
+		Detail:     detail,
+		message:    message,
+	}
+}
+
+func (ef *ErrorForbidden) Error() string {
+	return fmt.Sprintf("forbidden error: %s", ef.message)
+}
+
+var ErrForbidden = newErrForbidden(context.Background(), "")
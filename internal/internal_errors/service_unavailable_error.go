@@ -0,0 +1,46 @@
+package internal_errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+)
+
+type ErrorServiceUnavailable struct {
+	StatusCode int
+	StatusText string
+	Title      string
+	Detail     string
+	message    string
+}
+
+func NewErrServiceUnavailable(ctx context.Context, detailTag string) *ErrorServiceUnavailable {
+	return newErrServiceUnavailable(ctx, detailTag)
+}
+
+func newErrServiceUnavailable(ctx context.Context, detailTag string) *ErrorServiceUnavailable {
+	statusCode := http.StatusServiceUnavailable
+	statusText := strings.ToUpper(http.StatusText(statusCode))
+	statusText = strings.Replace(statusText, " ", "_", -1)
+	title, _ := locale.GetMessage(ctx, statusText)
+	detail, _ := locale.GetMessage(ctx, detailTag)
+	message, _ := locale.GetMessage(context.WithValue(ctx, middlewares.LangKey, "en"), detailTag)
+
+	return &ErrorServiceUnavailable{
+		StatusCode: statusCode,
+		StatusText: statusText,
+		Title:      title,
+		Detail:     detail,
+		message:    message,
+	}
+}
+
+func (es *ErrorServiceUnavailable) Error() string {
+	return fmt.Sprintf("service unavailable error: %s", es.message)
+}
+
+var ErrServiceUnavailable = newErrServiceUnavailable(context.Background(), "")
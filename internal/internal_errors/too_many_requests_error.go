@@ -0,0 +1,58 @@
+package internal_errors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+)
+
+type ErrorTooManyRequests struct {
+	StatusCode int
+	StatusText string
+	Title      string
+	Detail     string
+	RetryAfter time.Duration
+	message    string
+}
+
+func NewErrTooManyRequests(ctx context.Context, retryAfter time.Duration) *ErrorTooManyRequests {
+	return newErrTooManyRequests(ctx, retryAfter)
+}
+
+func newErrTooManyRequests(ctx context.Context, retryAfter time.Duration) *ErrorTooManyRequests {
+	statusCode := http.StatusTooManyRequests
+	statusText := strings.ToUpper(http.StatusText(statusCode))
+	statusText = strings.Replace(statusText, " ", "_", -1)
+	seconds := strconv.Itoa(RetryAfterSeconds(retryAfter))
+	title, _ := locale.GetMessage(ctx, statusText)
+	detail, _ := locale.GetMessage(ctx, "RATE_LIMITED", seconds)
+	message, _ := locale.GetMessage(context.WithValue(ctx, middlewares.LangKey, "en"), "RATE_LIMITED", seconds)
+
+	return &ErrorTooManyRequests{
+		StatusCode: statusCode,
+		StatusText: statusText,
+		Title:      title,
+		Detail:     detail,
+		RetryAfter: retryAfter,
+		message:    message,
+	}
+}
+
+func (et *ErrorTooManyRequests) Error() string {
+	return fmt.Sprintf("too many requests error: %s", et.message)
+}
+
+// RetryAfterSeconds rounds a wait up to whole seconds, the unit of the
+// Retry-After header.
+func RetryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+}
+
+var ErrTooManyRequests = newErrTooManyRequests(context.Background(), 0)
@@ -0,0 +1,28 @@
+package jobs
+
+import "time"
+
+type RetentionMode string
+
+const (
+	RetentionModePurge     RetentionMode = "purge"
+	RetentionModeAnonymize RetentionMode = "anonymize"
+)
+
+// Policy decides what the scheduler does with rooms over time. A zero
+// duration turns the matching job off.
+type Policy struct {
+	Interval          time.Duration
+	CloseEndedRooms   bool
+	InactivityTimeout time.Duration
+	RetentionPeriod   time.Duration
+	RetentionMode     RetentionMode
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Interval:        5 * time.Minute,
+		CloseEndedRooms: true,
+		RetentionMode:   RetentionModeAnonymize,
+	}
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/services"
+)
+
+// Scheduler runs the room expiry and retention jobs of a Policy every
+// Policy.Interval, starting right away.
+type Scheduler struct {
+	service *services.RoomsService
+	policy  Policy
+	stop    chan struct{}
+	done    chan struct{}
+	cancel  context.CancelFunc
+}
+
+func NewScheduler(service *services.RoomsService, policy Policy) *Scheduler {
+	return &Scheduler{
+		service: service,
+		policy:  policy,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			s.run(ctx)
+
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for a running job to finish. Once ctx is done the job is
+// cancelled instead.
+func (s *Scheduler) Stop(ctx context.Context) {
+	close(s.stop)
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	now := time.Now()
+
+	if s.policy.CloseEndedRooms {
+		closed, err := s.service.CloseEndedRooms(ctx, now)
+		if err != nil {
+			slog.Error("failed to close ended rooms", "error", err)
+		} else if closed > 0 {
+			slog.Info("closed ended rooms", "count", closed)
+		}
+	}
+
+	if s.policy.InactivityTimeout > 0 {
+		closed, err := s.service.CloseInactiveRooms(ctx, now.Add(-s.policy.InactivityTimeout))
+		if err != nil {
+			slog.Error("failed to close inactive rooms", "error", err)
+		} else if closed > 0 {
+			slog.Info("closed inactive rooms", "count", closed)
+		}
+	}
+
+	if s.policy.RetentionPeriod > 0 {
+		closedBefore := now.Add(-s.policy.RetentionPeriod)
+		switch s.policy.RetentionMode {
+		case RetentionModePurge:
+			purged, err := s.service.PurgeArchivedRooms(ctx, closedBefore)
+			if err != nil {
+				slog.Error("failed to purge archived rooms", "error", err)
+			} else if purged > 0 {
+				slog.Info("purged archived rooms", "count", purged)
+			}
+		case RetentionModeAnonymize:
+			anonymized, err := s.service.AnonymizeArchivedRooms(ctx, closedBefore)
+			if err != nil {
+				slog.Error("failed to anonymize archived rooms", "error", err)
+			} else if anonymized > 0 {
+				slog.Info("anonymized archived room messages", "count", anonymized)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package joincode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Alphabet leaves out characters that are easily confused when read from a
+// slide or typed on a phone: 0/O, 1/I/L.
+const Alphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const Length = 6
+
+// Generate returns a random code in its stored form, e.g. "K7P2QX".
+func Generate() (string, error) {
+	max := big.NewInt(int64(len(Alphabet)))
+	code := make([]byte, Length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = Alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Normalize turns user input such as "k7p-2qx" into the stored form. The
+// second result is false when the input can't be a join code.
+func Normalize(code string) (string, bool) {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+
+	if len(code) != Length {
+		return "", false
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(Alphabet, r) {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// Format returns the code in its display form, e.g. "K7P-2QX".
+func Format(code string) string {
+	if len(code) != Length {
+		return code
+	}
+	return code[:Length/2] + "-" + code[Length/2:]
+}
@@ -0,0 +1,59 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+)
+
+type APIKeyMapper struct{}
+
+func (mapper *APIKeyMapper) ToModel(apiKey pgstore.ApiKey) *models.APIKey {
+	return &models.APIKey{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		KeyHash:   apiKey.KeyHash,
+		CreatedAt: apiKey.CreatedAt.Time,
+	}
+}
+
+func (mapper *APIKeyMapper) ToInsertParams(apiKey *models.APIKey) pgstore.InsertApiKeyParams {
+	return pgstore.InsertApiKeyParams{
+		Name:    apiKey.Name,
+		Prefix:  apiKey.Prefix,
+		KeyHash: apiKey.KeyHash,
+	}
+}
+
+func (mapper *APIKeyMapper) SQLiteToModel(apiKey sqlitestore.ApiKey) *models.APIKey {
+	return &models.APIKey{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		KeyHash:   apiKey.KeyHash,
+		CreatedAt: time.UnixMicro(apiKey.CreatedAt),
+	}
+}
+
+func (mapper *APIKeyMapper) ToSQLiteInsertParams(apiKey *models.APIKey) sqlitestore.InsertApiKeyParams {
+	return sqlitestore.InsertApiKeyParams{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		KeyHash:   apiKey.KeyHash,
+		CreatedAt: apiKey.CreatedAt.UnixMicro(),
+	}
+}
+
+func (mapper *APIKeyMapper) ToResponse(apiKey *models.APIKey) *response.APIKeyResponse {
+	return &response.APIKeyResponse{
+		ID:        apiKey.ID.String(),
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		CreatedAt: apiKey.CreatedAt,
+	}
+}
@@ -3,6 +3,7 @@ package mappers
 import (
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
 	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
 	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
 )
 
@@ -10,20 +11,33 @@ type MessageMapper struct{}
 
 func (mapper *MessageMapper) ToModel(message pgstore.Message) *models.Message {
 	return &models.Message{
-		ID:         message.ID,
-		RoomID:     message.RoomID,
-		Message:    message.Message,
-		LikesCount: message.LikesCount,
-		Answered:   message.Answered,
+		ID:              message.ID,
+		RoomID:          message.RoomID,
+		Message:         message.Message,
+		LikesCount:      message.LikesCount,
+		DownvotesCount:  message.DownvotesCount,
+		Answered:        message.Answered,
+		Pinned:          message.Pinned,
+		Tag:             message.Tag,
+		ModerationFlags: message.ModerationFlags,
+		Hidden:          message.Hidden,
+		CreatedAt:       message.CreatedAt.Time,
 	}
 }
 
 func (mapper *MessageMapper) ToResponse(message *models.Message) *response.MessageResponse {
 	return &response.MessageResponse{
-		ID:         message.ID.String(),
-		RoomID:     message.RoomID.String(),
-		Message:    message.Message,
-		LikesCount: message.LikesCount,
-		Answered:   message.Answered,
+		ID:              message.ID.String(),
+		RoomID:          message.RoomID.String(),
+		Message:         message.Message,
+		LikesCount:      message.LikesCount,
+		DownvotesCount:  message.DownvotesCount,
+		Score:           ranking.NetScore(message.LikesCount, message.DownvotesCount),
+		Answered:        message.Answered,
+		Pinned:          message.Pinned,
+		Tag:             message.Tag,
+		ModerationFlags: message.ModerationFlags,
+		Hidden:          message.Hidden,
+		Reactions:       message.Reactions,
 	}
 }
@@ -0,0 +1,35 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+)
+
+type OutboxMapper struct{}
+
+func (mapper *OutboxMapper) ToModel(event pgstore.OutboxEvent) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:        event.ID,
+		RoomID:    event.RoomID,
+		Kind:      event.Kind,
+		Payload:   event.Payload,
+		Attempts:  event.Attempts,
+		Handled:   event.Handled,
+		CreatedAt: event.CreatedAt.Time,
+	}
+}
+
+func (mapper *OutboxMapper) SQLiteToModel(event sqlitestore.OutboxEvent) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:        event.ID,
+		RoomID:    event.RoomID,
+		Kind:      event.Kind,
+		Payload:   []byte(event.Payload),
+		Attempts:  int32(event.Attempts),
+		Handled:   int32(event.Handled),
+		CreatedAt: time.UnixMicro(event.CreatedAt),
+	}
+}
@@ -0,0 +1,54 @@
+package mappers
+
+import (
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+)
+
+type PollMapper struct{}
+
+func (mapper *PollMapper) ToModel(poll pgstore.Poll, options []pgstore.PollOption) *models.Poll {
+	modelOptions := make([]models.PollOption, len(options))
+	for i, option := range options {
+		modelOptions[i] = models.PollOption{
+			ID:         option.ID,
+			PollID:     option.PollID,
+			Position:   option.Position,
+			Label:      option.Label,
+			VotesCount: option.VotesCount,
+		}
+	}
+
+	return &models.Poll{
+		ID:        poll.ID,
+		RoomID:    poll.RoomID,
+		Question:  poll.Question,
+		CreatedAt: poll.CreatedAt.Time,
+		ClosedAt:  toTime(poll.ClosedAt),
+		Options:   modelOptions,
+	}
+}
+
+func (mapper *PollMapper) ToResponse(poll *models.Poll) *response.PollResponse {
+	var totalVotes int64
+	options := make([]response.PollOptionResponse, len(poll.Options))
+	for i, option := range poll.Options {
+		options[i] = response.PollOptionResponse{
+			ID:         option.ID.String(),
+			Label:      option.Label,
+			VotesCount: option.VotesCount,
+		}
+		totalVotes += option.VotesCount
+	}
+
+	return &response.PollResponse{
+		ID:         poll.ID.String(),
+		RoomID:     poll.RoomID.String(),
+		Question:   poll.Question,
+		Options:    options,
+		TotalVotes: totalVotes,
+		CreatedAt:  poll.CreatedAt,
+		ClosedAt:   poll.ClosedAt,
+	}
+}
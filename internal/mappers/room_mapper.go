@@ -1,23 +1,166 @@
 package mappers
 
 import (
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/joincode"
 	"github.com/JulioZittei/wsrs-ama-go/internal/models"
 	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type RoomMapper struct{}
 
 func (mapper *RoomMapper) ToModel(room pgstore.Room) *models.Room {
 	return &models.Room{
-		ID:      room.ID,
-		Subject: room.Subject,
+		ID:                 room.ID,
+		Subject:            room.Subject,
+		Description:        room.Description,
+		StartsAt:           toTime(room.StartsAt),
+		EndsAt:             toTime(room.EndsAt),
+		HostName:           room.HostName,
+		CoverImageURL:      room.CoverImageUrl,
+		Slug:               room.Slug,
+		JoinCode:           room.JoinCode,
+		PasscodeHash:       room.PasscodeHash,
+		Private:            room.Private,
+		CreatedAt:          room.CreatedAt.Time,
+		ClosedAt:           toTime(room.ClosedAt),
+		ReactionKinds:      room.ReactionKinds,
+		DownvotesEnabled:   room.DownvotesEnabled,
+		SpotlightMessageID: toUUID(room.SpotlightMessageID),
+		Tags:               room.Tags,
+	}
+}
+
+func (mapper *RoomMapper) RequestToModel(room *request.RoomRequest) *models.Room {
+	return &models.Room{
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         room.StartsAt,
+		EndsAt:           room.EndsAt,
+		HostName:         room.HostName,
+		CoverImageURL:    room.CoverImageURL,
+		Slug:             room.Slug,
+		Private:          room.Private,
+		ReactionKinds:    room.ReactionKinds,
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             room.Tags,
+	}
+}
+
+func (mapper *RoomMapper) ToRequest(room *models.Room) *request.RoomRequest {
+	return &request.RoomRequest{
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         room.StartsAt,
+		EndsAt:           room.EndsAt,
+		HostName:         room.HostName,
+		CoverImageURL:    room.CoverImageURL,
+		Slug:             room.Slug,
+		Private:          room.Private,
+		ReactionKinds:    room.ReactionKinds,
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             room.Tags,
 	}
 }
 
 func (mapper *RoomMapper) ToResponse(room *models.Room) *response.RoomResponse {
 	return &response.RoomResponse{
-		ID:      room.ID.String(),
-		Subject: room.Subject,
+		ID:                 room.ID.String(),
+		Subject:            room.Subject,
+		Description:        room.Description,
+		StartsAt:           room.StartsAt,
+		EndsAt:             room.EndsAt,
+		HostName:           room.HostName,
+		CoverImageURL:      room.CoverImageURL,
+		Slug:               room.Slug,
+		JoinCode:           joincode.Format(room.JoinCode),
+		HasPasscode:        room.PasscodeHash != "",
+		Private:            room.Private,
+		ClosedAt:           room.ClosedAt,
+		ReactionKinds:      room.ReactionKinds,
+		DownvotesEnabled:   room.DownvotesEnabled,
+		SpotlightMessageID: uuidString(room.SpotlightMessageID),
+		Tags:               tagResponses(room),
+	}
+}
+
+func (mapper *RoomMapper) ToInsertParams(room *models.Room) pgstore.InsertRoomParams {
+	return pgstore.InsertRoomParams{
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         toTimestamptz(room.StartsAt),
+		EndsAt:           toTimestamptz(room.EndsAt),
+		HostName:         room.HostName,
+		CoverImageUrl:    room.CoverImageURL,
+		Slug:             room.Slug,
+		JoinCode:         room.JoinCode,
+		PasscodeHash:     room.PasscodeHash,
+		Private:          room.Private,
+		ReactionKinds:    room.ReactionKinds,
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             room.Tags,
+	}
+}
+
+func (mapper *RoomMapper) ToUpdateParams(room *models.Room) pgstore.UpdateRoomParams {
+	return pgstore.UpdateRoomParams{
+		ID:               room.ID,
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         toTimestamptz(room.StartsAt),
+		EndsAt:           toTimestamptz(room.EndsAt),
+		HostName:         room.HostName,
+		CoverImageUrl:    room.CoverImageURL,
+		Slug:             room.Slug,
+		PasscodeHash:     room.PasscodeHash,
+		Private:          room.Private,
+		ReactionKinds:    room.ReactionKinds,
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             room.Tags,
+	}
+}
+
+func toTime(timestamp pgtype.Timestamptz) *time.Time {
+	if !timestamp.Valid {
+		return nil
+	}
+	return &timestamp.Time
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+func toUUID(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes)
+	return &value
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func tagResponses(room *models.Room) []response.RoomTagResponse {
+	tags := make([]response.RoomTagResponse, len(room.Tags))
+	for i, tag := range room.Tags {
+		tags[i] = response.RoomTagResponse{
+			Name:          tag,
+			MessagesCount: room.TagCounts[tag],
+		}
 	}
+	return tags
 }
@@ -0,0 +1,154 @@
+package mappers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+// SQLite stores timestamps as unix microseconds, the precision Postgres
+// keeps, and lists as JSON arrays.
+
+func (mapper *RoomMapper) SQLiteToModel(room sqlitestore.Room) *models.Room {
+	return &models.Room{
+		ID:                 room.ID,
+		Subject:            room.Subject,
+		Description:        room.Description,
+		StartsAt:           fromMicros(room.StartsAt),
+		EndsAt:             fromMicros(room.EndsAt),
+		HostName:           room.HostName,
+		CoverImageURL:      room.CoverImageUrl,
+		Slug:               room.Slug,
+		JoinCode:           room.JoinCode,
+		PasscodeHash:       room.PasscodeHash,
+		Private:            room.Private,
+		CreatedAt:          time.UnixMicro(room.CreatedAt),
+		ClosedAt:           fromMicros(room.ClosedAt),
+		ReactionKinds:      decodeList(room.ReactionKinds),
+		DownvotesEnabled:   room.DownvotesEnabled,
+		SpotlightMessageID: fromNullUUID(room.SpotlightMessageID),
+		Tags:               decodeList(room.Tags),
+	}
+}
+
+func (mapper *RoomMapper) ToSQLiteInsertParams(room *models.Room) sqlitestore.InsertRoomParams {
+	return sqlitestore.InsertRoomParams{
+		ID:               room.ID,
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         toMicros(room.StartsAt),
+		EndsAt:           toMicros(room.EndsAt),
+		HostName:         room.HostName,
+		CoverImageUrl:    room.CoverImageURL,
+		Slug:             room.Slug,
+		JoinCode:         room.JoinCode,
+		PasscodeHash:     room.PasscodeHash,
+		Private:          room.Private,
+		CreatedAt:        room.CreatedAt.UnixMicro(),
+		ReactionKinds:    encodeList(room.ReactionKinds),
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             encodeList(room.Tags),
+	}
+}
+
+func (mapper *RoomMapper) ToSQLiteUpdateParams(room *models.Room) sqlitestore.UpdateRoomParams {
+	return sqlitestore.UpdateRoomParams{
+		ID:               room.ID,
+		Subject:          room.Subject,
+		Description:      room.Description,
+		StartsAt:         toMicros(room.StartsAt),
+		EndsAt:           toMicros(room.EndsAt),
+		HostName:         room.HostName,
+		CoverImageUrl:    room.CoverImageURL,
+		Slug:             room.Slug,
+		PasscodeHash:     room.PasscodeHash,
+		Private:          room.Private,
+		ReactionKinds:    encodeList(room.ReactionKinds),
+		DownvotesEnabled: room.DownvotesEnabled,
+		Tags:             encodeList(room.Tags),
+	}
+}
+
+func (mapper *MessageMapper) SQLiteToModel(message sqlitestore.Message) *models.Message {
+	return &models.Message{
+		ID:              message.ID,
+		RoomID:          message.RoomID,
+		Message:         message.Message,
+		LikesCount:      message.LikesCount,
+		DownvotesCount:  message.DownvotesCount,
+		Answered:        message.Answered,
+		Pinned:          message.Pinned,
+		Tag:             message.Tag,
+		ModerationFlags: decodeList(message.ModerationFlags),
+		Hidden:          message.Hidden,
+		CreatedAt:       time.UnixMicro(message.CreatedAt),
+	}
+}
+
+// ToSQLiteFlags encodes the moderation flags of a message for the
+// moderation_flags column.
+func (mapper *MessageMapper) ToSQLiteFlags(flags []string) string {
+	return encodeList(flags)
+}
+
+func (mapper *PollMapper) SQLiteToModel(poll sqlitestore.Poll, options []sqlitestore.PollOption) *models.Poll {
+	modelOptions := make([]models.PollOption, len(options))
+	for i, option := range options {
+		modelOptions[i] = models.PollOption{
+			ID:         option.ID,
+			PollID:     option.PollID,
+			Position:   int32(option.Position),
+			Label:      option.Label,
+			VotesCount: option.VotesCount,
+		}
+	}
+
+	return &models.Poll{
+		ID:        poll.ID,
+		RoomID:    poll.RoomID,
+		Question:  poll.Question,
+		CreatedAt: time.UnixMicro(poll.CreatedAt),
+		ClosedAt:  fromMicros(poll.ClosedAt),
+		Options:   modelOptions,
+	}
+}
+
+func fromMicros(micros sql.NullInt64) *time.Time {
+	if !micros.Valid {
+		return nil
+	}
+	t := time.UnixMicro(micros.Int64)
+	return &t
+}
+
+func toMicros(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMicro(), Valid: true}
+}
+
+func fromNullUUID(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func decodeList(encoded string) []string {
+	list := []string{}
+	_ = json.Unmarshal([]byte(encoded), &list)
+	return list
+}
+
+func encodeList(list []string) string {
+	if list == nil {
+		return "[]"
+	}
+	encoded, _ := json.Marshal(list)
+	return string(encoded)
+}
@@ -0,0 +1,151 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhookMapper struct{}
+
+func (mapper *WebhookMapper) ToModel(webhook pgstore.Webhook) *models.Webhook {
+	return &models.Webhook{
+		ID:         webhook.ID,
+		RoomID:     toUUID(webhook.RoomID),
+		URL:        webhook.Url,
+		EventKinds: webhook.EventKinds,
+		Secret:     webhook.Secret,
+		CreatedAt:  webhook.CreatedAt.Time,
+	}
+}
+
+func (mapper *WebhookMapper) ToInsertParams(webhook *models.Webhook) pgstore.InsertWebhookParams {
+	return pgstore.InsertWebhookParams{
+		RoomID:     toPgUUID(webhook.RoomID),
+		Url:        webhook.URL,
+		EventKinds: webhook.EventKinds,
+		Secret:     webhook.Secret,
+	}
+}
+
+func (mapper *WebhookMapper) DeliveryToModel(delivery pgstore.WebhookDelivery) models.WebhookDelivery {
+	var responseStatus *int32
+	if delivery.ResponseStatus.Valid {
+		responseStatus = &delivery.ResponseStatus.Int32
+	}
+
+	return models.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventKind:      delivery.EventKind,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: responseStatus,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt.Time,
+		CreatedAt:      delivery.CreatedAt.Time,
+		CompletedAt:    toTime(delivery.CompletedAt),
+	}
+}
+
+func (mapper *WebhookMapper) SQLiteToModel(webhook sqlitestore.Webhook) *models.Webhook {
+	return &models.Webhook{
+		ID:         webhook.ID,
+		RoomID:     fromNullUUID(webhook.RoomID),
+		URL:        webhook.Url,
+		EventKinds: decodeList(webhook.EventKinds),
+		Secret:     webhook.Secret,
+		CreatedAt:  time.UnixMicro(webhook.CreatedAt),
+	}
+}
+
+func (mapper *WebhookMapper) ToSQLiteInsertParams(webhook *models.Webhook) sqlitestore.InsertWebhookParams {
+	return sqlitestore.InsertWebhookParams{
+		ID:         webhook.ID,
+		RoomID:     toNullUUID(webhook.RoomID),
+		Url:        webhook.URL,
+		EventKinds: encodeList(webhook.EventKinds),
+		Secret:     webhook.Secret,
+		CreatedAt:  webhook.CreatedAt.UnixMicro(),
+	}
+}
+
+func (mapper *WebhookMapper) SQLiteDeliveryToModel(delivery sqlitestore.WebhookDelivery) models.WebhookDelivery {
+	var responseStatus *int32
+	if delivery.ResponseStatus.Valid {
+		status := int32(delivery.ResponseStatus.Int64)
+		responseStatus = &status
+	}
+
+	return models.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventKind:      delivery.EventKind,
+		Payload:        []byte(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       int32(delivery.Attempts),
+		ResponseStatus: responseStatus,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  time.UnixMicro(delivery.NextAttemptAt),
+		CreatedAt:      time.UnixMicro(delivery.CreatedAt),
+		CompletedAt:    fromMicros(delivery.CompletedAt),
+	}
+}
+
+// ToResponse leaves the secret out, it is only shown once when the webhook
+// is created.
+func (mapper *WebhookMapper) ToResponse(webhook *models.Webhook) *response.WebhookResponse {
+	var roomId string
+	if webhook.RoomID != nil {
+		roomId = webhook.RoomID.String()
+	}
+
+	return &response.WebhookResponse{
+		ID:         webhook.ID.String(),
+		RoomID:     roomId,
+		URL:        webhook.URL,
+		EventKinds: webhook.EventKinds,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func (mapper *WebhookMapper) DeliveryToResponse(delivery *models.WebhookDelivery) *response.WebhookDeliveryResponse {
+	var nextAttemptAt *time.Time
+	if delivery.Status == models.WebhookDeliveryPending {
+		nextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	return &response.WebhookDeliveryResponse{
+		ID:             delivery.ID.String(),
+		WebhookID:      delivery.WebhookID.String(),
+		EventKind:      delivery.EventKind,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  nextAttemptAt,
+		CreatedAt:      delivery.CreatedAt,
+		CompletedAt:    delivery.CompletedAt,
+	}
+}
+
+func toPgUUID(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
+
+func toNullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
@@ -0,0 +1,50 @@
+// Package metrics counts what the server does. The counters are published
+// through expvar, served as JSON under /debug/vars of the metrics address.
+package metrics
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/http"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+)
+
+// EventCounter counts the delivered events per kind. An event delivered
+// again after a failure is counted again.
+type EventCounter struct {
+	counts *expvar.Map
+}
+
+// NewEventCounter publishes the counts under name, which must not be taken
+// by another expvar variable.
+func NewEventCounter(name string) *EventCounter {
+	return &EventCounter{counts: expvar.NewMap(name)}
+}
+
+func (c *EventCounter) HandleEvent(ctx context.Context, event events.Event) error {
+	c.counts.Add(string(event.Kind()), 1)
+	return nil
+}
+
+// Handler serves the expvar variables like expvar.Handler, but for cmdline.
+// The secrets can be passed as flags and would show in it.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, "{\n")
+		first := true
+		expvar.Do(func(kv expvar.KeyValue) {
+			if kv.Key == "cmdline" {
+				return
+			}
+			if !first {
+				fmt.Fprint(w, ",\n")
+			}
+			first = false
+			fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+		})
+		fmt.Fprint(w, "\n}\n")
+	})
+}
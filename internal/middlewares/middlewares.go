@@ -10,6 +10,15 @@ type ctxKeyLanguage string
 
 const LangKey ctxKeyLanguage = "language"
 
+type ctxKeyRoomAccess string
+
+const RoomAccessKey ctxKeyRoomAccess = "room_access"
+
+type ctxKeyAPIKey string
+
+// APIKeyKey holds true for the requests sent with a valid API key.
+const APIKeyKey ctxKeyAPIKey = "api_key"
+
 var languageSuports = []string{"en", "pt-BR"}
 
 func LanguageMiddleware(next http.Handler) http.Handler {
@@ -25,6 +34,38 @@ func LanguageMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// RoomAccessMiddleware stores the room access grant sent with the request.
+// WebSocket clients can't set headers, so the grant is also read from the
+// access_token query parameter.
+func RoomAccessMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grant := r.Header.Get("X-Room-Access")
+		if grant == "" {
+			grant = r.URL.Query().Get("access_token")
+		}
+
+		ctx := context.WithValue(r.Context(), RoomAccessKey, grant)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// APIKeyMiddleware checks the API key sent in the X-Api-Key header with
+// authenticate. A request without a valid key goes on as a participant.
+func APIKeyMiddleware(authenticate func(ctx context.Context, key string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("X-Api-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), APIKeyKey, authenticate(r.Context(), key))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func parseAcceptLanguage(al string) string {
 	if strings.Contains(al, ",") {
 		parts := strings.Split(al, ",")
@@ -0,0 +1,152 @@
+// Package migrate moves a database schema between the versions of a list of
+// numbered SQL migrations. The files are named like 001_create_rooms.sql
+// and hold the statements that apply the migration above the separator
+// line and the ones that revert it below, the layout tern uses, so the
+// databases it migrated keep their version.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const separator = "---- create above / drop below ----"
+
+var ErrInvalidVersion = errors.New("invalid version")
+
+// Migration is one step of the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Database is where the migrations are applied.
+type Database interface {
+	// Version returns the version of the schema, 0 when no migration was
+	// applied.
+	Version(ctx context.Context) (int, error)
+	// Apply runs sql and records version in a single transaction, so a
+	// failed migration leaves the schema where it was.
+	Apply(ctx context.Context, sql string, version int) error
+}
+
+// Load reads the migrations in dir. Their versions must follow each other
+// from 1.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		base := path.Base(name)
+		version, err := strconv.Atoi(strings.SplitN(base, "_", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration name %q", base)
+		}
+		if version != len(migrations)+1 {
+			return nil, fmt.Errorf("migration %s should be number %d", base, len(migrations)+1)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		up, down, _ := strings.Cut(string(content), separator)
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    base,
+			Up:      up,
+			Down:    down,
+		})
+	}
+	return migrations, nil
+}
+
+type Migrator struct {
+	database   Database
+	migrations []Migration
+}
+
+func New(database Database, migrations []Migration) *Migrator {
+	return &Migrator{
+		database:   database,
+		migrations: migrations,
+	}
+}
+
+// Latest returns the version of the last migration.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Migrations returns the migrations in the order they are applied.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Version returns the version of the schema.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	return m.database.Version(ctx)
+}
+
+// Up applies every migration not applied yet.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	current, err := m.database.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return fmt.Errorf("%w: no migration to revert", ErrInvalidVersion)
+	}
+	return m.To(ctx, current-1)
+}
+
+// To applies or reverts the migrations, one at a time, until the schema is
+// at version target.
+func (m *Migrator) To(ctx context.Context, target int) error {
+	if target < 0 || target > m.Latest() {
+		return fmt.Errorf("%w: %d, it must be between 0 and %d", ErrInvalidVersion, target, m.Latest())
+	}
+
+	current, err := m.database.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if current > m.Latest() {
+		return fmt.Errorf("the schema is at version %d, newer than the last migration %d", current, m.Latest())
+	}
+
+	for ; current < target; current++ {
+		migration := m.migrations[current]
+		if err := m.database.Apply(ctx, migration.Up, migration.Version); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+	}
+	for ; current > target; current-- {
+		migration := m.migrations[current-1]
+		if strings.TrimSpace(migration.Down) == "" {
+			return fmt.Errorf("migration %s can't be reverted", migration.Name)
+		}
+		if err := m.database.Apply(ctx, migration.Down, migration.Version-1); err != nil {
+			return fmt.Errorf("failed to revert migration %s: %w", migration.Name, err)
+		}
+	}
+	return nil
+}
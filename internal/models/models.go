@@ -1,16 +1,123 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Message struct {
-	ID         uuid.UUID
-	RoomID     uuid.UUID
-	Message    string
-	LikesCount int64
-	Answered   bool
+	ID             uuid.UUID
+	RoomID         uuid.UUID
+	Message        string
+	LikesCount     int64
+	DownvotesCount int64
+	Answered       bool
+	Pinned         bool
+	Tag            string
+	// ModerationFlags holds why the content filters flagged the message
+	ModerationFlags []string
+	// Hidden keeps the message out of the room, only admins see it
+	Hidden    bool
+	CreatedAt time.Time
+	Reactions map[string]int64
 }
 
 type Room struct {
-	ID      uuid.UUID
-	Subject string
+	ID                 uuid.UUID
+	Subject            string
+	Description        string
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	HostName           string
+	CoverImageURL      string
+	Slug               string
+	JoinCode           string
+	PasscodeHash       string
+	Private            bool
+	CreatedAt          time.Time
+	ClosedAt           *time.Time
+	ReactionKinds      []string
+	DownvotesEnabled   bool
+	SpotlightMessageID *uuid.UUID
+	Tags               []string
+	// TagCounts holds the number of messages per tag, when it was loaded
+	TagCounts map[string]int64
+}
+
+type Poll struct {
+	ID        uuid.UUID
+	RoomID    uuid.UUID
+	Question  string
+	CreatedAt time.Time
+	ClosedAt  *time.Time
+	Options   []PollOption
+}
+
+type PollOption struct {
+	ID         uuid.UUID
+	PollID     uuid.UUID
+	Position   int32
+	Label      string
+	VotesCount int64
+}
+
+// OutboxEvent is an event for the subscribers of a room, recorded in the
+// same transaction as the change it describes. Payload is the JSON value
+// sent along with Kind.
+type OutboxEvent struct {
+	ID       int64
+	RoomID   uuid.UUID
+	Kind     string
+	Payload  []byte
+	Attempts int32
+	// Handled is how many of the subscribers took the event already, in
+	// the order the dispatcher hands it over. Attempts counts the failures
+	// of the next one.
+	Handled   int32
+	CreatedAt time.Time
+}
+
+// Webhook posts the events of a room to URL, or the events of every room
+// when RoomID is nil. An empty EventKinds selects every kind.
+type Webhook struct {
+	ID         uuid.UUID
+	RoomID     *uuid.UUID
+	URL        string
+	EventKinds []string
+	Secret     string
+	CreatedAt  time.Time
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is an event on its way to a webhook. A pending delivery
+// is attempted again from NextAttemptAt on, ResponseStatus and LastError
+// tell how the last attempt went.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventKind      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	ResponseStatus *int32
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	CompletedAt    *time.Time
+}
+
+// APIKey lets an integration into every room. Only the SHA-256 hash of the
+// key is stored, Prefix is the start of the key to recognize it by.
+type APIKey struct {
+	ID        uuid.UUID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	CreatedAt time.Time
 }
@@ -0,0 +1,181 @@
+// Package outbox delivers the events the services publish along with their
+// changes. An event is only seen by the dispatcher once the transaction that
+// saved it is committed, so nobody hears about a change that was rolled back.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+)
+
+const (
+	// DefaultInterval is how often the outbox is checked for new events,
+	// the delay subscribers may see on top of the request itself.
+	DefaultInterval = 250 * time.Millisecond
+
+	batchSize = 100
+	// lease keeps the claimed events from being dispatched by someone else
+	// meanwhile. The events of a dispatcher that stopped halfway are
+	// dispatched again once it runs out.
+	lease = 30 * time.Second
+	// maxAttempts is how many times an event is handed to a subscriber
+	// before it is given up on, so one broken event can't hold back the
+	// ones after it forever.
+	maxAttempts = 5
+	// dispatched events are kept for a while to look into what was sent
+	retention  = 24 * time.Hour
+	pruneEvery = time.Hour
+)
+
+// Dispatcher hands the pending events of the outbox to its subscribers in
+// the order they were published. An error of a subscriber leaves the event
+// in the outbox, on the next check it is handed to that subscriber and the
+// ones after it, those before it already took it. The subscribers live in
+// this process, so a single dispatcher runs per outbox, the claims only keep
+// two of them from sending the same event while one replaces the other.
+type Dispatcher struct {
+	repository  repositories.OutboxRepository
+	subscribers []events.Subscriber
+	interval    time.Duration
+	stop        chan struct{}
+	done        chan struct{}
+	cancel      context.CancelFunc
+}
+
+func NewDispatcher(repository repositories.OutboxRepository, interval time.Duration, subscribers ...events.Subscriber) *Dispatcher {
+	return &Dispatcher{
+		repository:  repository,
+		subscribers: subscribers,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		var prunedAt time.Time
+		for {
+			d.dispatch(ctx)
+			if time.Since(prunedAt) >= pruneEvery {
+				d.prune(ctx)
+				prunedAt = time.Now()
+			}
+
+			select {
+			case <-d.stop:
+				// what was committed before stopping is still delivered
+				d.dispatch(ctx)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the pending events to be dispatched. Once ctx is done the
+// dispatch is cancelled instead, the remaining events are delivered after
+// the next start.
+func (d *Dispatcher) Stop(ctx context.Context) {
+	close(d.stop)
+
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+	}
+	d.cancel()
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := d.repository.ClaimPendingEvents(ctx, time.Now().Add(lease), batchSize)
+		if err != nil {
+			slog.Error("failed to claim pending outbox events", "error", err)
+			return
+		}
+
+		for i, event := range events {
+			if !d.deliver(ctx, event) {
+				// the order of the events is kept, the next check starts
+				// over from the failed one and claims the rest again
+				d.release(ctx, events[i+1:])
+				return
+			}
+		}
+
+		if len(events) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver reports whether the dispatcher may go on with the next event.
+func (d *Dispatcher) deliver(ctx context.Context, event models.OutboxEvent) bool {
+	decoded, err := events.Decode(events.Kind(event.Kind), event.Payload)
+	if err != nil {
+		// retrying won't make it readable
+		slog.Error("giving up on undecodable outbox event", "id", event.ID, "kind", event.Kind, "error", err)
+		return d.repository.MarkEventDispatched(ctx, event.ID) == nil
+	}
+
+	// the attempts count the failures of the first subscriber left
+	attempts := event.Attempts
+	for handled := event.Handled; int(handled) < len(d.subscribers); handled++ {
+		if handled > event.Handled {
+			attempts = 0
+		}
+
+		if err := d.subscribers[handled].HandleEvent(ctx, decoded); err != nil {
+			slog.Error("failed to deliver outbox event", "id", event.ID, "kind", event.Kind, "subscriber", handled, "attempt", attempts+1, "error", err)
+			if attempts+1 < maxAttempts {
+				// the repository logs its own errors, the attempt is
+				// counted on the next failure then
+				_ = d.repository.MarkEventFailed(ctx, event.ID, handled)
+				return false
+			}
+			// the subscribers after it still get the event
+			slog.Warn("giving up on outbox event for subscriber", "id", event.ID, "kind", event.Kind, "subscriber", handled)
+		}
+	}
+
+	return d.repository.MarkEventDispatched(ctx, event.ID) == nil
+}
+
+// release gives up the claim on events left unattempted, a failure only
+// delays them until their lease runs out.
+func (d *Dispatcher) release(ctx context.Context, events []models.OutboxEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	eventIds := make([]int64, len(events))
+	for i, event := range events {
+		eventIds[i] = event.ID
+	}
+	// the repository logs its own errors
+	_ = d.repository.ReleaseEvents(ctx, eventIds)
+}
+
+func (d *Dispatcher) prune(ctx context.Context) {
+	deleted, err := d.repository.DeleteDispatchedEvents(ctx, time.Now().Add(-retention))
+	if err != nil {
+		slog.Error("failed to delete dispatched outbox events", "error", err)
+	} else if deleted > 0 {
+		slog.Info("deleted dispatched outbox events", "count", deleted)
+	}
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+)
+
+// Publisher saves the published events to the outbox. With the repository
+// joining the unit of work of ctx, an event is stored only if the change it
+// describes is committed.
+type Publisher struct {
+	repository repositories.OutboxRepository
+}
+
+func NewPublisher(repository repositories.OutboxRepository) *Publisher {
+	return &Publisher{repository: repository}
+}
+
+func (p *Publisher) Publish(ctx context.Context, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.repository.SaveEvent(ctx, event.Room(), string(event.Kind()), payload)
+}
@@ -0,0 +1,98 @@
+package ranking
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+)
+
+type Sort string
+
+const (
+	// SortCreated keeps the messages in the order they were asked.
+	SortCreated Sort = "created"
+	// SortScore orders by net score, likes minus downvotes.
+	SortScore Sort = "score"
+	// SortWilson orders by the lower bound of the Wilson score interval, so a
+	// message with 9 likes out of 10 votes ranks below one with 90 out of 100.
+	SortWilson Sort = "wilson"
+	// SortHot orders by net score decayed by the age of the message.
+	SortHot Sort = "hot"
+)
+
+const (
+	// z for a 95% confidence level
+	wilsonZ = 1.96
+	// hotGravity controls how fast old messages sink in the hot ranking
+	hotGravity = 1.8
+)
+
+func ParseSort(value string) (Sort, bool) {
+	switch Sort(value) {
+	case "", SortCreated:
+		return SortCreated, true
+	case SortScore, SortWilson, SortHot:
+		return Sort(value), true
+	}
+	return "", false
+}
+
+func NetScore(likes int64, downvotes int64) int64 {
+	return likes - downvotes
+}
+
+func Wilson(likes int64, downvotes int64) float64 {
+	n := float64(likes + downvotes)
+	if n == 0 {
+		return 0
+	}
+
+	p := float64(likes) / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+func Hot(likes int64, downvotes int64, createdAt time.Time, now time.Time) float64 {
+	hours := now.Sub(createdAt).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return float64(NetScore(likes, downvotes)) / math.Pow(hours+2, hotGravity)
+}
+
+// SortMessages orders messages in place, pinned messages first and then best
+// first. Ties keep the order the messages were asked in.
+func SortMessages(messages []models.Message, by Sort, now time.Time) {
+	var score func(message *models.Message) float64
+	switch by {
+	case SortScore:
+		score = func(message *models.Message) float64 {
+			return float64(NetScore(message.LikesCount, message.DownvotesCount))
+		}
+	case SortWilson:
+		score = func(message *models.Message) float64 {
+			return Wilson(message.LikesCount, message.DownvotesCount)
+		}
+	case SortHot:
+		score = func(message *models.Message) float64 {
+			return Hot(message.LikesCount, message.DownvotesCount, message.CreatedAt, now)
+		}
+	default:
+		score = func(message *models.Message) float64 {
+			return 0
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Pinned != messages[j].Pinned {
+			return messages[i].Pinned
+		}
+		left, right := score(&messages[i]), score(&messages[j])
+		if left != right {
+			return left > right
+		}
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the limit of each route group. Reads are left unlimited.
+type Config struct {
+	// Rooms limits room creation and management per client address
+	Rooms Limit
+	// Messages limits new questions per participant
+	Messages Limit
+	// MessagesPerIP limits new questions per client address as well, the
+	// participant is whoever the client claims to be. It is higher than
+	// Messages as participants share addresses behind a NAT.
+	MessagesPerIP Limit
+	// Votes limits likes, reactions, downvotes and poll votes per participant
+	Votes Limit
+	// VotesPerIP limits the votes per client address as well
+	VotesPerIP Limit
+	// PollVotesPerIP limits the votes each client address casts on a poll.
+	// A poll counts one vote per participant, and the participant is whoever
+	// the client claims to be, so this is what keeps an address from voting
+	// over and over under new names.
+	PollVotesPerIP Limit
+	// Subscriptions limits new WebSocket connections per client address
+	Subscriptions Limit
+	// SocketCommands limits the frames each WebSocket connection may send
+	SocketCommands Limit
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Rooms:          PerMinute(30),
+		Messages:       PerMinute(10),
+		MessagesPerIP:  PerMinute(60),
+		Votes:          PerMinute(120),
+		VotesPerIP:     PerMinute(600),
+		PollVotesPerIP: PerHour(20),
+		Subscriptions:  PerMinute(30),
+		SocketCommands: PerMinute(60),
+	}
+}
+
+// ParseLimit reads a limit written as "<events>/<duration>".
+func ParseLimit(value string) (Limit, error) {
+	if value == "0" {
+		return Limit{}, nil
+	}
+
+	events, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("missing period in limit %q", value)
+	}
+
+	n, err := strconv.Atoi(events)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid events in limit %q", value)
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in limit %q", value)
+	}
+
+	return Limit{Rate: float64(n) / duration.Seconds(), Burst: n}, nil
+}
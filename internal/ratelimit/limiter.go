@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Burst events at once, refilled at Rate events per second.
+// A zero Rate turns the limit off.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute is a limit of n events per minute that may all be spent at once.
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// PerHour is a limit of n events per hour that may all be spent at once.
+func PerHour(n int) Limit {
+	return Limit{Rate: float64(n) / 3600, Burst: n}
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	lastSeen time.Time
+}
+
+// Limiter keeps a token bucket per key. Buckets that were left full are
+// dropped now and then so keys that stopped sending don't pile up.
+type Limiter struct {
+	limit     Limit
+	buckets   map[string]*bucket
+	mutex     *sync.Mutex
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewLimiter(limit Limit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+		mutex:   &sync.Mutex{},
+		now:     time.Now,
+	}
+}
+
+// Allow spends a token of key's bucket. When the bucket is empty it returns
+// false along with how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || !l.limit.Enabled() {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+elapsed*l.limit.Rate)
+	b.last = now
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := (1 - b.tokens) / l.limit.Rate
+		return false, time.Duration(wait * float64(time.Second))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that had time to refill completely. It must be
+// called with the mutex held.
+func (l *Limiter) sweep(now time.Time) {
+	refill := time.Duration(float64(l.limit.Burst) / l.limit.Rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
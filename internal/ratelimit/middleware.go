@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/exception_handler"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/go-chi/chi"
+)
+
+// ParticipantHeader identifies the participant sending a request, for the
+// routes that are limited per participant instead of per address.
+const ParticipantHeader = "X-Participant-Id"
+
+// KeyFunc picks the bucket a request is counted against.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys requests by client address. It expects middleware.RealIP to have
+// already replaced RemoteAddr with the address from the proxy headers.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByParticipant keys requests by the participant header, falling back to the
+// client address when it isn't sent.
+func ByParticipant(r *http.Request) string {
+	if participant := r.Header.Get(ParticipantHeader); participant != "" {
+		return "participant:" + participant
+	}
+	return "ip:" + ByIP(r)
+}
+
+// ByIPAndParam keys requests by client address and the URL parameter name,
+// so each address gets a bucket per resource of the route.
+func ByIPAndParam(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return ByIP(r) + ":" + chi.URLParam(r, name)
+	}
+}
+
+// Middleware rejects the requests over limiter's limit with a 429 and the
+// Retry-After header.
+func Middleware(limiter *Limiter, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := limiter.Allow(key(r)); !ok {
+				err := internal_errors.NewErrTooManyRequests(r.Context(), retryAfter)
+				exception_handler.WriteError(w, r, err.StatusCode, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+type MemoryAPIKeysRepository struct {
+	data *memoryData
+}
+
+func (mk *MemoryAPIKeysRepository) SaveAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	defer mk.data.lock(ctx, memoryAPIKeys)()
+
+	for _, existing := range mk.data.apiKeys {
+		if existing.apiKey.KeyHash == apiKey.KeyHash {
+			return nil, internal_errors.NewErrConflict(ctx, "RESOURCE_CONFLICT")
+		}
+	}
+
+	saved := *apiKey
+	saved.ID = uuid.New()
+	saved.CreatedAt = mk.data.now()
+	mk.data.apiKeys[saved.ID] = &memoryAPIKey{apiKey: saved, seq: mk.data.nextSeq()}
+	return &saved, nil
+}
+
+func (mk *MemoryAPIKeysRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	defer mk.data.rlock(ctx)()
+
+	for _, apiKey := range mk.data.apiKeys {
+		if apiKey.apiKey.KeyHash == keyHash {
+			found := apiKey.apiKey
+			return &found, nil
+		}
+	}
+	return nil, internal_errors.NewErrNotFound(ctx, "API key")
+}
+
+func (mk *MemoryAPIKeysRepository) FindAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	defer mk.data.rlock(ctx)()
+
+	apiKeys := make([]*memoryAPIKey, 0, len(mk.data.apiKeys))
+	for _, apiKey := range mk.data.apiKeys {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].seq < apiKeys[j].seq
+	})
+
+	modelAPIKeys := make([]models.APIKey, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		modelAPIKeys[i] = apiKey.apiKey
+	}
+	return modelAPIKeys, nil
+}
+
+func (mk *MemoryAPIKeysRepository) DeleteAPIKey(ctx context.Context, apiKeyId uuid.UUID) error {
+	defer mk.data.lock(ctx, memoryAPIKeys)()
+
+	if _, ok := mk.data.apiKeys[apiKeyId]; !ok {
+		return internal_errors.NewErrNotFound(ctx, "API key")
+	}
+	delete(mk.data.apiKeys, apiKeyId)
+	return nil
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// MemoryOutboxRepository keeps the outbox next to the rooms, so the events
+// roll back with them when a unit of work fails. Events are dropped as soon
+// as they are dispatched, nothing reads them afterwards.
+type MemoryOutboxRepository struct {
+	data *memoryData
+}
+
+func (mo *MemoryOutboxRepository) SaveEvent(ctx context.Context, roomId uuid.UUID, kind string, payload []byte) error {
+	// a unit of work rolls the appended events back without a copy
+	defer mo.data.lock(ctx)()
+
+	mo.data.eventSeq++
+	mo.data.events = append(mo.data.events, memoryOutboxEvent{
+		event: models.OutboxEvent{
+			ID:        mo.data.eventSeq,
+			RoomID:    roomId,
+			Kind:      kind,
+			Payload:   slices.Clone(payload),
+			CreatedAt: mo.data.now(),
+		},
+	})
+	return nil
+}
+
+func (mo *MemoryOutboxRepository) FindPendingEvents(ctx context.Context, limit int32) ([]models.OutboxEvent, error) {
+	defer mo.data.rlock(ctx)()
+
+	events := []models.OutboxEvent{}
+	for _, event := range mo.data.events {
+		if len(events) == int(limit) {
+			break
+		}
+		events = append(events, event.event)
+	}
+	return events, nil
+}
+
+func (mo *MemoryOutboxRepository) ClaimPendingEvents(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.OutboxEvent, error) {
+	defer mo.data.lock(ctx, memoryEvents)()
+
+	now := mo.data.now()
+	events := []models.OutboxEvent{}
+	for i := range mo.data.events {
+		if len(events) == int(limit) {
+			break
+		}
+		event := &mo.data.events[i]
+		if event.claimedUntil == nil || !event.claimedUntil.After(now) {
+			event.claimedUntil = &leaseUntil
+			events = append(events, event.event)
+		}
+	}
+	return events, nil
+}
+
+func (mo *MemoryOutboxRepository) MarkEventDispatched(ctx context.Context, eventId int64) error {
+	defer mo.data.lock(ctx, memoryEvents)()
+
+	mo.data.events = slices.DeleteFunc(mo.data.events, func(event memoryOutboxEvent) bool {
+		return event.event.ID == eventId
+	})
+	return nil
+}
+
+func (mo *MemoryOutboxRepository) MarkEventFailed(ctx context.Context, eventId int64, handled int32) error {
+	defer mo.data.lock(ctx, memoryEvents)()
+
+	if event := mo.data.findEvent(eventId); event != nil {
+		if event.event.Handled == handled {
+			event.event.Attempts++
+		} else {
+			event.event.Attempts = 1
+		}
+		event.event.Handled = handled
+		event.claimedUntil = nil
+	}
+	return nil
+}
+
+func (mo *MemoryOutboxRepository) ReleaseEvents(ctx context.Context, eventIds []int64) error {
+	defer mo.data.lock(ctx, memoryEvents)()
+
+	for _, eventId := range eventIds {
+		if event := mo.data.findEvent(eventId); event != nil {
+			event.claimedUntil = nil
+		}
+	}
+	return nil
+}
+
+// DeleteDispatchedEvents has nothing to delete, MarkEventDispatched drops the
+// events already.
+func (mo *MemoryOutboxRepository) DeleteDispatchedEvents(ctx context.Context, dispatchedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (d *memoryData) findEvent(eventId int64) *memoryOutboxEvent {
+	for i := range d.events {
+		if d.events[i].event.ID == eventId {
+			return &d.events[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// MemoryPollsRepository keeps the polls in memory, next to the rooms of the
+// MemoryRoomsRepository it was created with.
+type MemoryPollsRepository struct {
+	data *memoryData
+}
+
+func (mp *MemoryPollsRepository) SavePoll(ctx context.Context, roomId uuid.UUID, question string, options []string) (*models.Poll, error) {
+	defer mp.data.lock(ctx, memoryPolls)()
+
+	if _, ok := mp.data.rooms[roomId]; !ok {
+		return nil, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+
+	poll := models.Poll{
+		ID:        uuid.New(),
+		RoomID:    roomId,
+		Question:  question,
+		CreatedAt: mp.data.now(),
+		Options:   make([]models.PollOption, len(options)),
+	}
+	for i, label := range options {
+		poll.Options[i] = models.PollOption{
+			ID:       uuid.New(),
+			PollID:   poll.ID,
+			Position: int32(i + 1),
+			Label:    label,
+		}
+	}
+
+	mp.data.polls[poll.ID] = &memoryPoll{
+		poll: poll,
+		seq:  mp.data.nextSeq(),
+	}
+	return copyPoll(poll), nil
+}
+
+func (mp *MemoryPollsRepository) FindPoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	defer mp.data.rlock(ctx)()
+
+	poll, ok := mp.data.polls[pollId]
+	if !ok {
+		return &models.Poll{}, internal_errors.NewErrNotFound(ctx, "Poll")
+	}
+	return copyPoll(poll.poll), nil
+}
+
+func (mp *MemoryPollsRepository) FindAllRoomPolls(ctx context.Context, roomId uuid.UUID) ([]models.Poll, error) {
+	defer mp.data.rlock(ctx)()
+
+	polls := mp.data.sortedPolls(func(poll *memoryPoll) bool {
+		return poll.poll.RoomID == roomId
+	})
+
+	modelPolls := make([]models.Poll, len(polls))
+	for i, poll := range polls {
+		modelPolls[i] = *copyPoll(poll.poll)
+	}
+	return modelPolls, nil
+}
+
+// SaveVote counts the vote of a participant. It returns false when the
+// participant already voted in the poll.
+func (mp *MemoryPollsRepository) SaveVote(ctx context.Context, pollId uuid.UUID, optionId uuid.UUID, participantId string) (bool, error) {
+	defer mp.data.lock(ctx, memoryPolls)()
+
+	poll, ok := mp.data.polls[pollId]
+	if !ok {
+		return false, internal_errors.NewErrNotFound(ctx, "Poll")
+	}
+
+	option := -1
+	for i := range poll.poll.Options {
+		if poll.poll.Options[i].ID == optionId {
+			option = i
+		}
+	}
+	if option < 0 {
+		return false, internal_errors.NewErrNotFound(ctx, "Poll option")
+	}
+
+	if mp.data.votes[pollId] == nil {
+		mp.data.votes[pollId] = make(map[string]uuid.UUID)
+	}
+	if _, voted := mp.data.votes[pollId][participantId]; voted {
+		return false, nil
+	}
+
+	mp.data.votes[pollId][participantId] = optionId
+	poll.poll.Options[option].VotesCount++
+	return true, nil
+}
+
+func (mp *MemoryPollsRepository) ClosePoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	defer mp.data.lock(ctx, memoryPolls)()
+
+	poll, ok := mp.data.polls[pollId]
+	if !ok {
+		return nil, internal_errors.NewErrNotFound(ctx, "Poll")
+	}
+
+	if poll.poll.ClosedAt == nil {
+		now := mp.data.now()
+		poll.poll.ClosedAt = &now
+	}
+	return copyPoll(poll.poll), nil
+}
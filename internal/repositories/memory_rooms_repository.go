@@ -0,0 +1,597 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// MemoryRoomsRepository keeps the rooms in memory, for demos and local
+// development. Everything is lost when the process exits.
+type MemoryRoomsRepository struct {
+	data *memoryData
+}
+
+func (mr *MemoryRoomsRepository) CloseEndedRooms(ctx context.Context, endedBefore time.Time) ([]uuid.UUID, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	now := mr.data.now()
+	closed := []uuid.UUID{}
+	for id, room := range mr.data.rooms {
+		if room.room.ClosedAt == nil && room.room.EndsAt != nil && room.room.EndsAt.Before(endedBefore) {
+			room.room.ClosedAt = &now
+			closed = append(closed, id)
+		}
+	}
+	return closed, nil
+}
+
+func (mr *MemoryRoomsRepository) CloseInactiveRooms(ctx context.Context, inactiveSince time.Time) ([]uuid.UUID, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	active := make(map[uuid.UUID]bool)
+	for _, message := range mr.data.messages {
+		if !message.message.CreatedAt.Before(inactiveSince) {
+			active[message.message.RoomID] = true
+		}
+	}
+
+	now := mr.data.now()
+	closed := []uuid.UUID{}
+	for id, room := range mr.data.rooms {
+		if room.room.ClosedAt != nil || active[id] {
+			continue
+		}
+		// a room is idle from its start, not from when it was scheduled
+		idleSince := room.room.CreatedAt
+		if startsAt := room.room.StartsAt; startsAt != nil {
+			if startsAt.After(now) {
+				continue
+			}
+			if startsAt.After(idleSince) {
+				idleSince = *startsAt
+			}
+		}
+		if idleSince.Before(inactiveSince) {
+			room.room.ClosedAt = &now
+			closed = append(closed, id)
+		}
+	}
+	return closed, nil
+}
+
+func (mr *MemoryRoomsRepository) DeleteArchivedRooms(ctx context.Context, closedBefore time.Time) ([]uuid.UUID, error) {
+	defer mr.data.lock(ctx, memoryRoomTables...)()
+
+	deleted := []uuid.UUID{}
+	for id, room := range mr.data.rooms {
+		if room.room.ClosedAt != nil && room.room.ClosedAt.Before(closedBefore) {
+			mr.data.deleteRoom(id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+// AnonymizeArchivedRooms returns the number of messages it removed, like the
+// Postgres query does.
+func (mr *MemoryRoomsRepository) AnonymizeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error) {
+	defer mr.data.lock(ctx, memoryRooms, memoryMessages, memoryPolls)()
+
+	now := mr.data.now()
+	archived := make(map[uuid.UUID]bool)
+	for id, room := range mr.data.rooms {
+		if room.room.ClosedAt != nil && room.room.ClosedAt.Before(closedBefore) && room.anonymizedAt == nil {
+			room.room.Subject = "[removed]"
+			room.room.Slug = id.String()
+			room.room.Description = ""
+			room.room.HostName = ""
+			room.room.CoverImageURL = ""
+			room.anonymizedAt = &now
+			archived[id] = true
+		}
+	}
+
+	// the votes keep counting, but can't be told apart anymore
+	for id, poll := range mr.data.polls {
+		if !archived[poll.poll.RoomID] {
+			continue
+		}
+		votes := make(map[string]uuid.UUID, len(mr.data.votes[id]))
+		for _, optionId := range mr.data.votes[id] {
+			votes[uuid.NewString()] = optionId
+		}
+		mr.data.votes[id] = votes
+	}
+
+	var anonymized int64
+	for _, message := range mr.data.messages {
+		if archived[message.message.RoomID] {
+			message.message.Message = "[removed]"
+			anonymized++
+		}
+	}
+	return anonymized, nil
+}
+
+func (mr *MemoryRoomsRepository) CloseRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	room, ok := mr.data.rooms[roomId]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+
+	if room.room.ClosedAt == nil {
+		now := mr.data.now()
+		room.room.ClosedAt = &now
+	}
+	return copyRoom(room.room), nil
+}
+
+func (mr *MemoryRoomsRepository) ReopenRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	room, ok := mr.data.rooms[roomId]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+
+	room.room.ClosedAt = nil
+	return copyRoom(room.room), nil
+}
+
+func (mr *MemoryRoomsRepository) FindMessage(ctx context.Context, messageId uuid.UUID) (*models.Message, error) {
+	defer mr.data.rlock(ctx)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return &models.Message{}, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	modelMessage := copyMessage(message.message)
+	modelMessage.Reactions = mr.data.messageReactions(messageId)
+	return modelMessage, nil
+}
+
+func (mr *MemoryRoomsRepository) FindRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	defer mr.data.rlock(ctx)()
+
+	room, ok := mr.data.rooms[roomId]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	return copyRoom(room.room), nil
+}
+
+// LockRoom is FindRoom, a unit of work holds the write lock of the whole
+// store.
+func (mr *MemoryRoomsRepository) LockRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	return mr.FindRoom(ctx, roomId)
+}
+
+func (mr *MemoryRoomsRepository) FindRoomByJoinCode(ctx context.Context, code string) (*models.Room, error) {
+	defer mr.data.rlock(ctx)()
+
+	for _, room := range mr.data.rooms {
+		if room.room.JoinCode == code {
+			return copyRoom(room.room), nil
+		}
+	}
+	return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+}
+
+func (mr *MemoryRoomsRepository) FindAllRooms(ctx context.Context) ([]models.Room, error) {
+	defer mr.data.rlock(ctx)()
+
+	rooms := mr.data.sortedRooms(func(room *memoryRoom) bool {
+		return !room.room.Private
+	})
+
+	modelRooms := make([]models.Room, len(rooms))
+	for i, room := range rooms {
+		modelRooms[i] = *copyRoom(room.room)
+	}
+	return modelRooms, nil
+}
+
+func (mr *MemoryRoomsRepository) SearchRooms(ctx context.Context, query string, joinCode string) ([]models.Room, error) {
+	defer mr.data.rlock(ctx)()
+
+	query = strings.ToLower(query)
+	rooms := mr.data.sortedRooms(func(room *memoryRoom) bool {
+		return strings.Contains(strings.ToLower(room.room.Subject), query) ||
+			strings.Contains(strings.ToLower(room.room.Slug), query) ||
+			strings.Contains(strings.ToLower(room.room.HostName), query) ||
+			room.room.JoinCode == joinCode
+	})
+
+	modelRooms := make([]models.Room, len(rooms))
+	for i, room := range rooms {
+		modelRooms[i] = *copyRoom(room.room)
+	}
+	return modelRooms, nil
+}
+
+func (mr *MemoryRoomsRepository) FindAllRoomMessages(ctx context.Context, roomID uuid.UUID, tag string) ([]models.Message, error) {
+	defer mr.data.rlock(ctx)()
+
+	messages := mr.data.sortedMessages(func(message *memoryMessage) bool {
+		return message.message.RoomID == roomID && (tag == "" || message.message.Tag == tag)
+	})
+
+	modelMessages := make([]models.Message, len(messages))
+	for i, message := range messages {
+		modelMessage := copyMessage(message.message)
+		if reactions := mr.data.messageReactions(message.message.ID); len(reactions) > 0 {
+			modelMessage.Reactions = reactions
+		}
+		modelMessages[i] = *modelMessage
+	}
+	return modelMessages, nil
+}
+
+// StreamRoomMessages hands fn a snapshot of the messages, so fn runs without
+// holding the lock.
+func (mr *MemoryRoomsRepository) StreamRoomMessages(ctx context.Context, roomID uuid.UUID, fn func(*models.Message) error) error {
+	unlock := mr.data.rlock(ctx)
+	messages := mr.data.sortedMessages(func(message *memoryMessage) bool {
+		return message.message.RoomID == roomID
+	})
+	modelMessages := make([]*models.Message, len(messages))
+	for i, message := range messages {
+		modelMessages[i] = copyMessage(message.message)
+		if reactions := mr.data.messageReactions(message.message.ID); len(reactions) > 0 {
+			modelMessages[i].Reactions = reactions
+		}
+	}
+	unlock()
+
+	for _, message := range modelMessages {
+		if err := fn(message); err != nil {
+			return internal_errors.NewErrInternal(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (mr *MemoryRoomsRepository) SaveRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	if err := mr.checkRoom(ctx, room, uuid.Nil); err != nil {
+		return &models.Room{}, err
+	}
+	for _, other := range mr.data.rooms {
+		if other.room.JoinCode == room.JoinCode {
+			return &models.Room{}, internal_errors.NewErrConflict(ctx, "ROOM_JOIN_CODE_CONFLICT")
+		}
+	}
+
+	savedRoom := copyRoom(*room)
+	savedRoom.ID = uuid.New()
+	savedRoom.CreatedAt = mr.data.now()
+	savedRoom.ClosedAt = nil
+	savedRoom.SpotlightMessageID = nil
+
+	mr.data.rooms[savedRoom.ID] = &memoryRoom{
+		room: *savedRoom,
+		seq:  mr.data.nextSeq(),
+	}
+	return copyRoom(*savedRoom), nil
+}
+
+func (mr *MemoryRoomsRepository) FindRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	defer mr.data.rlock(ctx)()
+
+	wanted := make(map[uuid.UUID]bool, len(roomIds))
+	for _, id := range roomIds {
+		wanted[id] = true
+	}
+
+	counts := make(map[uuid.UUID]map[string]int64, len(roomIds))
+	for _, message := range mr.data.messages {
+		roomId := message.message.RoomID
+		if !wanted[roomId] || message.message.Tag == "" {
+			continue
+		}
+		if counts[roomId] == nil {
+			counts[roomId] = make(map[string]int64)
+		}
+		counts[roomId][message.message.Tag]++
+	}
+	return counts, nil
+}
+
+func (mr *MemoryRoomsRepository) IsRoomSlugTaken(ctx context.Context, slug string, roomId uuid.UUID) (bool, error) {
+	defer mr.data.rlock(ctx)()
+
+	for id, room := range mr.data.rooms {
+		if room.room.Slug == slug && id != roomId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (mr *MemoryRoomsRepository) FindRoomSlugs(ctx context.Context, slug string, roomId uuid.UUID) ([]string, error) {
+	defer mr.data.rlock(ctx)()
+
+	slugs := []string{}
+	for _, room := range mr.data.sortedRooms(func(room *memoryRoom) bool {
+		return room.room.ID != roomId && (room.room.Slug == slug || strings.HasPrefix(room.room.Slug, slug+"-"))
+	}) {
+		slugs = append(slugs, room.room.Slug)
+	}
+	return slugs, nil
+}
+
+func (mr *MemoryRoomsRepository) UpdateRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	stored, ok := mr.data.rooms[room.ID]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	if err := mr.checkRoom(ctx, room, room.ID); err != nil {
+		return &models.Room{}, err
+	}
+
+	updated := copyRoom(*room)
+	stored.room.Subject = updated.Subject
+	stored.room.Description = updated.Description
+	stored.room.StartsAt = updated.StartsAt
+	stored.room.EndsAt = updated.EndsAt
+	stored.room.HostName = updated.HostName
+	stored.room.CoverImageURL = updated.CoverImageURL
+	stored.room.Slug = updated.Slug
+	stored.room.PasscodeHash = updated.PasscodeHash
+	stored.room.Private = updated.Private
+	stored.room.ReactionKinds = updated.ReactionKinds
+	stored.room.DownvotesEnabled = updated.DownvotesEnabled
+	stored.room.Tags = updated.Tags
+	return copyRoom(stored.room), nil
+}
+
+func (mr *MemoryRoomsRepository) UpdateRoomJoinCode(ctx context.Context, roomId uuid.UUID, code string) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	stored, ok := mr.data.rooms[roomId]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	for id, other := range mr.data.rooms {
+		if other.room.JoinCode == code && id != roomId {
+			return &models.Room{}, internal_errors.NewErrConflict(ctx, "ROOM_JOIN_CODE_CONFLICT")
+		}
+	}
+
+	stored.room.JoinCode = code
+	return copyRoom(stored.room), nil
+}
+
+// UpdateRoomSpotlight puts a message in the spotlight of a room, a nil
+// messageId clears it.
+func (mr *MemoryRoomsRepository) UpdateRoomSpotlight(ctx context.Context, roomId uuid.UUID, messageId *uuid.UUID) (*models.Room, error) {
+	defer mr.data.lock(ctx, memoryRooms)()
+
+	stored, ok := mr.data.rooms[roomId]
+	if !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+
+	if messageId == nil {
+		stored.room.SpotlightMessageID = nil
+		return copyRoom(stored.room), nil
+	}
+
+	if _, ok := mr.data.messages[*messageId]; !ok {
+		return &models.Room{}, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+	id := *messageId
+	stored.room.SpotlightMessageID = &id
+	return copyRoom(stored.room), nil
+}
+
+func (mr *MemoryRoomsRepository) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
+	defer mr.data.lock(ctx, memoryRoomTables...)()
+
+	if _, ok := mr.data.rooms[roomId]; !ok {
+		return internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	mr.data.deleteRoom(roomId)
+	return nil
+}
+
+func (mr *MemoryRoomsRepository) SaveMessage(ctx context.Context, params *request.MessageRequest, moderationFlags []string) (uuid.UUID, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	if _, ok := mr.data.rooms[params.RoomID]; !ok {
+		return uuid.Nil, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	return mr.insertMessage(params.RoomID, params.Message, params.Tag, moderationFlags), nil
+}
+
+// SaveMessages saves messages[i] with tags[i] and moderationFlags[i], all of
+// them or none.
+func (mr *MemoryRoomsRepository) SaveMessages(ctx context.Context, roomId uuid.UUID, messages []string, tags []string, moderationFlags [][]string) ([]uuid.UUID, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	if _, ok := mr.data.rooms[roomId]; !ok {
+		return nil, internal_errors.NewErrNotFound(ctx, "Room")
+	}
+
+	messageIds := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		var tag string
+		if i < len(tags) {
+			tag = tags[i]
+		}
+		var flags []string
+		if i < len(moderationFlags) {
+			flags = moderationFlags[i]
+		}
+		messageIds[i] = mr.insertMessage(roomId, message, tag, flags)
+	}
+	return messageIds, nil
+}
+
+func (mr *MemoryRoomsRepository) ReactToMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return 0, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	if mr.data.reactions[messageId] == nil {
+		mr.data.reactions[messageId] = make(map[string]int64)
+	}
+	mr.data.reactions[messageId][kind]++
+
+	count := mr.data.reactions[messageId][kind]
+	if kind == "like" {
+		message.message.LikesCount = count
+	}
+	return count, nil
+}
+
+// RemoveReactionFromMessage returns 0 when nobody reacted with kind yet.
+func (mr *MemoryRoomsRepository) RemoveReactionFromMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	count, ok := mr.data.reactions[messageId][kind]
+	if !ok {
+		return 0, nil
+	}
+
+	count = max(count-1, 0)
+	mr.data.reactions[messageId][kind] = count
+	if message, ok := mr.data.messages[messageId]; ok && kind == "like" {
+		message.message.LikesCount = count
+	}
+	return count, nil
+}
+
+func (mr *MemoryRoomsRepository) DownvoteMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return 0, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	message.message.DownvotesCount++
+	return message.message.DownvotesCount, nil
+}
+
+func (mr *MemoryRoomsRepository) RemoveDownvoteFromMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return 0, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	message.message.DownvotesCount = max(message.message.DownvotesCount-1, 0)
+	return message.message.DownvotesCount, nil
+}
+
+func (mr *MemoryRoomsRepository) RecountMessageLikes(ctx context.Context, roomId uuid.UUID) (int64, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	var recounted int64
+	for id, message := range mr.data.messages {
+		if message.message.RoomID != roomId {
+			continue
+		}
+		if likes := mr.data.reactions[id]["like"]; message.message.LikesCount != likes {
+			message.message.LikesCount = likes
+			recounted++
+		}
+	}
+	return recounted, nil
+}
+
+func (mr *MemoryRoomsRepository) FindMessageReactions(ctx context.Context, messageId uuid.UUID) (map[string]int64, error) {
+	defer mr.data.rlock(ctx)()
+
+	return mr.data.messageReactions(messageId), nil
+}
+
+func (mr *MemoryRoomsRepository) SetMessagePinned(ctx context.Context, messageId uuid.UUID, pinned bool) (*models.Message, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return &models.Message{}, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	message.message.Pinned = pinned
+	modelMessage := copyMessage(message.message)
+	modelMessage.Reactions = mr.data.messageReactions(messageId)
+	return modelMessage, nil
+}
+
+func (mr *MemoryRoomsRepository) SetMessageHidden(ctx context.Context, messageId uuid.UUID, hidden bool) (*models.Message, error) {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	message, ok := mr.data.messages[messageId]
+	if !ok {
+		return &models.Message{}, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	message.message.Hidden = hidden
+	modelMessage := copyMessage(message.message)
+	modelMessage.Reactions = mr.data.messageReactions(messageId)
+	return modelMessage, nil
+}
+
+// MarkMessageAsAnswered does nothing for a message that doesn't exist, like
+// the Postgres statement.
+func (mr *MemoryRoomsRepository) MarkMessageAsAnswered(ctx context.Context, messageId uuid.UUID) error {
+	defer mr.data.lock(ctx, memoryMessages)()
+
+	if message, ok := mr.data.messages[messageId]; ok {
+		message.message.Answered = true
+	}
+	return nil
+}
+
+// checkRoom enforces the constraints of the rooms table. It must be called
+// with the write lock held.
+func (mr *MemoryRoomsRepository) checkRoom(ctx context.Context, room *models.Room, roomId uuid.UUID) error {
+	if room.StartsAt != nil && room.EndsAt != nil && !room.EndsAt.After(*room.StartsAt) {
+		return internal_errors.NewErrBadRequest(ctx, "INVALID_ROOM_SCHEDULE")
+	}
+
+	for id, other := range mr.data.rooms {
+		if other.room.Slug == room.Slug && id != roomId {
+			return internal_errors.NewErrConflict(ctx, "ROOM_SLUG_CONFLICT")
+		}
+	}
+	return nil
+}
+
+// insertMessage stores a new message. It must be called with the write lock
+// held.
+func (mr *MemoryRoomsRepository) insertMessage(roomId uuid.UUID, text string, tag string, moderationFlags []string) uuid.UUID {
+	message := models.Message{
+		ID:              uuid.New(),
+		RoomID:          roomId,
+		Message:         text,
+		Tag:             tag,
+		ModerationFlags: moderationFlags,
+		CreatedAt:       mr.data.now(),
+	}
+
+	mr.data.messages[message.ID] = &memoryMessage{
+		message: *copyMessage(message),
+		seq:     mr.data.nextSeq(),
+	}
+	return message.ID
+}
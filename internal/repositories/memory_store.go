@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// memoryData holds what the in-memory repositories store. The rooms and the
+// polls repositories share it so deleting a room also deletes its polls, as
+// the foreign keys do in Postgres.
+type memoryData struct {
+	mutex *sync.RWMutex
+	now   func() time.Time
+	// seq breaks the ties between rows created at the same time, so lists
+	// keep their insertion order
+	seq int64
+
+	rooms     map[uuid.UUID]*memoryRoom
+	messages  map[uuid.UUID]*memoryMessage
+	reactions map[uuid.UUID]map[string]int64
+	polls     map[uuid.UUID]*memoryPoll
+	// votes maps each poll to the option every participant voted for
+	votes map[uuid.UUID]map[string]uuid.UUID
+	// events holds the outbox in the order the events were saved, eventSeq
+	// numbers them like the id sequence of the SQL backends
+	events   []memoryOutboxEvent
+	eventSeq int64
+
+	webhooks   map[uuid.UUID]*memoryWebhook
+	deliveries map[uuid.UUID]*memoryWebhookDelivery
+
+	apiKeys map[uuid.UUID]*memoryAPIKey
+
+	// undo is set while a unit of work holds the lock
+	undo *memoryUndo
+}
+
+type memoryRoom struct {
+	room         models.Room
+	anonymizedAt *time.Time
+	seq          int64
+}
+
+type memoryMessage struct {
+	message models.Message
+	seq     int64
+}
+
+type memoryPoll struct {
+	poll models.Poll
+	seq  int64
+}
+
+type memoryOutboxEvent struct {
+	event        models.OutboxEvent
+	claimedUntil *time.Time
+}
+
+type memoryWebhook struct {
+	webhook models.Webhook
+	seq     int64
+}
+
+type memoryWebhookDelivery struct {
+	delivery models.WebhookDelivery
+	seq      int64
+}
+
+type memoryAPIKey struct {
+	apiKey models.APIKey
+	seq    int64
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		mutex:      &sync.RWMutex{},
+		now:        time.Now,
+		rooms:      make(map[uuid.UUID]*memoryRoom),
+		messages:   make(map[uuid.UUID]*memoryMessage),
+		reactions:  make(map[uuid.UUID]map[string]int64),
+		polls:      make(map[uuid.UUID]*memoryPoll),
+		votes:      make(map[uuid.UUID]map[string]uuid.UUID),
+		webhooks:   make(map[uuid.UUID]*memoryWebhook),
+		deliveries: make(map[uuid.UUID]*memoryWebhookDelivery),
+		apiKeys:    make(map[uuid.UUID]*memoryAPIKey),
+	}
+}
+
+// memoryTable is a group of rows a unit of work copies before writing to it
+// for the first time. The rows that belong to others are in the same group.
+type memoryTable int
+
+const (
+	memoryRooms memoryTable = iota
+	// the messages with their reactions
+	memoryMessages
+	// the polls with their votes
+	memoryPolls
+	memoryEvents
+	// the webhooks with their deliveries
+	memoryWebhooks
+	memoryAPIKeys
+)
+
+// memoryRoomTables are the tables deleting a room writes to.
+var memoryRoomTables = []memoryTable{memoryRooms, memoryMessages, memoryPolls, memoryWebhooks}
+
+// lock takes the write lock unless the context is in a unit of work, which
+// holds it already. In a unit of work the tables the caller is about to
+// write to are copied first, so they can be rolled back. It returns the
+// function that releases the lock.
+func (d *memoryData) lock(ctx context.Context, tables ...memoryTable) func() {
+	if tx, _ := txFrom[*memoryData](ctx); tx == d {
+		for _, table := range tables {
+			d.undo.save(d, table)
+		}
+		return func() {}
+	}
+	d.mutex.Lock()
+	return d.mutex.Unlock
+}
+
+func (d *memoryData) rlock(ctx context.Context) func() {
+	if tx, _ := txFrom[*memoryData](ctx); tx == d {
+		return func() {}
+	}
+	d.mutex.RLock()
+	return d.mutex.RUnlock
+}
+
+// memoryUndo rolls a unit of work back. It only holds copies of the tables
+// the unit of work wrote to. The events appended to the outbox are cut off
+// instead, so publishing an event doesn't copy the outbox.
+type memoryUndo struct {
+	seq      int64
+	eventSeq int64
+	events   int
+	restores map[memoryTable]func()
+}
+
+func (d *memoryData) newUndo() *memoryUndo {
+	return &memoryUndo{
+		seq:      d.seq,
+		eventSeq: d.eventSeq,
+		events:   len(d.events),
+		restores: make(map[memoryTable]func()),
+	}
+}
+
+// save copies a table unless it was copied already.
+func (u *memoryUndo) save(d *memoryData, table memoryTable) {
+	if _, ok := u.restores[table]; ok {
+		return
+	}
+
+	switch table {
+	case memoryRooms:
+		rooms := make(map[uuid.UUID]*memoryRoom, len(d.rooms))
+		for id, room := range d.rooms {
+			rooms[id] = &memoryRoom{room: *copyRoom(room.room), anonymizedAt: copyTime(room.anonymizedAt), seq: room.seq}
+		}
+		u.restores[table] = func() { d.rooms = rooms }
+	case memoryMessages:
+		messages := make(map[uuid.UUID]*memoryMessage, len(d.messages))
+		for id, message := range d.messages {
+			messages[id] = &memoryMessage{message: *copyMessage(message.message), seq: message.seq}
+		}
+		reactions := make(map[uuid.UUID]map[string]int64, len(d.reactions))
+		for id, counts := range d.reactions {
+			reactions[id] = maps.Clone(counts)
+		}
+		u.restores[table] = func() { d.messages, d.reactions = messages, reactions }
+	case memoryPolls:
+		polls := make(map[uuid.UUID]*memoryPoll, len(d.polls))
+		for id, poll := range d.polls {
+			polls[id] = &memoryPoll{poll: *copyPoll(poll.poll), seq: poll.seq}
+		}
+		votes := make(map[uuid.UUID]map[string]uuid.UUID, len(d.votes))
+		for id, pollVotes := range d.votes {
+			votes[id] = maps.Clone(pollVotes)
+		}
+		u.restores[table] = func() { d.polls, d.votes = polls, votes }
+	case memoryEvents:
+		events := slices.Clone(d.events)
+		u.restores[table] = func() { d.events = events }
+	case memoryWebhooks:
+		webhooks := make(map[uuid.UUID]*memoryWebhook, len(d.webhooks))
+		for id, webhook := range d.webhooks {
+			webhooks[id] = &memoryWebhook{webhook: *copyWebhook(webhook.webhook), seq: webhook.seq}
+		}
+		deliveries := make(map[uuid.UUID]*memoryWebhookDelivery, len(d.deliveries))
+		for id, delivery := range d.deliveries {
+			deliveries[id] = &memoryWebhookDelivery{delivery: copyDelivery(delivery.delivery), seq: delivery.seq}
+		}
+		u.restores[table] = func() { d.webhooks, d.deliveries = webhooks, deliveries }
+	case memoryAPIKeys:
+		apiKeys := make(map[uuid.UUID]*memoryAPIKey, len(d.apiKeys))
+		for id, apiKey := range d.apiKeys {
+			apiKeys[id] = &memoryAPIKey{apiKey: apiKey.apiKey, seq: apiKey.seq}
+		}
+		u.restores[table] = func() { d.apiKeys = apiKeys }
+	}
+}
+
+func (u *memoryUndo) rollback(d *memoryData) {
+	for _, restore := range u.restores {
+		restore()
+	}
+	// the copy of the outbox, if any, was taken after some appends
+	d.events = d.events[:u.events]
+	d.seq = u.seq
+	d.eventSeq = u.eventSeq
+}
+
+func (d *memoryData) nextSeq() int64 {
+	d.seq++
+	return d.seq
+}
+
+// sortedRooms returns the rooms that match in the order they were created.
+func (d *memoryData) sortedRooms(match func(*memoryRoom) bool) []*memoryRoom {
+	rooms := make([]*memoryRoom, 0, len(d.rooms))
+	for _, room := range d.rooms {
+		if match(room) {
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].seq < rooms[j].seq
+	})
+	return rooms
+}
+
+// sortedMessages returns the messages that match in the order they were
+// created.
+func (d *memoryData) sortedMessages(match func(*memoryMessage) bool) []*memoryMessage {
+	messages := make([]*memoryMessage, 0)
+	for _, message := range d.messages {
+		if match(message) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].seq < messages[j].seq
+	})
+	return messages
+}
+
+func (d *memoryData) sortedPolls(match func(*memoryPoll) bool) []*memoryPoll {
+	polls := make([]*memoryPoll, 0)
+	for _, poll := range d.polls {
+		if match(poll) {
+			polls = append(polls, poll)
+		}
+	}
+	sort.Slice(polls, func(i, j int) bool {
+		return polls[i].seq < polls[j].seq
+	})
+	return polls
+}
+
+// messageReactions returns the reactions of a message that were not all
+// taken back.
+func (d *memoryData) messageReactions(messageId uuid.UUID) map[string]int64 {
+	counts := make(map[string]int64)
+	for kind, count := range d.reactions[messageId] {
+		if count > 0 {
+			counts[kind] = count
+		}
+	}
+	return counts
+}
+
+// deleteRoom removes a room with everything that belongs to it. It must be
+// called with the write lock held.
+func (d *memoryData) deleteRoom(roomId uuid.UUID) {
+	delete(d.rooms, roomId)
+
+	for id, message := range d.messages {
+		if message.message.RoomID != roomId {
+			continue
+		}
+		delete(d.messages, id)
+		delete(d.reactions, id)
+
+		// spotlights pointing at the message are cleared, like ON DELETE
+		// SET NULL does
+		for _, room := range d.rooms {
+			if room.room.SpotlightMessageID != nil && *room.room.SpotlightMessageID == id {
+				room.room.SpotlightMessageID = nil
+			}
+		}
+	}
+
+	for id, poll := range d.polls {
+		if poll.poll.RoomID == roomId {
+			delete(d.polls, id)
+			delete(d.votes, id)
+		}
+	}
+
+	for id, webhook := range d.webhooks {
+		if webhook.webhook.RoomID != nil && *webhook.webhook.RoomID == roomId {
+			d.deleteWebhook(id)
+		}
+	}
+}
+
+// deleteWebhook removes a webhook with its deliveries. It must be called
+// with the write lock held.
+func (d *memoryData) deleteWebhook(webhookId uuid.UUID) {
+	delete(d.webhooks, webhookId)
+
+	for id, delivery := range d.deliveries {
+		if delivery.delivery.WebhookID == webhookId {
+			delete(d.deliveries, id)
+		}
+	}
+}
+
+// The models are copied on the way in and out so callers never share memory
+// with the store.
+
+func copyRoom(room models.Room) *models.Room {
+	room.StartsAt = copyTime(room.StartsAt)
+	room.EndsAt = copyTime(room.EndsAt)
+	room.ClosedAt = copyTime(room.ClosedAt)
+	room.ReactionKinds = cloneList(room.ReactionKinds)
+	room.Tags = cloneList(room.Tags)
+	room.TagCounts = nil
+	if room.SpotlightMessageID != nil {
+		id := *room.SpotlightMessageID
+		room.SpotlightMessageID = &id
+	}
+	return &room
+}
+
+func copyMessage(message models.Message) *models.Message {
+	message.ModerationFlags = cloneList(message.ModerationFlags)
+	message.Reactions = maps.Clone(message.Reactions)
+	return &message
+}
+
+func copyPoll(poll models.Poll) *models.Poll {
+	poll.ClosedAt = copyTime(poll.ClosedAt)
+	poll.Options = slices.Clone(poll.Options)
+	return &poll
+}
+
+func copyWebhook(webhook models.Webhook) *models.Webhook {
+	webhook.EventKinds = cloneList(webhook.EventKinds)
+	if webhook.RoomID != nil {
+		id := *webhook.RoomID
+		webhook.RoomID = &id
+	}
+	return &webhook
+}
+
+func copyDelivery(delivery models.WebhookDelivery) models.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	delivery.CompletedAt = copyTime(delivery.CompletedAt)
+	if delivery.ResponseStatus != nil {
+		status := *delivery.ResponseStatus
+		delivery.ResponseStatus = &status
+	}
+	return delivery
+}
+
+// cloneList returns an empty list for nil, the database columns are never
+// NULL.
+func cloneList(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return slices.Clone(list)
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+type MemoryWebhooksRepository struct {
+	data *memoryData
+}
+
+func (mw *MemoryWebhooksRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	if webhook.RoomID != nil {
+		if _, ok := mw.data.rooms[*webhook.RoomID]; !ok {
+			return nil, internal_errors.NewErrNotFound(ctx, "Room")
+		}
+	}
+
+	saved := copyWebhook(*webhook)
+	saved.ID = uuid.New()
+	saved.CreatedAt = mw.data.now()
+	mw.data.webhooks[saved.ID] = &memoryWebhook{webhook: *saved, seq: mw.data.nextSeq()}
+	return copyWebhook(*saved), nil
+}
+
+func (mw *MemoryWebhooksRepository) FindWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error) {
+	defer mw.data.rlock(ctx)()
+
+	webhook, ok := mw.data.webhooks[webhookId]
+	if !ok {
+		return nil, internal_errors.NewErrNotFound(ctx, "Webhook")
+	}
+	return copyWebhook(webhook.webhook), nil
+}
+
+func (mw *MemoryWebhooksRepository) FindWebhooks(ctx context.Context, roomId *uuid.UUID) ([]models.Webhook, error) {
+	defer mw.data.rlock(ctx)()
+
+	return mw.data.sortedWebhooks(func(webhook *models.Webhook) bool {
+		if roomId == nil {
+			return webhook.RoomID == nil
+		}
+		return webhook.RoomID != nil && *webhook.RoomID == *roomId
+	}), nil
+}
+
+func (mw *MemoryWebhooksRepository) FindEventWebhooks(ctx context.Context, roomId uuid.UUID) ([]models.Webhook, error) {
+	defer mw.data.rlock(ctx)()
+
+	return mw.data.sortedWebhooks(func(webhook *models.Webhook) bool {
+		return webhook.RoomID == nil || *webhook.RoomID == roomId
+	}), nil
+}
+
+func (mw *MemoryWebhooksRepository) DeleteWebhook(ctx context.Context, webhookId uuid.UUID) error {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	if _, ok := mw.data.webhooks[webhookId]; !ok {
+		return internal_errors.NewErrNotFound(ctx, "Webhook")
+	}
+	mw.data.deleteWebhook(webhookId)
+	return nil
+}
+
+func (mw *MemoryWebhooksRepository) SaveDelivery(ctx context.Context, webhookId uuid.UUID, eventKind string, payload []byte) error {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	if _, ok := mw.data.webhooks[webhookId]; !ok {
+		return internal_errors.NewErrNotFound(ctx, "Webhook")
+	}
+
+	now := mw.data.now()
+	delivery := models.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     webhookId,
+		EventKind:     eventKind,
+		Payload:       slices.Clone(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	mw.data.deliveries[delivery.ID] = &memoryWebhookDelivery{delivery: delivery, seq: mw.data.nextSeq()}
+	return nil
+}
+
+func (mw *MemoryWebhooksRepository) ClaimDueDeliveries(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.WebhookDelivery, error) {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	now := mw.data.now()
+	due := make([]*memoryWebhookDelivery, 0)
+	for _, delivery := range mw.data.deliveries {
+		if delivery.delivery.Status == models.WebhookDeliveryPending && !delivery.delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].delivery.NextAttemptAt.Equal(due[j].delivery.NextAttemptAt) {
+			return due[i].delivery.NextAttemptAt.Before(due[j].delivery.NextAttemptAt)
+		}
+		return due[i].seq < due[j].seq
+	})
+	if len(due) > int(limit) {
+		due = due[:limit]
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(due))
+	for i, delivery := range due {
+		delivery.delivery.NextAttemptAt = leaseUntil
+		deliveries[i] = copyDelivery(delivery.delivery)
+	}
+	return deliveries, nil
+}
+
+func (mw *MemoryWebhooksRepository) CompleteDelivery(ctx context.Context, deliveryId uuid.UUID, status string, responseStatus *int32, lastError string) error {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	if delivery, ok := mw.data.deliveries[deliveryId]; ok {
+		now := mw.data.now()
+		delivery.delivery.Status = status
+		delivery.delivery.Attempts++
+		delivery.delivery.ResponseStatus = copyStatus(responseStatus)
+		delivery.delivery.LastError = lastError
+		delivery.delivery.CompletedAt = &now
+	}
+	return nil
+}
+
+func (mw *MemoryWebhooksRepository) RetryDelivery(ctx context.Context, deliveryId uuid.UUID, responseStatus *int32, lastError string, nextAttemptAt time.Time) error {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	if delivery, ok := mw.data.deliveries[deliveryId]; ok {
+		delivery.delivery.Attempts++
+		delivery.delivery.ResponseStatus = copyStatus(responseStatus)
+		delivery.delivery.LastError = lastError
+		delivery.delivery.NextAttemptAt = nextAttemptAt
+	}
+	return nil
+}
+
+func (mw *MemoryWebhooksRepository) FindDeliveries(ctx context.Context, webhookId uuid.UUID, status string, limit int32) ([]models.WebhookDelivery, error) {
+	defer mw.data.rlock(ctx)()
+
+	matches := make([]*memoryWebhookDelivery, 0)
+	for _, delivery := range mw.data.deliveries {
+		if delivery.delivery.WebhookID == webhookId && (status == "" || delivery.delivery.Status == status) {
+			matches = append(matches, delivery)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].seq > matches[j].seq
+	})
+	if len(matches) > int(limit) {
+		matches = matches[:limit]
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(matches))
+	for i, delivery := range matches {
+		deliveries[i] = copyDelivery(delivery.delivery)
+	}
+	return deliveries, nil
+}
+
+func (mw *MemoryWebhooksRepository) DeleteCompletedDeliveries(ctx context.Context, completedBefore time.Time) (int64, error) {
+	defer mw.data.lock(ctx, memoryWebhooks)()
+
+	var deleted int64
+	for id, delivery := range mw.data.deliveries {
+		if delivery.delivery.CompletedAt != nil && delivery.delivery.CompletedAt.Before(completedBefore) {
+			delete(mw.data.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// sortedWebhooks returns copies of the webhooks that match in the order
+// they were created.
+func (d *memoryData) sortedWebhooks(match func(*models.Webhook) bool) []models.Webhook {
+	matches := make([]*memoryWebhook, 0)
+	for _, webhook := range d.webhooks {
+		if match(&webhook.webhook) {
+			matches = append(matches, webhook)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].seq < matches[j].seq
+	})
+
+	webhooks := make([]models.Webhook, len(matches))
+	for i, webhook := range matches {
+		webhooks[i] = *copyWebhook(webhook.webhook)
+	}
+	return webhooks
+}
+
+func copyStatus(status *int32) *int32 {
+	if status == nil {
+		return nil
+	}
+	value := *status
+	return &value
+}
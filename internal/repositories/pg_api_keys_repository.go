@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+)
+
+type PgAPIKeysRepository struct {
+	db           *pgstore.Queries
+	apiKeyMapper *mappers.APIKeyMapper
+}
+
+func NewPgAPIKeysRepository(db *pgstore.Queries, apiKeyMapper *mappers.APIKeyMapper) *PgAPIKeysRepository {
+	return &PgAPIKeysRepository{
+		db:           db,
+		apiKeyMapper: apiKeyMapper,
+	}
+}
+
+func (kr *PgAPIKeysRepository) queries(ctx context.Context) *pgstore.Queries {
+	return pgQueries(ctx, kr.db)
+}
+
+func (kr *PgAPIKeysRepository) SaveAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	saved, err := retry(ctx, func() (pgstore.ApiKey, error) {
+		return kr.queries(ctx).InsertApiKey(ctx, kr.apiKeyMapper.ToInsertParams(apiKey))
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving api key", "error", err)
+		return nil, translateError(ctx, err, "API key")
+	}
+	return kr.apiKeyMapper.ToModel(saved), nil
+}
+
+func (kr *PgAPIKeysRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	apiKey, err := kr.queries(ctx).GetApiKeyByHash(ctx, keyHash)
+	if err != nil {
+		return nil, translateError(ctx, err, "API key")
+	}
+	return kr.apiKeyMapper.ToModel(apiKey), nil
+}
+
+func (kr *PgAPIKeysRepository) FindAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	apiKeys, err := kr.queries(ctx).GetApiKeys(ctx)
+	if err != nil {
+		slog.Error("something went wrong while finding api keys", "error", err)
+		return nil, translateError(ctx, err, "API key")
+	}
+
+	modelAPIKeys := make([]models.APIKey, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		modelAPIKeys[i] = *kr.apiKeyMapper.ToModel(apiKey)
+	}
+	return modelAPIKeys, nil
+}
+
+func (kr *PgAPIKeysRepository) DeleteAPIKey(ctx context.Context, apiKeyId uuid.UUID) error {
+	deleted, err := retry(ctx, func() (int64, error) {
+		return kr.queries(ctx).DeleteApiKey(ctx, apiKeyId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting api key", "error", err)
+		return translateError(ctx, err, "API key")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "API key")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes of the errors translated below, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeCheckViolation       = "23514"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	codeQueryCanceled        = "57014"
+	codeLockNotAvailable     = "55P03"
+)
+
+const (
+	maxAttempts  = 3
+	retryBackoff = 20 * time.Millisecond
+)
+
+// foreignKeyResources names the row each foreign key points to, so a
+// violation reports the parent that is missing.
+var foreignKeyResources = map[string]string{
+	"messages_room_id_fkey":              "Room",
+	"message_reactions_message_id_fkey":  "Message",
+	"rooms_spotlight_message_id_fkey":    "Message",
+	"polls_room_id_fkey":                 "Room",
+	"poll_options_poll_id_fkey":          "Poll",
+	"poll_votes_poll_id_fkey":            "Poll",
+	"poll_votes_option_id_fkey":          "Poll option",
+	"webhooks_room_id_fkey":              "Room",
+	"webhook_deliveries_webhook_id_fkey": "Webhook",
+}
+
+var uniqueViolationTags = map[string]string{
+	"rooms_slug_key":      "ROOM_SLUG_CONFLICT",
+	"rooms_join_code_key": "ROOM_JOIN_CODE_CONFLICT",
+	"poll_votes_pkey":     "ALREADY_VOTED",
+}
+
+var checkViolationTags = map[string]string{
+	"rooms_schedule_check": "INVALID_ROOM_SCHEDULE",
+}
+
+// translateError turns a database error into the internal error the
+// controllers know how to answer with. resource names what the query was
+// looking for when it returns no rows.
+func translateError(ctx context.Context, err error, resource string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return internal_errors.NewErrNotFound(ctx, resource)
+	}
+
+	if isTimeout(err) {
+		return internal_errors.NewErrServiceUnavailable(ctx, "DATABASE_TIMEOUT")
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return internal_errors.NewErrInternal(ctx, err)
+	}
+
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		if tag, ok := uniqueViolationTags[pgErr.ConstraintName]; ok {
+			return internal_errors.NewErrConflict(ctx, tag)
+		}
+		return internal_errors.NewErrConflict(ctx, "RESOURCE_CONFLICT")
+	case codeForeignKeyViolation:
+		if parent, ok := foreignKeyResources[pgErr.ConstraintName]; ok {
+			return internal_errors.NewErrNotFound(ctx, parent)
+		}
+		return internal_errors.NewErrNotFound(ctx, resource)
+	case codeCheckViolation:
+		if tag, ok := checkViolationTags[pgErr.ConstraintName]; ok {
+			return internal_errors.NewErrBadRequest(ctx, tag)
+		}
+		return internal_errors.NewErrBadRequest(ctx, "CONSTRAINT_VIOLATION")
+	case codeSerializationFailure, codeDeadlockDetected:
+		if lostRace, ok := ctx.Value(lostRaceKey{}).(*bool); ok {
+			*lostRace = true
+		}
+		return internal_errors.NewErrServiceUnavailable(ctx, "DATABASE_CONFLICT")
+	default:
+		return internal_errors.NewErrInternal(ctx, err)
+	}
+}
+
+// isNoRows tells whether a query that returns a single row found nothing.
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == codeQueryCanceled || pgErr.Code == codeLockNotAvailable
+	}
+	return errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err)
+}
+
+// isRetryable tells whether running the statement again may succeed, which
+// is the case when it lost a race against a concurrent transaction.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == codeSerializationFailure || pgErr.Code == codeDeadlockDetected
+}
+
+// retry runs fn again when it fails with a serialization failure or a
+// deadlock, waiting a little longer before each attempt. Only statements
+// that write are retried, reads don't take part in those races.
+func retry[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	return retryWhen(ctx, isRetryable, fn)
+}
+
+// retryWhen runs fn again while it fails with an error retryable accepts.
+// Statements in a unit of work are never retried, the failure aborted the
+// transaction they belong to.
+func retryWhen[T any](ctx context.Context, retryable func(error) bool, fn func() (T, error)) (T, error) {
+	result, err := fn()
+	if inTransaction(ctx) {
+		return result, err
+	}
+	for attempt := 1; attempt < maxAttempts && retryable(err); attempt++ {
+		slog.Warn("retrying statement after it lost a race", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(retryBackoff * time.Duration(attempt)):
+		}
+
+		result, err = fn()
+	}
+	return result, err
+}
+
+func retryExec(ctx context.Context, fn func() error) error {
+	_, err := retry(ctx, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
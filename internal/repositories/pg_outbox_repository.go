@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PgOutboxRepository struct {
+	db           *pgstore.Queries
+	outboxMapper *mappers.OutboxMapper
+}
+
+func NewPgOutboxRepository(db *pgstore.Queries, outboxMapper *mappers.OutboxMapper) *PgOutboxRepository {
+	return &PgOutboxRepository{
+		db:           db,
+		outboxMapper: outboxMapper,
+	}
+}
+
+func (or *PgOutboxRepository) queries(ctx context.Context) *pgstore.Queries {
+	return pgQueries(ctx, or.db)
+}
+
+func (or *PgOutboxRepository) SaveEvent(ctx context.Context, roomId uuid.UUID, kind string, payload []byte) error {
+	err := retryExec(ctx, func() error {
+		return or.queries(ctx).InsertOutboxEvent(ctx, pgstore.InsertOutboxEventParams{
+			RoomID:  roomId,
+			Kind:    kind,
+			Payload: payload,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving outbox event", "error", err)
+		return translateError(ctx, err, "Room")
+	}
+	return nil
+}
+
+func (or *PgOutboxRepository) FindPendingEvents(ctx context.Context, limit int32) ([]models.OutboxEvent, error) {
+	events, err := or.queries(ctx).GetPendingOutboxEvents(ctx, limit)
+	if err != nil {
+		slog.Error("something went wrong while finding pending outbox events", "error", err)
+		return nil, translateError(ctx, err, "Event")
+	}
+
+	modelEvents := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		modelEvents[i] = or.outboxMapper.ToModel(event)
+	}
+	return modelEvents, nil
+}
+
+func (or *PgOutboxRepository) ClaimPendingEvents(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.OutboxEvent, error) {
+	events, err := retry(ctx, func() ([]pgstore.OutboxEvent, error) {
+		return or.queries(ctx).ClaimPendingOutboxEvents(ctx, pgstore.ClaimPendingOutboxEventsParams{
+			LeaseUntil: pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+			MaxEvents:  limit,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while claiming pending outbox events", "error", err)
+		return nil, translateError(ctx, err, "Event")
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	slices.SortFunc(events, func(a, b pgstore.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	modelEvents := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		modelEvents[i] = or.outboxMapper.ToModel(event)
+	}
+	return modelEvents, nil
+}
+
+func (or *PgOutboxRepository) MarkEventDispatched(ctx context.Context, eventId int64) error {
+	if err := retryExec(ctx, func() error {
+		return or.queries(ctx).MarkOutboxEventDispatched(ctx, eventId)
+	}); err != nil {
+		slog.Error("something went wrong while marking outbox event as dispatched", "error", err)
+		return translateError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *PgOutboxRepository) MarkEventFailed(ctx context.Context, eventId int64, handled int32) error {
+	if err := retryExec(ctx, func() error {
+		return or.queries(ctx).FailOutboxEvent(ctx, pgstore.FailOutboxEventParams{
+			Handled: handled,
+			ID:      eventId,
+		})
+	}); err != nil {
+		slog.Error("something went wrong while counting outbox event attempt", "error", err)
+		return translateError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *PgOutboxRepository) ReleaseEvents(ctx context.Context, eventIds []int64) error {
+	if err := retryExec(ctx, func() error {
+		return or.queries(ctx).ReleaseOutboxEvents(ctx, eventIds)
+	}); err != nil {
+		slog.Error("something went wrong while releasing outbox events", "error", err)
+		return translateError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *PgOutboxRepository) DeleteDispatchedEvents(ctx context.Context, dispatchedBefore time.Time) (int64, error) {
+	deleted, err := retry(ctx, func() (int64, error) {
+		return or.queries(ctx).DeleteDispatchedOutboxEvents(ctx, pgtype.Timestamptz{Time: dispatchedBefore, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting dispatched outbox events", "error", err)
+		return deleted, translateError(ctx, err, "Event")
+	}
+	return deleted, nil
+}
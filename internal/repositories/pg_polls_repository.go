@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+)
+
+type PgPollsRepository struct {
+	db         *pgstore.Queries
+	pollMapper *mappers.PollMapper
+}
+
+func NewPgPollsRepository(db *pgstore.Queries, pollMapper *mappers.PollMapper) *PgPollsRepository {
+	return &PgPollsRepository{
+		db:         db,
+		pollMapper: pollMapper,
+	}
+}
+
+func (pr *PgPollsRepository) queries(ctx context.Context) *pgstore.Queries {
+	return pgQueries(ctx, pr.db)
+}
+
+// SavePoll stores a poll together with its options in a single statement.
+func (pr *PgPollsRepository) SavePoll(ctx context.Context, roomId uuid.UUID, question string, options []string) (*models.Poll, error) {
+	pollId, err := retry(ctx, func() (uuid.UUID, error) {
+		return pr.queries(ctx).InsertPoll(ctx, pgstore.InsertPollParams{
+			RoomID:   roomId,
+			Question: question,
+			Options:  options,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving poll", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+	return pr.FindPoll(ctx, pollId)
+}
+
+func (pr *PgPollsRepository) FindPoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	poll, err := pr.queries(ctx).GetPoll(ctx, pollId)
+	if err != nil {
+		slog.Error("something went wrong while finding a poll", "error", err)
+		return pr.pollMapper.ToModel(poll, nil), translateError(ctx, err, "Poll")
+	}
+
+	options, err := pr.queries(ctx).GetPollOptions(ctx, pollId)
+	if err != nil {
+		slog.Error("something went wrong while finding poll options", "error", err)
+		return pr.pollMapper.ToModel(poll, nil), translateError(ctx, err, "Poll")
+	}
+	return pr.pollMapper.ToModel(poll, options), nil
+}
+
+func (pr *PgPollsRepository) FindAllRoomPolls(ctx context.Context, roomId uuid.UUID) ([]models.Poll, error) {
+	polls, err := pr.queries(ctx).GetRoomPolls(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding room polls", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+
+	options, err := pr.queries(ctx).GetRoomPollOptions(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding room poll options", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+
+	pollOptions := make(map[uuid.UUID][]pgstore.PollOption, len(polls))
+	for _, option := range options {
+		pollOptions[option.PollID] = append(pollOptions[option.PollID], option)
+	}
+
+	modelPolls := make([]models.Poll, len(polls))
+	for i, poll := range polls {
+		modelPolls[i] = *pr.pollMapper.ToModel(poll, pollOptions[poll.ID])
+	}
+	return modelPolls, nil
+}
+
+// SaveVote counts the vote of a participant. It returns false when the
+// participant already voted in the poll.
+func (pr *PgPollsRepository) SaveVote(ctx context.Context, pollId uuid.UUID, optionId uuid.UUID, participantId string) (bool, error) {
+	counted, err := retry(ctx, func() (int64, error) {
+		return pr.queries(ctx).InsertPollVote(ctx, pgstore.InsertPollVoteParams{
+			PollID:        pollId,
+			ParticipantID: participantId,
+			OptionID:      optionId,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving poll vote", "error", err)
+		return false, translateError(ctx, err, "Poll")
+	}
+	return counted > 0, nil
+}
+
+func (pr *PgPollsRepository) ClosePoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	_, err := retry(ctx, func() (pgstore.Poll, error) {
+		return pr.queries(ctx).ClosePoll(ctx, pollId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing poll", "error", err)
+		return nil, translateError(ctx, err, "Poll")
+	}
+	return pr.FindPoll(ctx, pollId)
+}
@@ -0,0 +1,553 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PgRoomsRepository struct {
+	db            *pgstore.Queries
+	roomMapper    *mappers.RoomMapper
+	messageMapper *mappers.MessageMapper
+}
+
+func NewPgRoomsRepository(db *pgstore.Queries, roomMapper *mappers.RoomMapper,
+	messageMapper *mappers.MessageMapper) *PgRoomsRepository {
+	return &PgRoomsRepository{
+		db:            db,
+		roomMapper:    roomMapper,
+		messageMapper: messageMapper,
+	}
+}
+
+// queries runs the statements in the unit of work the context carries.
+func (rr *PgRoomsRepository) queries(ctx context.Context) *pgstore.Queries {
+	return pgQueries(ctx, rr.db)
+}
+
+func (rr *PgRoomsRepository) CloseEndedRooms(ctx context.Context, endedBefore time.Time) ([]uuid.UUID, error) {
+	closed, err := retry(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).CloseEndedRooms(ctx, pgtype.Timestamptz{Time: endedBefore, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing ended rooms", "error", err)
+		return closed, translateError(ctx, err, "Room")
+	}
+	return closed, err
+}
+
+func (rr *PgRoomsRepository) CloseInactiveRooms(ctx context.Context, inactiveSince time.Time) ([]uuid.UUID, error) {
+	closed, err := retry(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).CloseInactiveRooms(ctx, pgtype.Timestamptz{Time: inactiveSince, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing inactive rooms", "error", err)
+		return closed, translateError(ctx, err, "Room")
+	}
+	return closed, err
+}
+
+func (rr *PgRoomsRepository) DeleteArchivedRooms(ctx context.Context, closedBefore time.Time) ([]uuid.UUID, error) {
+	deleted, err := retry(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).DeleteArchivedRooms(ctx, pgtype.Timestamptz{Time: closedBefore, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting archived rooms", "error", err)
+		return deleted, translateError(ctx, err, "Room")
+	}
+	return deleted, err
+}
+
+func (rr *PgRoomsRepository) AnonymizeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error) {
+	anonymized, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).AnonymizeArchivedRooms(ctx, pgtype.Timestamptz{Time: closedBefore, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while anonymizing archived rooms", "error", err)
+		return anonymized, translateError(ctx, err, "Room")
+	}
+	return anonymized, err
+}
+
+func (rr *PgRoomsRepository) CloseRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	closedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).CloseRoom(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing room", "error", err)
+		return rr.roomMapper.ToModel(closedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(closedRoom), err
+}
+
+func (rr *PgRoomsRepository) ReopenRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	reopenedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).ReopenRoom(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while reopening room", "error", err)
+		return rr.roomMapper.ToModel(reopenedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(reopenedRoom), err
+}
+
+func (rr *PgRoomsRepository) FindMessage(ctx context.Context, messageId uuid.UUID) (*models.Message, error) {
+	message, err := rr.queries(ctx).GetMessage(ctx, messageId)
+	if err != nil {
+		slog.Error("something went wrong while finding a message", "error", err)
+		return rr.messageMapper.ToModel(message), translateError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.ToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *PgRoomsRepository) FindRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := rr.queries(ctx).GetRoom(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding a room", "error", err)
+		return rr.roomMapper.ToModel(room), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(room), err
+}
+
+func (rr *PgRoomsRepository) LockRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := rr.queries(ctx).LockRoom(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while locking a room", "error", err)
+		return rr.roomMapper.ToModel(room), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(room), err
+}
+
+func (rr *PgRoomsRepository) FindRoomByJoinCode(ctx context.Context, code string) (*models.Room, error) {
+	room, err := rr.queries(ctx).GetRoomByJoinCode(ctx, code)
+	if err != nil {
+		slog.Error("something went wrong while finding a room by join code", "error", err)
+		return rr.roomMapper.ToModel(room), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(room), err
+}
+
+func (rr *PgRoomsRepository) FindAllRooms(ctx context.Context) ([]models.Room, error) {
+	rooms, err := rr.queries(ctx).GetRooms(ctx)
+	modelRooms := make([]models.Room, len(rooms))
+
+	for i, room := range rooms {
+		modelRoom := rr.roomMapper.ToModel(room)
+		modelRooms[i] = *modelRoom
+	}
+
+	if err != nil {
+		slog.Error("something went wrong while finding all rooms", "error", err)
+		return modelRooms, translateError(ctx, err, "Room")
+	}
+
+	return modelRooms, err
+}
+
+func (rr *PgRoomsRepository) SearchRooms(ctx context.Context, query string, joinCode string) ([]models.Room, error) {
+	rooms, err := rr.queries(ctx).SearchRooms(ctx, pgstore.SearchRoomsParams{
+		Query:    query,
+		JoinCode: joinCode,
+	})
+	if err != nil {
+		slog.Error("something went wrong while searching rooms", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+
+	modelRooms := make([]models.Room, len(rooms))
+	for i, room := range rooms {
+		modelRooms[i] = *rr.roomMapper.ToModel(room)
+	}
+	return modelRooms, nil
+}
+
+// FindAllRoomMessages finds the messages of a room, only the ones with the
+// given tag unless it is empty.
+func (rr *PgRoomsRepository) FindAllRoomMessages(ctx context.Context, roomID uuid.UUID, tag string) ([]models.Message, error) {
+	var messages []pgstore.Message
+	var err error
+	if tag == "" {
+		messages, err = rr.queries(ctx).GetRoomMessages(ctx, roomID)
+	} else {
+		messages, err = rr.queries(ctx).GetRoomMessagesByTag(ctx, pgstore.GetRoomMessagesByTagParams{
+			RoomID: roomID,
+			Tag:    tag,
+		})
+	}
+	modelMessages := make([]models.Message, len(messages))
+
+	for i, message := range messages {
+		modelMessage := rr.messageMapper.ToModel(message)
+		modelMessages[i] = *modelMessage
+	}
+
+	if err != nil {
+		slog.Error("something went wrong while finding all room messages", "error", err)
+		return modelMessages, translateError(ctx, err, "Room")
+	}
+
+	reactions, err := rr.queries(ctx).GetRoomMessageReactions(ctx, roomID)
+	if err != nil {
+		slog.Error("something went wrong while finding room message reactions", "error", err)
+		return modelMessages, translateError(ctx, err, "Room")
+	}
+
+	indexes := make(map[uuid.UUID]int, len(modelMessages))
+	for i, message := range modelMessages {
+		indexes[message.ID] = i
+	}
+	for _, reaction := range reactions {
+		i, ok := indexes[reaction.MessageID]
+		if !ok {
+			continue
+		}
+		message := &modelMessages[i]
+		if message.Reactions == nil {
+			message.Reactions = make(map[string]int64)
+		}
+		message.Reactions[reaction.Kind] = reaction.Count
+	}
+	return modelMessages, nil
+}
+
+// StreamRoomMessages reads the messages of a room a page at a time, so the
+// messages of a large room aren't all held at once.
+func (rr *PgRoomsRepository) StreamRoomMessages(ctx context.Context, roomID uuid.UUID, fn func(*models.Message) error) error {
+	reactions, err := rr.queries(ctx).GetRoomMessageReactions(ctx, roomID)
+	if err != nil {
+		slog.Error("something went wrong while finding room message reactions", "error", err)
+		return translateError(ctx, err, "Room")
+	}
+	// a room has a handful of reactions per message at most, they are read
+	// upfront rather than with every page
+	messageReactions := make(map[uuid.UUID]map[string]int64)
+	for _, reaction := range reactions {
+		if messageReactions[reaction.MessageID] == nil {
+			messageReactions[reaction.MessageID] = make(map[string]int64)
+		}
+		messageReactions[reaction.MessageID][reaction.Kind] = reaction.Count
+	}
+
+	params := pgstore.GetRoomMessagesPageParams{
+		RoomID:         roomID,
+		AfterCreatedAt: pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true},
+		PageSize:       streamPageSize,
+	}
+	for {
+		messages, err := rr.queries(ctx).GetRoomMessagesPage(ctx, params)
+		if err != nil {
+			slog.Error("something went wrong while streaming room messages", "error", err)
+			return translateError(ctx, err, "Room")
+		}
+		for _, message := range messages {
+			modelMessage := rr.messageMapper.ToModel(message)
+			modelMessage.Reactions = messageReactions[message.ID]
+			if err := fn(modelMessage); err != nil {
+				return err
+			}
+		}
+		if len(messages) < streamPageSize {
+			return nil
+		}
+		last := messages[len(messages)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+func (rr *PgRoomsRepository) SaveRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	savedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).InsertRoom(ctx, rr.roomMapper.ToInsertParams(room))
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving room", "error", err)
+		return rr.roomMapper.ToModel(savedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(savedRoom), err
+}
+
+// FindRoomsTagCounts counts the messages per tag of each of the given rooms.
+func (rr *PgRoomsRepository) FindRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	rows, err := rr.queries(ctx).GetRoomsTagCounts(ctx, roomIds)
+	if err != nil {
+		slog.Error("something went wrong while counting room tags", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+
+	counts := make(map[uuid.UUID]map[string]int64, len(roomIds))
+	for _, row := range rows {
+		if counts[row.RoomID] == nil {
+			counts[row.RoomID] = make(map[string]int64)
+		}
+		counts[row.RoomID][row.Tag] = row.MessagesCount
+	}
+	return counts, nil
+}
+
+func (rr *PgRoomsRepository) IsRoomSlugTaken(ctx context.Context, slug string, roomId uuid.UUID) (bool, error) {
+	taken, err := rr.queries(ctx).IsRoomSlugTaken(ctx, pgstore.IsRoomSlugTakenParams{
+		Slug: slug,
+		ID:   roomId,
+	})
+	if err != nil {
+		slog.Error("something went wrong while checking room slug", "error", err)
+		return taken, translateError(ctx, err, "Room")
+	}
+	return taken, err
+}
+
+func (rr *PgRoomsRepository) FindRoomSlugs(ctx context.Context, slug string, roomId uuid.UUID) ([]string, error) {
+	slugs, err := rr.queries(ctx).GetRoomSlugs(ctx, pgstore.GetRoomSlugsParams{
+		Slug: slug,
+		ID:   roomId,
+	})
+	if err != nil {
+		slog.Error("something went wrong while finding room slugs", "error", err)
+		return slugs, translateError(ctx, err, "Room")
+	}
+	return slugs, err
+}
+
+func (rr *PgRoomsRepository) UpdateRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	updatedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).UpdateRoom(ctx, rr.roomMapper.ToUpdateParams(room))
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room", "error", err)
+		return rr.roomMapper.ToModel(updatedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(updatedRoom), err
+}
+
+func (rr *PgRoomsRepository) UpdateRoomJoinCode(ctx context.Context, roomId uuid.UUID, code string) (*models.Room, error) {
+	updatedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).UpdateRoomJoinCode(ctx, pgstore.UpdateRoomJoinCodeParams{
+			ID:       roomId,
+			JoinCode: code,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room join code", "error", err)
+		return rr.roomMapper.ToModel(updatedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(updatedRoom), err
+}
+
+// UpdateRoomSpotlight puts a message in the spotlight of a room, a nil
+// messageId clears it.
+func (rr *PgRoomsRepository) UpdateRoomSpotlight(ctx context.Context, roomId uuid.UUID, messageId *uuid.UUID) (*models.Room, error) {
+	spotlight := pgtype.UUID{}
+	if messageId != nil {
+		spotlight = pgtype.UUID{Bytes: *messageId, Valid: true}
+	}
+
+	updatedRoom, err := retry(ctx, func() (pgstore.Room, error) {
+		return rr.queries(ctx).UpdateRoomSpotlight(ctx, pgstore.UpdateRoomSpotlightParams{
+			ID:                 roomId,
+			SpotlightMessageID: spotlight,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room spotlight", "error", err)
+		return rr.roomMapper.ToModel(updatedRoom), translateError(ctx, err, "Room")
+	}
+	return rr.roomMapper.ToModel(updatedRoom), err
+}
+
+func (rr *PgRoomsRepository) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
+	deleted, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).DeleteRoom(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting room", "error", err)
+		return translateError(ctx, err, "Room")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	return nil
+}
+
+func (rr *PgRoomsRepository) SaveMessage(ctx context.Context, params *request.MessageRequest, moderationFlags []string) (uuid.UUID, error) {
+	messageId, err := retry(ctx, func() (uuid.UUID, error) {
+		return rr.queries(ctx).InsertMessage(ctx, pgstore.InsertMessageParams{
+			RoomID:          params.RoomID,
+			Message:         params.Message,
+			Tag:             params.Tag,
+			ModerationFlags: moderationFlags,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving message", "error", err)
+		return messageId, translateError(ctx, err, "Room")
+	}
+	return messageId, err
+}
+
+// SaveMessages saves messages[i] with tags[i] and moderationFlags[i] in a
+// single statement.
+func (rr *PgRoomsRepository) SaveMessages(ctx context.Context, roomId uuid.UUID, messages []string, tags []string, moderationFlags [][]string) ([]uuid.UUID, error) {
+	// multidimensional arrays need rows of the same length, so the flags of
+	// each message travel as a comma separated list
+	joinedFlags := make([]string, len(moderationFlags))
+	for i, flags := range moderationFlags {
+		joinedFlags[i] = strings.Join(flags, ",")
+	}
+
+	rows, err := retry(ctx, func() ([]pgstore.InsertMessagesRow, error) {
+		return rr.queries(ctx).InsertMessages(ctx, pgstore.InsertMessagesParams{
+			RoomID:          roomId,
+			Messages:        messages,
+			Tags:            tags,
+			ModerationFlags: joinedFlags,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving messages", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+
+	// RETURNING doesn't promise the order of the input, each id comes with
+	// the position of its message instead
+	messageIds := make([]uuid.UUID, len(messages))
+	for _, row := range rows {
+		messageIds[row.Position-1] = row.ID
+	}
+	return messageIds, nil
+}
+
+func (rr *PgRoomsRepository) ReactToMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	count, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).IncrementMessageReaction(ctx, pgstore.IncrementMessageReactionParams{
+			MessageID: messageId,
+			Kind:      kind,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while adding message reaction", "error", err)
+		return count, translateError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *PgRoomsRepository) RemoveReactionFromMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	count, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).DecrementMessageReaction(ctx, pgstore.DecrementMessageReactionParams{
+			MessageID: messageId,
+			Kind:      kind,
+		})
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return 0, nil
+		}
+
+		slog.Error("something went wrong while removing message reaction", "error", err)
+		return count, translateError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *PgRoomsRepository) DownvoteMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	count, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).IncrementMessageDownvotes(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while downvoting message", "error", err)
+		return count, translateError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *PgRoomsRepository) RemoveDownvoteFromMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	count, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).DecrementMessageDownvotes(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while removing message downvote", "error", err)
+		return count, translateError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *PgRoomsRepository) RecountMessageLikes(ctx context.Context, roomId uuid.UUID) (int64, error) {
+	recounted, err := retry(ctx, func() (int64, error) {
+		return rr.queries(ctx).RecountRoomMessageLikes(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while recounting message likes", "error", err)
+		return recounted, translateError(ctx, err, "Room")
+	}
+	return recounted, err
+}
+
+func (rr *PgRoomsRepository) FindMessageReactions(ctx context.Context, messageId uuid.UUID) (map[string]int64, error) {
+	reactions, err := rr.queries(ctx).GetMessageReactions(ctx, messageId)
+	if err != nil {
+		slog.Error("something went wrong while finding message reactions", "error", err)
+		return nil, translateError(ctx, err, "Message")
+	}
+
+	counts := make(map[string]int64, len(reactions))
+	for _, reaction := range reactions {
+		counts[reaction.Kind] = reaction.Count
+	}
+	return counts, nil
+}
+
+func (rr *PgRoomsRepository) SetMessagePinned(ctx context.Context, messageId uuid.UUID, pinned bool) (*models.Message, error) {
+	message, err := retry(ctx, func() (pgstore.Message, error) {
+		return rr.queries(ctx).SetMessagePinned(ctx, pgstore.SetMessagePinnedParams{
+			ID:     messageId,
+			Pinned: pinned,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while pinning message", "error", err)
+		return rr.messageMapper.ToModel(message), translateError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.ToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *PgRoomsRepository) SetMessageHidden(ctx context.Context, messageId uuid.UUID, hidden bool) (*models.Message, error) {
+	message, err := retry(ctx, func() (pgstore.Message, error) {
+		return rr.queries(ctx).SetMessageHidden(ctx, pgstore.SetMessageHiddenParams{
+			ID:     messageId,
+			Hidden: hidden,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while hiding message", "error", err)
+		return rr.messageMapper.ToModel(message), translateError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.ToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *PgRoomsRepository) MarkMessageAsAnswered(ctx context.Context, messageId uuid.UUID) error {
+	err := retryExec(ctx, func() error {
+		return rr.queries(ctx).MarkMessageAsAnswered(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while marking message as answered", "error", err)
+		return translateError(ctx, err, "Message")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PgWebhooksRepository struct {
+	db            *pgstore.Queries
+	webhookMapper *mappers.WebhookMapper
+}
+
+func NewPgWebhooksRepository(db *pgstore.Queries, webhookMapper *mappers.WebhookMapper) *PgWebhooksRepository {
+	return &PgWebhooksRepository{
+		db:            db,
+		webhookMapper: webhookMapper,
+	}
+}
+
+func (wr *PgWebhooksRepository) queries(ctx context.Context) *pgstore.Queries {
+	return pgQueries(ctx, wr.db)
+}
+
+func (wr *PgWebhooksRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	saved, err := retry(ctx, func() (pgstore.Webhook, error) {
+		return wr.queries(ctx).InsertWebhook(ctx, wr.webhookMapper.ToInsertParams(webhook))
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving webhook", "error", err)
+		return nil, translateError(ctx, err, "Room")
+	}
+	return wr.webhookMapper.ToModel(saved), nil
+}
+
+func (wr *PgWebhooksRepository) FindWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error) {
+	webhook, err := wr.queries(ctx).GetWebhook(ctx, webhookId)
+	if err != nil {
+		slog.Error("something went wrong while finding a webhook", "error", err)
+		return nil, translateError(ctx, err, "Webhook")
+	}
+	return wr.webhookMapper.ToModel(webhook), nil
+}
+
+func (wr *PgWebhooksRepository) FindWebhooks(ctx context.Context, roomId *uuid.UUID) ([]models.Webhook, error) {
+	var webhooks []pgstore.Webhook
+	var err error
+	if roomId == nil {
+		webhooks, err = wr.queries(ctx).GetGlobalWebhooks(ctx)
+	} else {
+		webhooks, err = wr.queries(ctx).GetRoomWebhooks(ctx, pgtype.UUID{Bytes: *roomId, Valid: true})
+	}
+	if err != nil {
+		slog.Error("something went wrong while finding webhooks", "error", err)
+		return nil, translateError(ctx, err, "Webhook")
+	}
+	return wr.toModels(webhooks), nil
+}
+
+func (wr *PgWebhooksRepository) FindEventWebhooks(ctx context.Context, roomId uuid.UUID) ([]models.Webhook, error) {
+	webhooks, err := wr.queries(ctx).GetEventWebhooks(ctx, pgtype.UUID{Bytes: roomId, Valid: true})
+	if err != nil {
+		slog.Error("something went wrong while finding event webhooks", "error", err)
+		return nil, translateError(ctx, err, "Webhook")
+	}
+	return wr.toModels(webhooks), nil
+}
+
+func (wr *PgWebhooksRepository) DeleteWebhook(ctx context.Context, webhookId uuid.UUID) error {
+	deleted, err := retry(ctx, func() (int64, error) {
+		return wr.queries(ctx).DeleteWebhook(ctx, webhookId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting webhook", "error", err)
+		return translateError(ctx, err, "Webhook")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "Webhook")
+	}
+	return nil
+}
+
+func (wr *PgWebhooksRepository) SaveDelivery(ctx context.Context, webhookId uuid.UUID, eventKind string, payload []byte) error {
+	err := retryExec(ctx, func() error {
+		return wr.queries(ctx).InsertWebhookDelivery(ctx, pgstore.InsertWebhookDeliveryParams{
+			WebhookID: webhookId,
+			EventKind: eventKind,
+			Payload:   payload,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving webhook delivery", "error", err)
+		return translateError(ctx, err, "Webhook")
+	}
+	return nil
+}
+
+func (wr *PgWebhooksRepository) ClaimDueDeliveries(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.WebhookDelivery, error) {
+	deliveries, err := retry(ctx, func() ([]pgstore.WebhookDelivery, error) {
+		return wr.queries(ctx).ClaimDueWebhookDeliveries(ctx, pgstore.ClaimDueWebhookDeliveriesParams{
+			LeaseUntil:    pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+			MaxDeliveries: limit,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while claiming webhook deliveries", "error", err)
+		return nil, translateError(ctx, err, "Webhook delivery")
+	}
+	return wr.toDeliveryModels(deliveries), nil
+}
+
+func (wr *PgWebhooksRepository) CompleteDelivery(ctx context.Context, deliveryId uuid.UUID, status string, responseStatus *int32, lastError string) error {
+	err := retryExec(ctx, func() error {
+		return wr.queries(ctx).CompleteWebhookDelivery(ctx, pgstore.CompleteWebhookDeliveryParams{
+			ID:             deliveryId,
+			Status:         status,
+			ResponseStatus: pgInt4(responseStatus),
+			LastError:      lastError,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while completing webhook delivery", "error", err)
+		return translateError(ctx, err, "Webhook delivery")
+	}
+	return nil
+}
+
+func (wr *PgWebhooksRepository) RetryDelivery(ctx context.Context, deliveryId uuid.UUID, responseStatus *int32, lastError string, nextAttemptAt time.Time) error {
+	err := retryExec(ctx, func() error {
+		return wr.queries(ctx).RetryWebhookDelivery(ctx, pgstore.RetryWebhookDeliveryParams{
+			ID:             deliveryId,
+			ResponseStatus: pgInt4(responseStatus),
+			LastError:      lastError,
+			NextAttemptAt:  pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while rescheduling webhook delivery", "error", err)
+		return translateError(ctx, err, "Webhook delivery")
+	}
+	return nil
+}
+
+func (wr *PgWebhooksRepository) FindDeliveries(ctx context.Context, webhookId uuid.UUID, status string, limit int32) ([]models.WebhookDelivery, error) {
+	deliveries, err := wr.queries(ctx).GetWebhookDeliveries(ctx, pgstore.GetWebhookDeliveriesParams{
+		WebhookID:     webhookId,
+		Status:        status,
+		MaxDeliveries: limit,
+	})
+	if err != nil {
+		slog.Error("something went wrong while finding webhook deliveries", "error", err)
+		return nil, translateError(ctx, err, "Webhook")
+	}
+	return wr.toDeliveryModels(deliveries), nil
+}
+
+func (wr *PgWebhooksRepository) DeleteCompletedDeliveries(ctx context.Context, completedBefore time.Time) (int64, error) {
+	deleted, err := retry(ctx, func() (int64, error) {
+		return wr.queries(ctx).DeleteCompletedWebhookDeliveries(ctx, pgtype.Timestamptz{Time: completedBefore, Valid: true})
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting completed webhook deliveries", "error", err)
+		return deleted, translateError(ctx, err, "Webhook delivery")
+	}
+	return deleted, nil
+}
+
+func (wr *PgWebhooksRepository) toModels(webhooks []pgstore.Webhook) []models.Webhook {
+	modelWebhooks := make([]models.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		modelWebhooks[i] = *wr.webhookMapper.ToModel(webhook)
+	}
+	return modelWebhooks
+}
+
+func (wr *PgWebhooksRepository) toDeliveryModels(deliveries []pgstore.WebhookDelivery) []models.WebhookDelivery {
+	modelDeliveries := make([]models.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		modelDeliveries[i] = wr.webhookMapper.DeliveryToModel(delivery)
+	}
+	return modelDeliveries
+}
+
+func pgInt4(value *int32) pgtype.Int4 {
+	if value == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *value, Valid: true}
+}
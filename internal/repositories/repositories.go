@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// streamPageSize is how many messages StreamRoomMessages reads per query.
+const streamPageSize = 500
+
+// RoomsRepository stores rooms, their messages and the reactions to them.
+// Implementations return the internal_errors the controllers answer with,
+// a missing row is an ErrorNotFound.
+type RoomsRepository interface {
+	FindRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error)
+	// LockRoom finds a room and keeps it from being updated or deleted
+	// until the unit of work ctx belongs to ends.
+	LockRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error)
+	FindRoomByJoinCode(ctx context.Context, code string) (*models.Room, error)
+	FindAllRooms(ctx context.Context) ([]models.Room, error)
+	FindRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) (map[uuid.UUID]map[string]int64, error)
+	IsRoomSlugTaken(ctx context.Context, slug string, roomId uuid.UUID) (bool, error)
+	// FindRoomSlugs returns slug and the slugs made of it and a suffix,
+	// e.g. "intro-2", that rooms other than roomId use
+	FindRoomSlugs(ctx context.Context, slug string, roomId uuid.UUID) ([]string, error)
+	SaveRoom(ctx context.Context, room *models.Room) (*models.Room, error)
+	UpdateRoom(ctx context.Context, room *models.Room) (*models.Room, error)
+	UpdateRoomJoinCode(ctx context.Context, roomId uuid.UUID, code string) (*models.Room, error)
+	UpdateRoomSpotlight(ctx context.Context, roomId uuid.UUID, messageId *uuid.UUID) (*models.Room, error)
+	DeleteRoom(ctx context.Context, roomId uuid.UUID) error
+
+	// the close methods return the ids of the rooms they closed
+	CloseEndedRooms(ctx context.Context, endedBefore time.Time) ([]uuid.UUID, error)
+	// CloseInactiveRooms counts the idle time of a room from its start,
+	// scheduled rooms that haven't started are left open
+	CloseInactiveRooms(ctx context.Context, inactiveSince time.Time) ([]uuid.UUID, error)
+	// DeleteArchivedRooms returns the ids of the rooms it deleted
+	DeleteArchivedRooms(ctx context.Context, closedBefore time.Time) ([]uuid.UUID, error)
+	AnonymizeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error)
+	// CloseRoom keeps the time a closed room was closed at
+	CloseRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error)
+	ReopenRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error)
+	// SearchRooms finds the rooms, private ones included, whose subject,
+	// slug or host name contains query, ignoring case, or whose join code
+	// is joinCode. An empty query finds every room.
+	SearchRooms(ctx context.Context, query string, joinCode string) ([]models.Room, error)
+
+	FindMessage(ctx context.Context, messageId uuid.UUID) (*models.Message, error)
+	FindAllRoomMessages(ctx context.Context, roomID uuid.UUID, tag string) ([]models.Message, error)
+	// StreamRoomMessages hands fn the messages of a room with their
+	// reactions, in the order they were created
+	StreamRoomMessages(ctx context.Context, roomID uuid.UUID, fn func(*models.Message) error) error
+	SaveMessage(ctx context.Context, params *request.MessageRequest, moderationFlags []string) (uuid.UUID, error)
+	SaveMessages(ctx context.Context, roomId uuid.UUID, messages []string, tags []string, moderationFlags [][]string) ([]uuid.UUID, error)
+	SetMessagePinned(ctx context.Context, messageId uuid.UUID, pinned bool) (*models.Message, error)
+	SetMessageHidden(ctx context.Context, messageId uuid.UUID, hidden bool) (*models.Message, error)
+	MarkMessageAsAnswered(ctx context.Context, messageId uuid.UUID) error
+
+	FindMessageReactions(ctx context.Context, messageId uuid.UUID) (map[string]int64, error)
+	ReactToMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error)
+	RemoveReactionFromMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error)
+	DownvoteMessage(ctx context.Context, messageId uuid.UUID) (int64, error)
+	RemoveDownvoteFromMessage(ctx context.Context, messageId uuid.UUID) (int64, error)
+	// RecountMessageLikes sets the likes_count of the messages of a room
+	// back to their like reactions and returns how many were off.
+	RecountMessageLikes(ctx context.Context, roomId uuid.UUID) (int64, error)
+}
+
+// PollsRepository stores the polls of the rooms and the votes they got.
+type PollsRepository interface {
+	SavePoll(ctx context.Context, roomId uuid.UUID, question string, options []string) (*models.Poll, error)
+	FindPoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error)
+	FindAllRoomPolls(ctx context.Context, roomId uuid.UUID) ([]models.Poll, error)
+	SaveVote(ctx context.Context, pollId uuid.UUID, optionId uuid.UUID, participantId string) (bool, error)
+	ClosePoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error)
+}
+
+// OutboxRepository stores the events of the rooms until they are delivered.
+// SaveEvent takes part in the unit of work of ctx, so an event is only
+// stored when the change it describes is committed.
+type OutboxRepository interface {
+	SaveEvent(ctx context.Context, roomId uuid.UUID, kind string, payload []byte) error
+	// FindPendingEvents returns the oldest events that weren't dispatched
+	// yet, in the order they were saved.
+	FindPendingEvents(ctx context.Context, limit int32) ([]models.OutboxEvent, error)
+	// ClaimPendingEvents is FindPendingEvents for the dispatcher. It leaves
+	// out the events claimed by someone else and claims the ones it returns
+	// until leaseUntil, so they aren't handed over twice at once.
+	ClaimPendingEvents(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.OutboxEvent, error)
+	MarkEventDispatched(ctx context.Context, eventId int64) error
+	// MarkEventFailed counts a failed attempt of the subscriber after the
+	// handled ones and releases the event.
+	MarkEventFailed(ctx context.Context, eventId int64, handled int32) error
+	// ReleaseEvents gives up the claim on events that weren't attempted.
+	ReleaseEvents(ctx context.Context, eventIds []int64) error
+	DeleteDispatchedEvents(ctx context.Context, dispatchedBefore time.Time) (int64, error)
+}
+
+// WebhooksRepository stores the webhooks and the log of their deliveries.
+// The webhooks of a room are deleted along with it.
+type WebhooksRepository interface {
+	SaveWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	FindWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error)
+	// FindWebhooks returns the webhooks of a room, or the global ones for a
+	// nil roomId, in the order they were created.
+	FindWebhooks(ctx context.Context, roomId *uuid.UUID) ([]models.Webhook, error)
+	// FindEventWebhooks returns the webhooks that may get an event of a
+	// room: its own and the global ones.
+	FindEventWebhooks(ctx context.Context, roomId uuid.UUID) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookId uuid.UUID) error
+
+	// SaveDelivery adds a delivery that is due right away.
+	SaveDelivery(ctx context.Context, webhookId uuid.UUID, eventKind string, payload []byte) error
+	// ClaimDueDeliveries returns the pending deliveries that are due and
+	// pushes them back to leaseUntil, so no one else attempts them in the
+	// meantime. A delivery whose attempt was never recorded is due again
+	// once the lease is over.
+	ClaimDueDeliveries(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.WebhookDelivery, error)
+	// CompleteDelivery records the last attempt of a delivery, which ends
+	// as delivered or failed.
+	CompleteDelivery(ctx context.Context, deliveryId uuid.UUID, status string, responseStatus *int32, lastError string) error
+	RetryDelivery(ctx context.Context, deliveryId uuid.UUID, responseStatus *int32, lastError string, nextAttemptAt time.Time) error
+	// FindDeliveries returns the latest deliveries of a webhook first. An
+	// empty status selects every status.
+	FindDeliveries(ctx context.Context, webhookId uuid.UUID, status string, limit int32) ([]models.WebhookDelivery, error)
+	DeleteCompletedDeliveries(ctx context.Context, completedBefore time.Time) (int64, error)
+}
+
+// APIKeysRepository stores the API keys, found by the hash of the key.
+type APIKeysRepository interface {
+	SaveAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error)
+	FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	FindAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, apiKeyId uuid.UUID) error
+}
+
+// Store groups the repositories of one storage backend with the units of
+// work that span them.
+type Store struct {
+	Rooms      RoomsRepository
+	Polls      PollsRepository
+	Outbox     OutboxRepository
+	Webhooks   WebhooksRepository
+	APIKeys    APIKeysRepository
+	UnitOfWork UnitOfWork
+}
+
+func NewPgStore(pool *pgxpool.Pool) Store {
+	db := pgstore.New(pool)
+	return Store{
+		Rooms:      NewPgRoomsRepository(db, &mappers.RoomMapper{}, &mappers.MessageMapper{}),
+		Polls:      NewPgPollsRepository(db, &mappers.PollMapper{}),
+		Outbox:     NewPgOutboxRepository(db, &mappers.OutboxMapper{}),
+		Webhooks:   NewPgWebhooksRepository(db, &mappers.WebhookMapper{}),
+		APIKeys:    NewPgAPIKeysRepository(db, &mappers.APIKeyMapper{}),
+		UnitOfWork: NewPgUnitOfWork(pool),
+	}
+}
+
+// NewSQLiteStore expects a database opened with sqlitestore.Open and
+// migrated with sqlitestore.Migrate.
+func NewSQLiteStore(db *sql.DB) Store {
+	return Store{
+		Rooms:      NewSQLiteRoomsRepository(db, &mappers.RoomMapper{}, &mappers.MessageMapper{}),
+		Polls:      NewSQLitePollsRepository(db, &mappers.PollMapper{}),
+		Outbox:     NewSQLiteOutboxRepository(db, &mappers.OutboxMapper{}),
+		Webhooks:   NewSQLiteWebhooksRepository(db, &mappers.WebhookMapper{}),
+		APIKeys:    NewSQLiteAPIKeysRepository(db, &mappers.APIKeyMapper{}),
+		UnitOfWork: NewSQLiteUnitOfWork(db),
+	}
+}
+
+func NewMemoryStore() Store {
+	data := newMemoryData()
+	return Store{
+		Rooms:      &MemoryRoomsRepository{data: data},
+		Polls:      &MemoryPollsRepository{data: data},
+		Outbox:     &MemoryOutboxRepository{data: data},
+		Webhooks:   &MemoryWebhooksRepository{data: data},
+		APIKeys:    &MemoryAPIKeysRepository{data: data},
+		UnitOfWork: &MemoryUnitOfWork{data: data},
+	}
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+type SQLiteAPIKeysRepository struct {
+	db           *sqlitestore.Queries
+	apiKeyMapper *mappers.APIKeyMapper
+}
+
+func NewSQLiteAPIKeysRepository(conn *sql.DB, apiKeyMapper *mappers.APIKeyMapper) *SQLiteAPIKeysRepository {
+	return &SQLiteAPIKeysRepository{
+		db:           sqlitestore.New(conn),
+		apiKeyMapper: apiKeyMapper,
+	}
+}
+
+func (kr *SQLiteAPIKeysRepository) queries(ctx context.Context) *sqlitestore.Queries {
+	return sqliteQueries(ctx, kr.db)
+}
+
+func (kr *SQLiteAPIKeysRepository) SaveAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	params := kr.apiKeyMapper.ToSQLiteInsertParams(apiKey)
+	params.ID = uuid.New()
+	params.CreatedAt = time.Now().UnixMicro()
+
+	err := retrySQLiteExec(ctx, func() error {
+		return kr.queries(ctx).InsertApiKey(ctx, params)
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving api key", "error", err)
+		return nil, translateSQLiteError(ctx, err, "API key")
+	}
+	return kr.FindAPIKeyByHash(ctx, params.KeyHash)
+}
+
+func (kr *SQLiteAPIKeysRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	apiKey, err := kr.queries(ctx).GetApiKeyByHash(ctx, keyHash)
+	if err != nil {
+		return nil, translateSQLiteError(ctx, err, "API key")
+	}
+	return kr.apiKeyMapper.SQLiteToModel(apiKey), nil
+}
+
+func (kr *SQLiteAPIKeysRepository) FindAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	apiKeys, err := kr.queries(ctx).GetApiKeys(ctx)
+	if err != nil {
+		slog.Error("something went wrong while finding api keys", "error", err)
+		return nil, translateSQLiteError(ctx, err, "API key")
+	}
+
+	modelAPIKeys := make([]models.APIKey, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		modelAPIKeys[i] = *kr.apiKeyMapper.SQLiteToModel(apiKey)
+	}
+	return modelAPIKeys, nil
+}
+
+func (kr *SQLiteAPIKeysRepository) DeleteAPIKey(ctx context.Context, apiKeyId uuid.UUID) error {
+	deleted, err := retrySQLite(ctx, func() (int64, error) {
+		return kr.queries(ctx).DeleteApiKey(ctx, apiKeyId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting api key", "error", err)
+		return translateSQLiteError(ctx, err, "API key")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "API key")
+	}
+	return nil
+}
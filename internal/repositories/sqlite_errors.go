@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLite reports which columns broke a unique constraint rather than the
+// name of the constraint, e.g. "UNIQUE constraint failed: rooms.slug".
+var sqliteUniqueViolationTags = map[string]string{
+	"rooms.slug":      "ROOM_SLUG_CONFLICT",
+	"rooms.join_code": "ROOM_JOIN_CODE_CONFLICT",
+	"poll_votes.poll_id, poll_votes.participant_id": "ALREADY_VOTED",
+}
+
+// translateSQLiteError is the translateError of the SQLite backend. Foreign
+// key violations don't say which key failed, so they report resource.
+func translateSQLiteError(ctx context.Context, err error, resource string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal_errors.NewErrNotFound(ctx, resource)
+	}
+
+	// an interrupted statement doesn't wrap the error of the context
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return internal_errors.NewErrServiceUnavailable(ctx, "DATABASE_TIMEOUT")
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return internal_errors.NewErrInternal(ctx, err)
+	}
+
+	if isSQLiteBusy(err) {
+		return internal_errors.NewErrServiceUnavailable(ctx, "DATABASE_CONFLICT")
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		for columns, tag := range sqliteUniqueViolationTags {
+			if strings.Contains(sqliteErr.Error(), "failed: "+columns) {
+				return internal_errors.NewErrConflict(ctx, tag)
+			}
+		}
+		return internal_errors.NewErrConflict(ctx, "RESOURCE_CONFLICT")
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return internal_errors.NewErrNotFound(ctx, resource)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		for constraint, tag := range checkViolationTags {
+			if strings.Contains(sqliteErr.Error(), constraint) {
+				return internal_errors.NewErrBadRequest(ctx, tag)
+			}
+		}
+		return internal_errors.NewErrBadRequest(ctx, "CONSTRAINT_VIOLATION")
+	default:
+		return internal_errors.NewErrInternal(ctx, err)
+	}
+}
+
+func isSQLiteForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// isSQLiteBusy tells whether the database was locked by another connection
+// for longer than the busy timeout, running the statement again may succeed.
+func isSQLiteBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// extended codes keep the primary code in the low byte
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+func retrySQLite[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	return retryWhen(ctx, isSQLiteBusy, fn)
+}
+
+func retrySQLiteExec(ctx context.Context, fn func() error) error {
+	_, err := retrySQLite(ctx, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// sqliteTx runs fn in a transaction, the whole transaction is run again
+// when the database is busy. Inside a unit of work fn joins its transaction.
+func sqliteTx(ctx context.Context, db *sql.DB, queries *sqlitestore.Queries, fn func(*sqlitestore.Queries) error) error {
+	if tx, ok := txFrom[*sql.Tx](ctx); ok {
+		return fn(queries.WithTx(tx))
+	}
+
+	return retrySQLiteExec(ctx, func() error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(queries.WithTx(tx)); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
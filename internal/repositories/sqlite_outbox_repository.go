@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+type SQLiteOutboxRepository struct {
+	db           *sqlitestore.Queries
+	outboxMapper *mappers.OutboxMapper
+}
+
+func NewSQLiteOutboxRepository(conn *sql.DB, outboxMapper *mappers.OutboxMapper) *SQLiteOutboxRepository {
+	return &SQLiteOutboxRepository{
+		db:           sqlitestore.New(conn),
+		outboxMapper: outboxMapper,
+	}
+}
+
+func (or *SQLiteOutboxRepository) queries(ctx context.Context) *sqlitestore.Queries {
+	return sqliteQueries(ctx, or.db)
+}
+
+func (or *SQLiteOutboxRepository) SaveEvent(ctx context.Context, roomId uuid.UUID, kind string, payload []byte) error {
+	err := retrySQLiteExec(ctx, func() error {
+		return or.queries(ctx).InsertOutboxEvent(ctx, sqlitestore.InsertOutboxEventParams{
+			RoomID:    roomId,
+			Kind:      kind,
+			Payload:   string(payload),
+			CreatedAt: time.Now().UnixMicro(),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving outbox event", "error", err)
+		return translateSQLiteError(ctx, err, "Room")
+	}
+	return nil
+}
+
+func (or *SQLiteOutboxRepository) FindPendingEvents(ctx context.Context, limit int32) ([]models.OutboxEvent, error) {
+	events, err := or.queries(ctx).GetPendingOutboxEvents(ctx, int64(limit))
+	if err != nil {
+		slog.Error("something went wrong while finding pending outbox events", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Event")
+	}
+
+	modelEvents := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		modelEvents[i] = or.outboxMapper.SQLiteToModel(event)
+	}
+	return modelEvents, nil
+}
+
+func (or *SQLiteOutboxRepository) ClaimPendingEvents(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.OutboxEvent, error) {
+	events, err := retrySQLite(ctx, func() ([]sqlitestore.OutboxEvent, error) {
+		return or.queries(ctx).ClaimPendingOutboxEvents(ctx, sqlitestore.ClaimPendingOutboxEventsParams{
+			LeaseUntil: leaseUntil.UnixMicro(),
+			Now:        time.Now().UnixMicro(),
+			MaxEvents:  int64(limit),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while claiming pending outbox events", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Event")
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	slices.SortFunc(events, func(a, b sqlitestore.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	modelEvents := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		modelEvents[i] = or.outboxMapper.SQLiteToModel(event)
+	}
+	return modelEvents, nil
+}
+
+func (or *SQLiteOutboxRepository) MarkEventDispatched(ctx context.Context, eventId int64) error {
+	if err := retrySQLiteExec(ctx, func() error {
+		return or.queries(ctx).MarkOutboxEventDispatched(ctx, sqlitestore.MarkOutboxEventDispatchedParams{
+			Now: time.Now().UnixMicro(),
+			ID:  eventId,
+		})
+	}); err != nil {
+		slog.Error("something went wrong while marking outbox event as dispatched", "error", err)
+		return translateSQLiteError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *SQLiteOutboxRepository) MarkEventFailed(ctx context.Context, eventId int64, handled int32) error {
+	if err := retrySQLiteExec(ctx, func() error {
+		return or.queries(ctx).FailOutboxEvent(ctx, sqlitestore.FailOutboxEventParams{
+			Handled: int64(handled),
+			ID:      eventId,
+		})
+	}); err != nil {
+		slog.Error("something went wrong while counting outbox event attempt", "error", err)
+		return translateSQLiteError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *SQLiteOutboxRepository) ReleaseEvents(ctx context.Context, eventIds []int64) error {
+	if len(eventIds) == 0 {
+		return nil
+	}
+	if err := retrySQLiteExec(ctx, func() error {
+		return or.queries(ctx).ReleaseOutboxEvents(ctx, eventIds)
+	}); err != nil {
+		slog.Error("something went wrong while releasing outbox events", "error", err)
+		return translateSQLiteError(ctx, err, "Event")
+	}
+	return nil
+}
+
+func (or *SQLiteOutboxRepository) DeleteDispatchedEvents(ctx context.Context, dispatchedBefore time.Time) (int64, error) {
+	deleted, err := retrySQLite(ctx, func() (int64, error) {
+		return or.queries(ctx).DeleteDispatchedOutboxEvents(ctx, dispatchedBefore.UnixMicro())
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting dispatched outbox events", "error", err)
+		return deleted, translateSQLiteError(ctx, err, "Event")
+	}
+	return deleted, nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+type SQLitePollsRepository struct {
+	conn       *sql.DB
+	db         *sqlitestore.Queries
+	pollMapper *mappers.PollMapper
+}
+
+func NewSQLitePollsRepository(conn *sql.DB, pollMapper *mappers.PollMapper) *SQLitePollsRepository {
+	return &SQLitePollsRepository{
+		conn:       conn,
+		db:         sqlitestore.New(conn),
+		pollMapper: pollMapper,
+	}
+}
+
+func (pr *SQLitePollsRepository) queries(ctx context.Context) *sqlitestore.Queries {
+	return sqliteQueries(ctx, pr.db)
+}
+
+// SavePoll stores a poll together with its options in a single transaction.
+func (pr *SQLitePollsRepository) SavePoll(ctx context.Context, roomId uuid.UUID, question string, options []string) (*models.Poll, error) {
+	pollId := uuid.New()
+	err := sqliteTx(ctx, pr.conn, pr.db, func(q *sqlitestore.Queries) error {
+		err := q.InsertPoll(ctx, sqlitestore.InsertPollParams{
+			ID:        pollId,
+			RoomID:    roomId,
+			Question:  question,
+			CreatedAt: time.Now().UnixMicro(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for i, label := range options {
+			err := q.InsertPollOption(ctx, sqlitestore.InsertPollOptionParams{
+				ID:       uuid.New(),
+				PollID:   pollId,
+				Position: int64(i + 1),
+				Label:    label,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving poll", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+	return pr.FindPoll(ctx, pollId)
+}
+
+func (pr *SQLitePollsRepository) FindPoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	poll, err := pr.queries(ctx).GetPoll(ctx, pollId)
+	if err != nil {
+		slog.Error("something went wrong while finding a poll", "error", err)
+		return pr.pollMapper.SQLiteToModel(poll, nil), translateSQLiteError(ctx, err, "Poll")
+	}
+
+	options, err := pr.queries(ctx).GetPollOptions(ctx, pollId)
+	if err != nil {
+		slog.Error("something went wrong while finding poll options", "error", err)
+		return pr.pollMapper.SQLiteToModel(poll, nil), translateSQLiteError(ctx, err, "Poll")
+	}
+	return pr.pollMapper.SQLiteToModel(poll, options), nil
+}
+
+func (pr *SQLitePollsRepository) FindAllRoomPolls(ctx context.Context, roomId uuid.UUID) ([]models.Poll, error) {
+	polls, err := pr.queries(ctx).GetRoomPolls(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding room polls", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+
+	options, err := pr.queries(ctx).GetRoomPollOptions(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding room poll options", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+
+	pollOptions := make(map[uuid.UUID][]sqlitestore.PollOption, len(polls))
+	for _, option := range options {
+		pollOptions[option.PollID] = append(pollOptions[option.PollID], option)
+	}
+
+	modelPolls := make([]models.Poll, len(polls))
+	for i, poll := range polls {
+		modelPolls[i] = *pr.pollMapper.SQLiteToModel(poll, pollOptions[poll.ID])
+	}
+	return modelPolls, nil
+}
+
+// SaveVote counts the vote of a participant. It returns false when the
+// participant already voted in the poll.
+func (pr *SQLitePollsRepository) SaveVote(ctx context.Context, pollId uuid.UUID, optionId uuid.UUID, participantId string) (bool, error) {
+	var counted int64
+	err := sqliteTx(ctx, pr.conn, pr.db, func(q *sqlitestore.Queries) error {
+		var err error
+		counted, err = q.InsertPollVote(ctx, sqlitestore.InsertPollVoteParams{
+			PollID:        pollId,
+			ParticipantID: participantId,
+			OptionID:      optionId,
+			CreatedAt:     time.Now().UnixMicro(),
+		})
+		if err != nil || counted == 0 {
+			return err
+		}
+		return q.IncrementPollOptionVotes(ctx, optionId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving poll vote", "error", err)
+		return false, translateSQLiteError(ctx, err, "Poll")
+	}
+	return counted > 0, nil
+}
+
+func (pr *SQLitePollsRepository) ClosePoll(ctx context.Context, pollId uuid.UUID) (*models.Poll, error) {
+	_, err := retrySQLite(ctx, func() (sqlitestore.Poll, error) {
+		return pr.queries(ctx).ClosePoll(ctx, sqlitestore.ClosePollParams{
+			Now: time.Now().UnixMicro(),
+			ID:  pollId,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing poll", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Poll")
+	}
+	return pr.FindPoll(ctx, pollId)
+}
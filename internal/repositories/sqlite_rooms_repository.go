@@ -0,0 +1,616 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+// SQLiteRoomsRepository stores the rooms in SQLite. The ids and timestamps
+// Postgres fills in with defaults are generated here, and the statements
+// Postgres runs as a single query with CTEs run in a transaction.
+type SQLiteRoomsRepository struct {
+	conn          *sql.DB
+	db            *sqlitestore.Queries
+	roomMapper    *mappers.RoomMapper
+	messageMapper *mappers.MessageMapper
+}
+
+func NewSQLiteRoomsRepository(conn *sql.DB, roomMapper *mappers.RoomMapper,
+	messageMapper *mappers.MessageMapper) *SQLiteRoomsRepository {
+	return &SQLiteRoomsRepository{
+		conn:          conn,
+		db:            sqlitestore.New(conn),
+		roomMapper:    roomMapper,
+		messageMapper: messageMapper,
+	}
+}
+
+// queries joins the transaction of the unit of work ctx belongs to, if any.
+func (rr *SQLiteRoomsRepository) queries(ctx context.Context) *sqlitestore.Queries {
+	return sqliteQueries(ctx, rr.db)
+}
+
+func (rr *SQLiteRoomsRepository) CloseEndedRooms(ctx context.Context, endedBefore time.Time) ([]uuid.UUID, error) {
+	closed, err := retrySQLite(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).CloseEndedRooms(ctx, sqlitestore.CloseEndedRoomsParams{
+			Now:         time.Now().UnixMicro(),
+			EndedBefore: endedBefore.UnixMicro(),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing ended rooms", "error", err)
+		return closed, translateSQLiteError(ctx, err, "Room")
+	}
+	return closed, err
+}
+
+func (rr *SQLiteRoomsRepository) CloseInactiveRooms(ctx context.Context, inactiveSince time.Time) ([]uuid.UUID, error) {
+	closed, err := retrySQLite(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).CloseInactiveRooms(ctx, sqlitestore.CloseInactiveRoomsParams{
+			Now:           time.Now().UnixMicro(),
+			InactiveSince: inactiveSince.UnixMicro(),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing inactive rooms", "error", err)
+		return closed, translateSQLiteError(ctx, err, "Room")
+	}
+	return closed, err
+}
+
+func (rr *SQLiteRoomsRepository) DeleteArchivedRooms(ctx context.Context, closedBefore time.Time) ([]uuid.UUID, error) {
+	deleted, err := retrySQLite(ctx, func() ([]uuid.UUID, error) {
+		return rr.queries(ctx).DeleteArchivedRooms(ctx, closedBefore.UnixMicro())
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting archived rooms", "error", err)
+		return deleted, translateSQLiteError(ctx, err, "Room")
+	}
+	return deleted, err
+}
+
+// AnonymizeArchivedRooms anonymizes the messages and the poll votes of the
+// archived rooms before marking the rooms, which those queries look for.
+// Like the Postgres query it returns how many messages were anonymized.
+func (rr *SQLiteRoomsRepository) AnonymizeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error) {
+	var anonymized int64
+	err := sqliteTx(ctx, rr.conn, rr.db, func(q *sqlitestore.Queries) error {
+		var err error
+		anonymized, err = q.AnonymizeArchivedRoomMessages(ctx, closedBefore.UnixMicro())
+		if err != nil {
+			return err
+		}
+		if err := q.AnonymizeArchivedRoomVotes(ctx, closedBefore.UnixMicro()); err != nil {
+			return err
+		}
+
+		_, err = q.AnonymizeArchivedRooms(ctx, sqlitestore.AnonymizeArchivedRoomsParams{
+			Now:          time.Now().UnixMicro(),
+			ClosedBefore: closedBefore.UnixMicro(),
+		})
+		return err
+	})
+	if err != nil {
+		slog.Error("something went wrong while anonymizing archived rooms", "error", err)
+		return 0, translateSQLiteError(ctx, err, "Room")
+	}
+	return anonymized, nil
+}
+
+func (rr *SQLiteRoomsRepository) CloseRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	closedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).CloseRoom(ctx, sqlitestore.CloseRoomParams{
+			ID:  roomId,
+			Now: time.Now().UnixMicro(),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while closing room", "error", err)
+		return rr.roomMapper.SQLiteToModel(closedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(closedRoom), err
+}
+
+func (rr *SQLiteRoomsRepository) ReopenRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	reopenedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).ReopenRoom(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while reopening room", "error", err)
+		return rr.roomMapper.SQLiteToModel(reopenedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(reopenedRoom), err
+}
+
+func (rr *SQLiteRoomsRepository) FindMessage(ctx context.Context, messageId uuid.UUID) (*models.Message, error) {
+	message, err := rr.queries(ctx).GetMessage(ctx, messageId)
+	if err != nil {
+		slog.Error("something went wrong while finding a message", "error", err)
+		return rr.messageMapper.SQLiteToModel(message), translateSQLiteError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.SQLiteToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *SQLiteRoomsRepository) FindRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := rr.queries(ctx).GetRoom(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while finding a room", "error", err)
+		return rr.roomMapper.SQLiteToModel(room), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(room), err
+}
+
+// LockRoom only reads the room. The database is opened with a single
+// connection, so a unit of work has it for itself until it ends.
+func (rr *SQLiteRoomsRepository) LockRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := rr.queries(ctx).LockRoom(ctx, roomId)
+	if err != nil {
+		slog.Error("something went wrong while locking a room", "error", err)
+		return rr.roomMapper.SQLiteToModel(room), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(room), err
+}
+
+func (rr *SQLiteRoomsRepository) FindRoomByJoinCode(ctx context.Context, code string) (*models.Room, error) {
+	room, err := rr.queries(ctx).GetRoomByJoinCode(ctx, code)
+	if err != nil {
+		slog.Error("something went wrong while finding a room by join code", "error", err)
+		return rr.roomMapper.SQLiteToModel(room), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(room), err
+}
+
+func (rr *SQLiteRoomsRepository) FindAllRooms(ctx context.Context) ([]models.Room, error) {
+	rooms, err := rr.queries(ctx).GetRooms(ctx)
+	modelRooms := make([]models.Room, len(rooms))
+
+	for i, room := range rooms {
+		modelRooms[i] = *rr.roomMapper.SQLiteToModel(room)
+	}
+
+	if err != nil {
+		slog.Error("something went wrong while finding all rooms", "error", err)
+		return modelRooms, translateSQLiteError(ctx, err, "Room")
+	}
+
+	return modelRooms, err
+}
+
+func (rr *SQLiteRoomsRepository) SearchRooms(ctx context.Context, query string, joinCode string) ([]models.Room, error) {
+	rooms, err := rr.queries(ctx).SearchRooms(ctx, sqlitestore.SearchRoomsParams{
+		Query:    query,
+		JoinCode: joinCode,
+	})
+	if err != nil {
+		slog.Error("something went wrong while searching rooms", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+
+	modelRooms := make([]models.Room, len(rooms))
+	for i, room := range rooms {
+		modelRooms[i] = *rr.roomMapper.SQLiteToModel(room)
+	}
+	return modelRooms, nil
+}
+
+// FindAllRoomMessages finds the messages of a room, only the ones with the
+// given tag unless it is empty.
+func (rr *SQLiteRoomsRepository) FindAllRoomMessages(ctx context.Context, roomID uuid.UUID, tag string) ([]models.Message, error) {
+	var messages []sqlitestore.Message
+	var err error
+	if tag == "" {
+		messages, err = rr.queries(ctx).GetRoomMessages(ctx, roomID)
+	} else {
+		messages, err = rr.queries(ctx).GetRoomMessagesByTag(ctx, sqlitestore.GetRoomMessagesByTagParams{
+			RoomID: roomID,
+			Tag:    tag,
+		})
+	}
+	modelMessages := make([]models.Message, len(messages))
+
+	for i, message := range messages {
+		modelMessages[i] = *rr.messageMapper.SQLiteToModel(message)
+	}
+
+	if err != nil {
+		slog.Error("something went wrong while finding all room messages", "error", err)
+		return modelMessages, translateSQLiteError(ctx, err, "Room")
+	}
+
+	reactions, err := rr.queries(ctx).GetRoomMessageReactions(ctx, roomID)
+	if err != nil {
+		slog.Error("something went wrong while finding room message reactions", "error", err)
+		return modelMessages, translateSQLiteError(ctx, err, "Room")
+	}
+
+	indexes := make(map[uuid.UUID]int, len(modelMessages))
+	for i, message := range modelMessages {
+		indexes[message.ID] = i
+	}
+	for _, reaction := range reactions {
+		i, ok := indexes[reaction.MessageID]
+		if !ok {
+			continue
+		}
+		message := &modelMessages[i]
+		if message.Reactions == nil {
+			message.Reactions = make(map[string]int64)
+		}
+		message.Reactions[reaction.Kind] = reaction.Count
+	}
+	return modelMessages, nil
+}
+
+func (rr *SQLiteRoomsRepository) StreamRoomMessages(ctx context.Context, roomID uuid.UUID, fn func(*models.Message) error) error {
+	reactions, err := rr.queries(ctx).GetRoomMessageReactions(ctx, roomID)
+	if err != nil {
+		slog.Error("something went wrong while finding room message reactions", "error", err)
+		return translateSQLiteError(ctx, err, "Room")
+	}
+	// a room has a handful of reactions per message at most, they are read
+	// upfront rather than with every page
+	messageReactions := make(map[uuid.UUID]map[string]int64)
+	for _, reaction := range reactions {
+		if messageReactions[reaction.MessageID] == nil {
+			messageReactions[reaction.MessageID] = make(map[string]int64)
+		}
+		messageReactions[reaction.MessageID][reaction.Kind] = reaction.Count
+	}
+
+	params := sqlitestore.GetRoomMessagesPageParams{
+		RoomID:         roomID,
+		AfterCreatedAt: math.MinInt64,
+		PageSize:       streamPageSize,
+	}
+	for {
+		messages, err := rr.queries(ctx).GetRoomMessagesPage(ctx, params)
+		if err != nil {
+			slog.Error("something went wrong while streaming room messages", "error", err)
+			return translateSQLiteError(ctx, err, "Room")
+		}
+		for _, message := range messages {
+			modelMessage := rr.messageMapper.SQLiteToModel(message)
+			modelMessage.Reactions = messageReactions[message.ID]
+			if err := fn(modelMessage); err != nil {
+				return err
+			}
+		}
+		if len(messages) < streamPageSize {
+			return nil
+		}
+		last := messages[len(messages)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+func (rr *SQLiteRoomsRepository) SaveRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	params := rr.roomMapper.ToSQLiteInsertParams(room)
+	params.ID = uuid.New()
+	params.CreatedAt = time.Now().UnixMicro()
+
+	savedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).InsertRoom(ctx, params)
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving room", "error", err)
+		return rr.roomMapper.SQLiteToModel(savedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(savedRoom), err
+}
+
+// FindRoomsTagCounts counts the messages per tag of each of the given rooms.
+func (rr *SQLiteRoomsRepository) FindRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	rows, err := rr.queries(ctx).GetRoomsTagCounts(ctx, roomIds)
+	if err != nil {
+		slog.Error("something went wrong while counting room tags", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+
+	counts := make(map[uuid.UUID]map[string]int64, len(roomIds))
+	for _, row := range rows {
+		if counts[row.RoomID] == nil {
+			counts[row.RoomID] = make(map[string]int64)
+		}
+		counts[row.RoomID][row.Tag] = row.MessagesCount
+	}
+	return counts, nil
+}
+
+func (rr *SQLiteRoomsRepository) IsRoomSlugTaken(ctx context.Context, slug string, roomId uuid.UUID) (bool, error) {
+	taken, err := rr.queries(ctx).IsRoomSlugTaken(ctx, sqlitestore.IsRoomSlugTakenParams{
+		Slug: slug,
+		ID:   roomId,
+	})
+	if err != nil {
+		slog.Error("something went wrong while checking room slug", "error", err)
+		return taken, translateSQLiteError(ctx, err, "Room")
+	}
+	return taken, err
+}
+
+func (rr *SQLiteRoomsRepository) FindRoomSlugs(ctx context.Context, slug string, roomId uuid.UUID) ([]string, error) {
+	slugs, err := rr.queries(ctx).GetRoomSlugs(ctx, sqlitestore.GetRoomSlugsParams{
+		Slug: slug,
+		ID:   roomId,
+	})
+	if err != nil {
+		slog.Error("something went wrong while finding room slugs", "error", err)
+		return slugs, translateSQLiteError(ctx, err, "Room")
+	}
+	return slugs, err
+}
+
+func (rr *SQLiteRoomsRepository) UpdateRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	updatedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).UpdateRoom(ctx, rr.roomMapper.ToSQLiteUpdateParams(room))
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room", "error", err)
+		return rr.roomMapper.SQLiteToModel(updatedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(updatedRoom), err
+}
+
+func (rr *SQLiteRoomsRepository) UpdateRoomJoinCode(ctx context.Context, roomId uuid.UUID, code string) (*models.Room, error) {
+	updatedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).UpdateRoomJoinCode(ctx, sqlitestore.UpdateRoomJoinCodeParams{
+			ID:       roomId,
+			JoinCode: code,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room join code", "error", err)
+		return rr.roomMapper.SQLiteToModel(updatedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(updatedRoom), err
+}
+
+// UpdateRoomSpotlight puts a message in the spotlight of a room, a nil
+// messageId clears it.
+func (rr *SQLiteRoomsRepository) UpdateRoomSpotlight(ctx context.Context, roomId uuid.UUID, messageId *uuid.UUID) (*models.Room, error) {
+	spotlight := uuid.NullUUID{}
+	if messageId != nil {
+		spotlight = uuid.NullUUID{UUID: *messageId, Valid: true}
+	}
+
+	updatedRoom, err := retrySQLite(ctx, func() (sqlitestore.Room, error) {
+		return rr.queries(ctx).UpdateRoomSpotlight(ctx, sqlitestore.UpdateRoomSpotlightParams{
+			ID:                 roomId,
+			SpotlightMessageID: spotlight,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while updating room spotlight", "error", err)
+		if isSQLiteForeignKeyViolation(err) {
+			return rr.roomMapper.SQLiteToModel(updatedRoom), internal_errors.NewErrNotFound(ctx, "Message")
+		}
+		return rr.roomMapper.SQLiteToModel(updatedRoom), translateSQLiteError(ctx, err, "Room")
+	}
+	return rr.roomMapper.SQLiteToModel(updatedRoom), err
+}
+
+func (rr *SQLiteRoomsRepository) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
+	deleted, err := retrySQLite(ctx, func() (int64, error) {
+		return rr.queries(ctx).DeleteRoom(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting room", "error", err)
+		return translateSQLiteError(ctx, err, "Room")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "Room")
+	}
+	return nil
+}
+
+func (rr *SQLiteRoomsRepository) SaveMessage(ctx context.Context, params *request.MessageRequest, moderationFlags []string) (uuid.UUID, error) {
+	messageId := uuid.New()
+	err := retrySQLiteExec(ctx, func() error {
+		return rr.queries(ctx).InsertMessage(ctx, sqlitestore.InsertMessageParams{
+			ID:              messageId,
+			RoomID:          params.RoomID,
+			Message:         params.Message,
+			Tag:             params.Tag,
+			ModerationFlags: rr.messageMapper.ToSQLiteFlags(moderationFlags),
+			CreatedAt:       time.Now().UnixMicro(),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving message", "error", err)
+		return uuid.Nil, translateSQLiteError(ctx, err, "Room")
+	}
+	return messageId, err
+}
+
+// SaveMessages saves messages[i] with tags[i] and moderationFlags[i] in a
+// single transaction.
+func (rr *SQLiteRoomsRepository) SaveMessages(ctx context.Context, roomId uuid.UUID, messages []string, tags []string, moderationFlags [][]string) ([]uuid.UUID, error) {
+	messageIds := make([]uuid.UUID, len(messages))
+	err := sqliteTx(ctx, rr.conn, rr.db, func(q *sqlitestore.Queries) error {
+		// each message is a microsecond younger than the previous one, so
+		// they are listed in the order they were given
+		createdAt := time.Now().UnixMicro()
+		for i, message := range messages {
+			messageIds[i] = uuid.New()
+			err := q.InsertMessage(ctx, sqlitestore.InsertMessageParams{
+				ID:              messageIds[i],
+				RoomID:          roomId,
+				Message:         message,
+				Tag:             tags[i],
+				ModerationFlags: rr.messageMapper.ToSQLiteFlags(moderationFlags[i]),
+				CreatedAt:       createdAt + int64(i),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving messages", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+	return messageIds, err
+}
+
+// ReactToMessage counts a reaction to a message, likes are also copied to
+// the likes_count of the message.
+func (rr *SQLiteRoomsRepository) ReactToMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	var count int64
+	err := sqliteTx(ctx, rr.conn, rr.db, func(q *sqlitestore.Queries) error {
+		var err error
+		count, err = q.IncrementMessageReaction(ctx, sqlitestore.IncrementMessageReactionParams{
+			MessageID: messageId,
+			Kind:      kind,
+		})
+		if err != nil {
+			return err
+		}
+		return rr.syncLikes(ctx, q, messageId, kind, count)
+	})
+	if err != nil {
+		slog.Error("something went wrong while adding message reaction", "error", err)
+		return 0, translateSQLiteError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *SQLiteRoomsRepository) RemoveReactionFromMessage(ctx context.Context, messageId uuid.UUID, kind string) (int64, error) {
+	var count int64
+	err := sqliteTx(ctx, rr.conn, rr.db, func(q *sqlitestore.Queries) error {
+		var err error
+		count, err = q.DecrementMessageReaction(ctx, sqlitestore.DecrementMessageReactionParams{
+			MessageID: messageId,
+			Kind:      kind,
+		})
+		if err != nil {
+			return err
+		}
+		return rr.syncLikes(ctx, q, messageId, kind, count)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		slog.Error("something went wrong while removing message reaction", "error", err)
+		return 0, translateSQLiteError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *SQLiteRoomsRepository) syncLikes(ctx context.Context, q *sqlitestore.Queries, messageId uuid.UUID, kind string, count int64) error {
+	if kind != "like" {
+		return nil
+	}
+	return q.UpdateMessageLikes(ctx, sqlitestore.UpdateMessageLikesParams{
+		LikesCount: count,
+		ID:         messageId,
+	})
+}
+
+func (rr *SQLiteRoomsRepository) DownvoteMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	count, err := retrySQLite(ctx, func() (int64, error) {
+		return rr.queries(ctx).IncrementMessageDownvotes(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while downvoting message", "error", err)
+		return count, translateSQLiteError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *SQLiteRoomsRepository) RemoveDownvoteFromMessage(ctx context.Context, messageId uuid.UUID) (int64, error) {
+	count, err := retrySQLite(ctx, func() (int64, error) {
+		return rr.queries(ctx).DecrementMessageDownvotes(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while removing message downvote", "error", err)
+		return count, translateSQLiteError(ctx, err, "Message")
+	}
+	return count, err
+}
+
+func (rr *SQLiteRoomsRepository) RecountMessageLikes(ctx context.Context, roomId uuid.UUID) (int64, error) {
+	recounted, err := retrySQLite(ctx, func() (int64, error) {
+		return rr.queries(ctx).RecountRoomMessageLikes(ctx, roomId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while recounting message likes", "error", err)
+		return recounted, translateSQLiteError(ctx, err, "Room")
+	}
+	return recounted, err
+}
+
+func (rr *SQLiteRoomsRepository) FindMessageReactions(ctx context.Context, messageId uuid.UUID) (map[string]int64, error) {
+	reactions, err := rr.queries(ctx).GetMessageReactions(ctx, messageId)
+	if err != nil {
+		slog.Error("something went wrong while finding message reactions", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Message")
+	}
+
+	counts := make(map[string]int64, len(reactions))
+	for _, reaction := range reactions {
+		counts[reaction.Kind] = reaction.Count
+	}
+	return counts, nil
+}
+
+func (rr *SQLiteRoomsRepository) SetMessagePinned(ctx context.Context, messageId uuid.UUID, pinned bool) (*models.Message, error) {
+	message, err := retrySQLite(ctx, func() (sqlitestore.Message, error) {
+		return rr.queries(ctx).SetMessagePinned(ctx, sqlitestore.SetMessagePinnedParams{
+			ID:     messageId,
+			Pinned: pinned,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while pinning message", "error", err)
+		return rr.messageMapper.SQLiteToModel(message), translateSQLiteError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.SQLiteToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *SQLiteRoomsRepository) SetMessageHidden(ctx context.Context, messageId uuid.UUID, hidden bool) (*models.Message, error) {
+	message, err := retrySQLite(ctx, func() (sqlitestore.Message, error) {
+		return rr.queries(ctx).SetMessageHidden(ctx, sqlitestore.SetMessageHiddenParams{
+			ID:     messageId,
+			Hidden: hidden,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while hiding message", "error", err)
+		return rr.messageMapper.SQLiteToModel(message), translateSQLiteError(ctx, err, "Message")
+	}
+
+	modelMessage := rr.messageMapper.SQLiteToModel(message)
+	modelMessage.Reactions, err = rr.FindMessageReactions(ctx, messageId)
+	return modelMessage, err
+}
+
+func (rr *SQLiteRoomsRepository) MarkMessageAsAnswered(ctx context.Context, messageId uuid.UUID) error {
+	err := retrySQLiteExec(ctx, func() error {
+		return rr.queries(ctx).MarkMessageAsAnswered(ctx, messageId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while marking message as answered", "error", err)
+		return translateSQLiteError(ctx, err, "Message")
+	}
+	return nil
+}
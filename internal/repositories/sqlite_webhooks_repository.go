@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/google/uuid"
+)
+
+type SQLiteWebhooksRepository struct {
+	db            *sqlitestore.Queries
+	webhookMapper *mappers.WebhookMapper
+}
+
+func NewSQLiteWebhooksRepository(conn *sql.DB, webhookMapper *mappers.WebhookMapper) *SQLiteWebhooksRepository {
+	return &SQLiteWebhooksRepository{
+		db:            sqlitestore.New(conn),
+		webhookMapper: webhookMapper,
+	}
+}
+
+func (wr *SQLiteWebhooksRepository) queries(ctx context.Context) *sqlitestore.Queries {
+	return sqliteQueries(ctx, wr.db)
+}
+
+func (wr *SQLiteWebhooksRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	params := wr.webhookMapper.ToSQLiteInsertParams(webhook)
+	params.ID = uuid.New()
+	params.CreatedAt = time.Now().UnixMicro()
+
+	err := retrySQLiteExec(ctx, func() error {
+		return wr.queries(ctx).InsertWebhook(ctx, params)
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving webhook", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Room")
+	}
+	return wr.FindWebhook(ctx, params.ID)
+}
+
+func (wr *SQLiteWebhooksRepository) FindWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error) {
+	webhook, err := wr.queries(ctx).GetWebhook(ctx, webhookId)
+	if err != nil {
+		slog.Error("something went wrong while finding a webhook", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Webhook")
+	}
+	return wr.webhookMapper.SQLiteToModel(webhook), nil
+}
+
+func (wr *SQLiteWebhooksRepository) FindWebhooks(ctx context.Context, roomId *uuid.UUID) ([]models.Webhook, error) {
+	var webhooks []sqlitestore.Webhook
+	var err error
+	if roomId == nil {
+		webhooks, err = wr.queries(ctx).GetGlobalWebhooks(ctx)
+	} else {
+		webhooks, err = wr.queries(ctx).GetRoomWebhooks(ctx, uuid.NullUUID{UUID: *roomId, Valid: true})
+	}
+	if err != nil {
+		slog.Error("something went wrong while finding webhooks", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Webhook")
+	}
+	return wr.toModels(webhooks), nil
+}
+
+func (wr *SQLiteWebhooksRepository) FindEventWebhooks(ctx context.Context, roomId uuid.UUID) ([]models.Webhook, error) {
+	webhooks, err := wr.queries(ctx).GetEventWebhooks(ctx, uuid.NullUUID{UUID: roomId, Valid: true})
+	if err != nil {
+		slog.Error("something went wrong while finding event webhooks", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Webhook")
+	}
+	return wr.toModels(webhooks), nil
+}
+
+func (wr *SQLiteWebhooksRepository) DeleteWebhook(ctx context.Context, webhookId uuid.UUID) error {
+	deleted, err := retrySQLite(ctx, func() (int64, error) {
+		return wr.queries(ctx).DeleteWebhook(ctx, webhookId)
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting webhook", "error", err)
+		return translateSQLiteError(ctx, err, "Webhook")
+	}
+	if deleted == 0 {
+		return internal_errors.NewErrNotFound(ctx, "Webhook")
+	}
+	return nil
+}
+
+func (wr *SQLiteWebhooksRepository) SaveDelivery(ctx context.Context, webhookId uuid.UUID, eventKind string, payload []byte) error {
+	now := time.Now().UnixMicro()
+	err := retrySQLiteExec(ctx, func() error {
+		return wr.queries(ctx).InsertWebhookDelivery(ctx, sqlitestore.InsertWebhookDeliveryParams{
+			ID:            uuid.New(),
+			WebhookID:     webhookId,
+			EventKind:     eventKind,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while saving webhook delivery", "error", err)
+		return translateSQLiteError(ctx, err, "Webhook")
+	}
+	return nil
+}
+
+func (wr *SQLiteWebhooksRepository) ClaimDueDeliveries(ctx context.Context, leaseUntil time.Time, limit int32) ([]models.WebhookDelivery, error) {
+	deliveries, err := retrySQLite(ctx, func() ([]sqlitestore.WebhookDelivery, error) {
+		return wr.queries(ctx).ClaimDueWebhookDeliveries(ctx, sqlitestore.ClaimDueWebhookDeliveriesParams{
+			LeaseUntil:    leaseUntil.UnixMicro(),
+			Now:           time.Now().UnixMicro(),
+			MaxDeliveries: int64(limit),
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while claiming webhook deliveries", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Webhook delivery")
+	}
+	return wr.toDeliveryModels(deliveries), nil
+}
+
+func (wr *SQLiteWebhooksRepository) CompleteDelivery(ctx context.Context, deliveryId uuid.UUID, status string, responseStatus *int32, lastError string) error {
+	err := retrySQLiteExec(ctx, func() error {
+		return wr.queries(ctx).CompleteWebhookDelivery(ctx, sqlitestore.CompleteWebhookDeliveryParams{
+			Status:         status,
+			ResponseStatus: sqliteInt(responseStatus),
+			LastError:      lastError,
+			Now:            time.Now().UnixMicro(),
+			ID:             deliveryId,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while completing webhook delivery", "error", err)
+		return translateSQLiteError(ctx, err, "Webhook delivery")
+	}
+	return nil
+}
+
+func (wr *SQLiteWebhooksRepository) RetryDelivery(ctx context.Context, deliveryId uuid.UUID, responseStatus *int32, lastError string, nextAttemptAt time.Time) error {
+	err := retrySQLiteExec(ctx, func() error {
+		return wr.queries(ctx).RetryWebhookDelivery(ctx, sqlitestore.RetryWebhookDeliveryParams{
+			ResponseStatus: sqliteInt(responseStatus),
+			LastError:      lastError,
+			NextAttemptAt:  nextAttemptAt.UnixMicro(),
+			ID:             deliveryId,
+		})
+	})
+	if err != nil {
+		slog.Error("something went wrong while rescheduling webhook delivery", "error", err)
+		return translateSQLiteError(ctx, err, "Webhook delivery")
+	}
+	return nil
+}
+
+func (wr *SQLiteWebhooksRepository) FindDeliveries(ctx context.Context, webhookId uuid.UUID, status string, limit int32) ([]models.WebhookDelivery, error) {
+	deliveries, err := wr.queries(ctx).GetWebhookDeliveries(ctx, sqlitestore.GetWebhookDeliveriesParams{
+		WebhookID:     webhookId,
+		Status:        status,
+		MaxDeliveries: int64(limit),
+	})
+	if err != nil {
+		slog.Error("something went wrong while finding webhook deliveries", "error", err)
+		return nil, translateSQLiteError(ctx, err, "Webhook")
+	}
+	return wr.toDeliveryModels(deliveries), nil
+}
+
+func (wr *SQLiteWebhooksRepository) DeleteCompletedDeliveries(ctx context.Context, completedBefore time.Time) (int64, error) {
+	deleted, err := retrySQLite(ctx, func() (int64, error) {
+		return wr.queries(ctx).DeleteCompletedWebhookDeliveries(ctx, completedBefore.UnixMicro())
+	})
+	if err != nil {
+		slog.Error("something went wrong while deleting completed webhook deliveries", "error", err)
+		return deleted, translateSQLiteError(ctx, err, "Webhook delivery")
+	}
+	return deleted, nil
+}
+
+func (wr *SQLiteWebhooksRepository) toModels(webhooks []sqlitestore.Webhook) []models.Webhook {
+	modelWebhooks := make([]models.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		modelWebhooks[i] = *wr.webhookMapper.SQLiteToModel(webhook)
+	}
+	return modelWebhooks
+}
+
+func (wr *SQLiteWebhooksRepository) toDeliveryModels(deliveries []sqlitestore.WebhookDelivery) []models.WebhookDelivery {
+	modelDeliveries := make([]models.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		modelDeliveries[i] = wr.webhookMapper.SQLiteDeliveryToModel(delivery)
+	}
+	return modelDeliveries
+}
+
+func sqliteInt(value *int32) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*value), Valid: true}
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/pgstore"
+	"github.com/JulioZittei/wsrs-ama-go/internal/store/sqlitestore"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UnitOfWork runs several repository calls as a single transaction.
+type UnitOfWork interface {
+	// Do runs fn in a transaction, which is committed when fn returns nil
+	// and rolled back when it returns an error or panics. The repository
+	// calls made with the context fn gets take part in the transaction. A
+	// Do called inside another one joins the outer transaction. fn may
+	// run more than once, it should only change the database.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// lostRaceKey holds a *bool in the context of a postgres unit of work, set
+// when one of its statements lost a race against a concurrent transaction.
+// The statements report it translated, so it can't be told from the error
+// fn returns.
+type lostRaceKey struct{}
+
+// txFrom returns the transaction the context carries, if it carries one of
+// the type the backend uses.
+func txFrom[T any](ctx context.Context) (T, bool) {
+	tx, ok := ctx.Value(txKey{}).(T)
+	return tx, ok
+}
+
+func inTransaction(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+// pgQueries returns the queries of the transaction the context carries, or
+// db outside of a unit of work.
+func pgQueries(ctx context.Context, db *pgstore.Queries) *pgstore.Queries {
+	if tx, ok := txFrom[pgx.Tx](ctx); ok {
+		return db.WithTx(tx)
+	}
+	return db
+}
+
+func sqliteQueries(ctx context.Context, db *sqlitestore.Queries) *sqlitestore.Queries {
+	if tx, ok := txFrom[*sql.Tx](ctx); ok {
+		return db.WithTx(tx)
+	}
+	return db
+}
+
+type PgUnitOfWork struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgUnitOfWork(pool *pgxpool.Pool) *PgUnitOfWork {
+	return &PgUnitOfWork{pool: pool}
+}
+
+// Do runs fn again in a new transaction when the previous one lost a race
+// against a concurrent transaction.
+func (u *PgUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	lostRace, err := u.do(ctx, fn)
+	for attempt := 1; attempt < maxAttempts && lostRace; attempt++ {
+		slog.Warn("retrying unit of work after it lost a race", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryBackoff * time.Duration(attempt)):
+		}
+
+		lostRace, err = u.do(ctx, fn)
+	}
+	return err
+}
+
+// do runs fn in a transaction once and reports whether it failed by losing
+// a race.
+func (u *PgUnitOfWork) do(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return false, translateError(ctx, err, "")
+	}
+	// a no-op once the transaction is committed
+	defer tx.Rollback(context.Background())
+
+	lostRace := false
+	txCtx := context.WithValue(context.WithValue(ctx, txKey{}, tx), lostRaceKey{}, &lostRace)
+	if err := fn(txCtx); err != nil {
+		return lostRace || isRetryable(err), err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return isRetryable(err), translateError(ctx, err, "")
+	}
+	return false, nil
+}
+
+type SQLiteUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewSQLiteUnitOfWork(db *sql.DB) *SQLiteUnitOfWork {
+	return &SQLiteUnitOfWork{db: db}
+}
+
+func (u *SQLiteUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return translateSQLiteError(ctx, err, "")
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return translateSQLiteError(ctx, err, "")
+	}
+	return nil
+}
+
+// MemoryUnitOfWork holds the write lock of the in-memory store for the whole
+// unit of work and rolls back the tables fn wrote to when it fails.
+type MemoryUnitOfWork struct {
+	data *memoryData
+}
+
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	u.data.mutex.Lock()
+	defer u.data.mutex.Unlock()
+
+	undo := u.data.newUndo()
+	u.data.undo = undo
+	defer func() {
+		u.data.undo = nil
+		if recovered := recover(); recovered != nil {
+			undo.rollback(u.data)
+			panic(recovered)
+		}
+		if err != nil {
+			undo.rollback(u.data)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, u.data))
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/JulioZittei/wsrs-ama-go/internal/validator"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "wsrs_"
+	apiKeySize   = 32
+	// apiKeyShownLength is how much of a key is kept to tell the keys apart
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+)
+
+type APIKeysService struct {
+	repository   repositories.APIKeysRepository
+	apiKeyMapper *mappers.APIKeyMapper
+}
+
+func NewAPIKeysService(repository repositories.APIKeysRepository, apiKeyMapper *mappers.APIKeyMapper) *APIKeysService {
+	return &APIKeysService{
+		repository:   repository,
+		apiKeyMapper: apiKeyMapper,
+	}
+}
+
+// CreateAPIKey generates a key. Only its hash is stored, so the response is
+// the only one that carries it.
+func (s *APIKeysService) CreateAPIKey(ctx context.Context, params *request.APIKeyRequest) (*response.APIKeyResponse, error) {
+	if err := validator.ValidateStruct(ctx, params); err != nil {
+		return nil, err
+	}
+
+	generated := make([]byte, apiKeySize)
+	if _, err := rand.Read(generated); err != nil {
+		return nil, internal_errors.NewErrInternal(ctx, err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(generated)
+
+	apiKey, err := s.repository.SaveAPIKey(ctx, &models.APIKey{
+		Name:    params.Name,
+		Prefix:  key[:apiKeyShownLength],
+		KeyHash: hashAPIKey(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyResponse := s.apiKeyMapper.ToResponse(apiKey)
+	apiKeyResponse.Key = key
+	return apiKeyResponse, nil
+}
+
+func (s *APIKeysService) GetAPIKeys(ctx context.Context) ([]response.APIKeyResponse, error) {
+	apiKeys, err := s.repository.FindAPIKeys(ctx)
+	responseAPIKeys := make([]response.APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		responseAPIKeys[i] = *s.apiKeyMapper.ToResponse(&apiKey)
+	}
+	return responseAPIKeys, err
+}
+
+func (s *APIKeysService) DeleteAPIKey(ctx context.Context, apiKeyId uuid.UUID) error {
+	return s.repository.DeleteAPIKey(ctx, apiKeyId)
+}
+
+// Authenticate tells whether key is one of the stored keys. A failing
+// repository rejects the key as well.
+func (s *APIKeysService) Authenticate(ctx context.Context, key string) bool {
+	if key == "" {
+		return false
+	}
+	_, err := s.repository.FindAPIKeyByHash(ctx, hashAPIKey(key))
+	return err == nil
+}
+
+// hashAPIKey uses SHA-256 rather than bcrypt, the keys are random enough and
+// have to be found by their hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+)
+
+// filterMessage runs the content filters on a new message. The filtered text
+// replaces params.Message and the moderation flags raised are returned. A
+// rejection becomes a validation error on the message field.
+func (s *RoomsService) filterMessage(ctx context.Context, params *request.MessageRequest) ([]string, error) {
+	lang, _ := ctx.Value(middlewares.LangKey).(string)
+	message := &contentfilter.Message{
+		Text: params.Message,
+		Lang: lang,
+	}
+
+	if err := s.filters.Apply(message); err != nil {
+		var rejection *contentfilter.Rejection
+		if !errors.As(err, &rejection) {
+			return nil, internal_errors.NewErrInternal(ctx, err)
+		}
+
+		reason, _ := locale.GetMessage(ctx, rejection.Key, "message", rejection.Param)
+		return nil, internal_errors.NewErrValidation(ctx, []response.ErrorsParam{{Param: "message", Message: reason}})
+	}
+
+	params.Message = message.Text
+	return message.Flags, nil
+}
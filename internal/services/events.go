@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/joincode"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
+)
+
+// publish hands an event to the publisher. Call it in the unit of work of
+// the change the event describes.
+func (s *RoomsService) publish(ctx context.Context, event events.Event) error {
+	return s.publisher.Publish(ctx, event)
+}
+
+func roomUpdatedEvent(room *models.Room) events.RoomUpdated {
+	tags := room.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return events.RoomUpdated{
+		RoomID:             room.ID,
+		Subject:            room.Subject,
+		Description:        room.Description,
+		StartsAt:           room.StartsAt,
+		EndsAt:             room.EndsAt,
+		HostName:           room.HostName,
+		CoverImageURL:      room.CoverImageURL,
+		Slug:               room.Slug,
+		JoinCode:           joincode.Format(room.JoinCode),
+		DownvotesEnabled:   room.DownvotesEnabled,
+		SpotlightMessageID: room.SpotlightMessageID,
+		Tags:               tags,
+	}
+}
+
+// scoreChangedEvent tells the room the counts of a message changed, along
+// with the net score its rank follows.
+func scoreChangedEvent(message *models.Message) events.ScoreChanged {
+	return events.ScoreChanged{
+		RoomID:         message.RoomID,
+		MessageID:      message.ID,
+		LikesCount:     message.LikesCount,
+		DownvotesCount: message.DownvotesCount,
+		Score:          ranking.NetScore(message.LikesCount, message.DownvotesCount),
+	}
+}
+
+// pollEventOptions returns the options of a poll along with its total votes.
+func pollEventOptions(poll *models.Poll) ([]events.PollOption, int64) {
+	var totalVotes int64
+	options := make([]events.PollOption, len(poll.Options))
+	for i, option := range poll.Options {
+		options[i] = events.PollOption{
+			ID:         option.ID,
+			Label:      option.Label,
+			VotesCount: option.VotesCount,
+		}
+		totalVotes += option.VotesCount
+	}
+	return options, totalVotes
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// recordingPublisher keeps the published events, or fails with err when it
+// is set.
+type recordingPublisher struct {
+	events []events.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) kinds() []events.Kind {
+	kinds := make([]events.Kind, len(p.events))
+	for i, event := range p.events {
+		kinds[i] = event.Kind()
+	}
+	return kinds
+}
+
+// newTestRoom returns a service on the memory store along with a room it
+// created and the context of the host of that room.
+func newTestRoom(t *testing.T) (*RoomsService, *recordingPublisher, uuid.UUID, context.Context) {
+	t.Helper()
+
+	store := repositories.NewMemoryStore()
+	publisher := &recordingPublisher{}
+	service := NewRoomsService(store.Rooms, publisher, store.UnitOfWork, &mappers.RoomMapper{},
+		&mappers.MessageMapper{}, access.NewGrantSigner(nil), nil)
+
+	room, err := service.CreateRoom(context.Background(), &request.RoomRequest{Subject: "Events"})
+	if err != nil {
+		t.Fatalf("creating the room: %v", err)
+	}
+	hostCtx := context.WithValue(context.Background(), middlewares.RoomAccessKey, room.HostAccess.Token)
+	return service, publisher, uuid.MustParse(room.ID), hostCtx
+}
+
+func TestRoomsServicePublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	service, publisher, roomId, hostCtx := newTestRoom(t)
+
+	messageId, err := service.CreateRoomMessage(ctx, &request.MessageRequest{RoomID: roomId, Message: "Is it live?"})
+	if err != nil {
+		t.Fatalf("creating the message: %v", err)
+	}
+	if _, err := service.ReactToRoomMessage(ctx, roomId, messageId, DefaultReactionKind); err != nil {
+		t.Fatalf("reacting: %v", err)
+	}
+	if err := service.AnswerRoomMessage(hostCtx, roomId, messageId); err != nil {
+		t.Fatalf("answering: %v", err)
+	}
+	if _, err := service.CloseRoom(ctx, roomId); err != nil {
+		t.Fatalf("closing the room: %v", err)
+	}
+
+	want := []events.Kind{
+		events.KindMessageCreated,
+		events.KindReactionChanged,
+		events.KindScoreChanged,
+		events.KindMessageAnswered,
+		events.KindRoomClosed,
+	}
+	if got := publisher.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+
+	created := publisher.events[0].(events.MessageCreated)
+	if created.RoomID != roomId || created.MessageID != messageId || created.Message != "Is it live?" {
+		t.Errorf("message_created = %+v, want the created message", created)
+	}
+	reaction := publisher.events[1].(events.ReactionChanged)
+	if reaction.MessageID != messageId || !reaction.Added || reaction.Count != 1 {
+		t.Errorf("reaction_changed = %+v, want a first like added", reaction)
+	}
+	if answered := publisher.events[3].(events.MessageAnswered); answered.MessageID != messageId {
+		t.Errorf("message_answered = %+v, want message %s", answered, messageId)
+	}
+	if closed := publisher.events[4].(events.RoomClosed); closed.RoomID != roomId || closed.Reason != events.CloseReasonManual {
+		t.Errorf("room_closed = %+v, want room %s closed manually", closed, roomId)
+	}
+}
+
+func TestRoomsServicePublishesNothingWhenWritesFail(t *testing.T) {
+	ctx := context.Background()
+	service, publisher, roomId, hostCtx := newTestRoom(t)
+
+	messageId, err := service.CreateRoomMessage(ctx, &request.MessageRequest{RoomID: roomId, Message: "Is it live?"})
+	if err != nil {
+		t.Fatalf("creating the message: %v", err)
+	}
+	published := len(publisher.events)
+
+	// a participant can't answer
+	if err := service.AnswerRoomMessage(ctx, roomId, messageId); err == nil {
+		t.Error("answering without the host grant succeeded")
+	}
+	if _, err := service.ReactToRoomMessage(ctx, roomId, messageId, "love"); err == nil {
+		t.Error("reacting with a kind the room doesn't allow succeeded")
+	}
+	if _, err := service.ReactToRoomMessage(ctx, roomId, uuid.New(), DefaultReactionKind); err == nil {
+		t.Error("reacting to a missing message succeeded")
+	}
+	if _, err := service.CloseRoom(ctx, uuid.New()); err == nil {
+		t.Error("closing a missing room succeeded")
+	}
+	if len(publisher.events) != published {
+		t.Fatalf("published %v after the failed writes", publisher.kinds()[published:])
+	}
+
+	if _, err := service.CloseRoom(hostCtx, roomId); err != nil {
+		t.Fatalf("closing the room: %v", err)
+	}
+	published = len(publisher.events)
+
+	// a closed room takes no questions or reactions and can't be closed again
+	if _, err := service.CreateRoomMessage(ctx, &request.MessageRequest{RoomID: roomId, Message: "Too late?"}); err == nil {
+		t.Error("creating a message in a closed room succeeded")
+	}
+	if _, err := service.ReactToRoomMessage(ctx, roomId, messageId, DefaultReactionKind); err == nil {
+		t.Error("reacting in a closed room succeeded")
+	}
+	if _, err := service.CloseRoom(ctx, roomId); err == nil {
+		t.Error("closing a closed room succeeded")
+	}
+	if len(publisher.events) != published {
+		t.Fatalf("published %v after the failed writes", publisher.kinds()[published:])
+	}
+}
+
+func TestRoomsServiceRollsBackWhenPublishingFails(t *testing.T) {
+	ctx := context.Background()
+	service, publisher, roomId, _ := newTestRoom(t)
+	publisher.err = errors.New("outbox unavailable")
+
+	if _, err := service.CreateRoomMessage(ctx, &request.MessageRequest{RoomID: roomId, Message: "Is it live?"}); err == nil {
+		t.Fatal("creating the message succeeded without its event")
+	}
+	messages, err := service.GetRoomMessages(ctx, roomId, ranking.SortCreated, "")
+	if err != nil {
+		t.Fatalf("listing the messages: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("found %d messages, want the message rolled back with its event", len(messages))
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// PinRoomMessage pins or unpins a message. Pinned messages are listed before
+// every other message of the room, whatever the requested ordering.
+func (s *RoomsService) PinRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID, pinned bool) (*response.MessageResponse, error) {
+	var message *models.Message
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.lockHostedRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if _, err := s.findMessageIn(ctx, room, messageId); err != nil {
+			return err
+		}
+
+		message, err = s.repository.SetMessagePinned(ctx, messageId, pinned)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.MessagePinned{
+			RoomID:    roomId,
+			MessageID: messageId,
+			Pinned:    pinned,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.messageMapper.ToResponse(message), nil
+}
+
+// SpotlightRoomMessage marks the message the host is answering now. A room has
+// at most one spotlight, so it replaces the previous one. A nil messageId
+// clears the spotlight.
+func (s *RoomsService) SpotlightRoomMessage(ctx context.Context, roomId uuid.UUID, messageId *uuid.UUID) (*response.RoomResponse, error) {
+	var room *models.Room
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.lockHostedRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if messageId != nil {
+			if _, err := s.findMessageIn(ctx, locked, *messageId); err != nil {
+				return err
+			}
+		}
+
+		room, err = s.repository.UpdateRoomSpotlight(ctx, roomId, messageId)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.MessageSpotlighted{
+			RoomID:    roomId,
+			MessageID: messageId,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.roomResponse(ctx, room, nil)
+}
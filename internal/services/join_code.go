@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/joincode"
+)
+
+// maxJoinCodeAttempts bounds the codes drawn for a room. With 31^6 possible
+// codes a second one is already rare.
+const maxJoinCodeAttempts = 5
+
+// newJoinCode draws a random code. The unique index on the join codes tells
+// when a room is using it already, the callers draw another one then.
+func newJoinCode(ctx context.Context) (string, error) {
+	code, err := joincode.Generate()
+	if err != nil {
+		return "", internal_errors.NewErrInternal(ctx, err)
+	}
+	return code, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// takenCodesRepository rejects the first join codes it is given, as if
+// other rooms were using them.
+type takenCodesRepository struct {
+	repositories.RoomsRepository
+	taken int
+	codes []string
+}
+
+func (r *takenCodesRepository) conflict(ctx context.Context, code string) error {
+	r.codes = append(r.codes, code)
+	if len(r.codes) <= r.taken {
+		return internal_errors.NewErrConflict(ctx, "ROOM_JOIN_CODE_CONFLICT")
+	}
+	return nil
+}
+
+func (r *takenCodesRepository) SaveRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
+	if err := r.conflict(ctx, room.JoinCode); err != nil {
+		return &models.Room{}, err
+	}
+	return r.RoomsRepository.SaveRoom(ctx, room)
+}
+
+func (r *takenCodesRepository) UpdateRoomJoinCode(ctx context.Context, roomId uuid.UUID, code string) (*models.Room, error) {
+	if err := r.conflict(ctx, code); err != nil {
+		return &models.Room{}, err
+	}
+	return r.RoomsRepository.UpdateRoomJoinCode(ctx, roomId, code)
+}
+
+func TestJoinCodeConflictsDrawAnotherCode(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	repository := &takenCodesRepository{RoomsRepository: store.Rooms, taken: 2}
+	service := newTestService(repository, store.UnitOfWork)
+
+	room, err := service.CreateRoom(ctx, &request.RoomRequest{Subject: "Town Hall"})
+	if err != nil {
+		t.Fatalf("creating the room: %v", err)
+	}
+	if len(repository.codes) != 3 || repository.codes[0] == repository.codes[2] {
+		t.Errorf("tried the codes %v, want a new code after each of the 2 conflicts", repository.codes)
+	}
+
+	repository.codes, repository.taken = nil, 1
+	hostCtx := context.WithValue(ctx, middlewares.RoomAccessKey, room.HostAccess.Token)
+	if _, err := service.RegenerateRoomJoinCode(hostCtx, uuid.MustParse(room.ID)); err != nil {
+		t.Fatalf("regenerating the join code: %v", err)
+	}
+	if len(repository.codes) != 2 {
+		t.Errorf("tried the codes %v, want a new code after the conflict", repository.codes)
+	}
+}
+
+func TestJoinCodeConflictsGiveUp(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	repository := &takenCodesRepository{RoomsRepository: store.Rooms, taken: maxJoinCodeAttempts}
+	service := newTestService(repository, store.UnitOfWork)
+
+	_, err := service.CreateRoom(context.Background(), &request.RoomRequest{Subject: "Town Hall"})
+	if !internal_errors.IsConflict(err, "ROOM_JOIN_CODE_CONFLICT") {
+		t.Errorf("err = %v, want the conflict once every code was taken", err)
+	}
+	if len(repository.codes) != maxJoinCodeAttempts {
+		t.Errorf("tried %d codes, want %d", len(repository.codes), maxJoinCodeAttempts)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+type PollsService struct {
+	repository   repositories.PollsRepository
+	roomsService *RoomsService
+	pollMapper   *mappers.PollMapper
+}
+
+func NewPollsService(repository repositories.PollsRepository, roomsService *RoomsService,
+	pollMapper *mappers.PollMapper) *PollsService {
+	return &PollsService{
+		repository:   repository,
+		roomsService: roomsService,
+		pollMapper:   pollMapper,
+	}
+}
+
+func (s *PollsService) CreatePoll(ctx context.Context, roomId uuid.UUID, params *request.PollRequest) (*response.PollResponse, error) {
+	var pollResponse *response.PollResponse
+	err := s.roomsService.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.roomsService.lockHostedRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if err := checkRoomOpen(ctx, room); err != nil {
+			return err
+		}
+
+		poll, err := s.repository.SavePoll(ctx, roomId, params.Question, params.Options)
+		if err != nil {
+			return err
+		}
+
+		pollResponse = s.pollMapper.ToResponse(poll)
+		options, _ := pollEventOptions(poll)
+		return s.roomsService.publish(ctx, events.PollCreated{
+			RoomID:   roomId,
+			PollID:   poll.ID,
+			Question: poll.Question,
+			Options:  options,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pollResponse, nil
+}
+
+func (s *PollsService) GetRoomPolls(ctx context.Context, roomId uuid.UUID) ([]response.PollResponse, error) {
+	if _, err := s.roomsService.findAccessibleRoom(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	polls, err := s.repository.FindAllRoomPolls(ctx, roomId)
+	responsePolls := make([]response.PollResponse, len(polls))
+	for i, poll := range polls {
+		responsePolls[i] = *s.pollMapper.ToResponse(&poll)
+	}
+	return responsePolls, err
+}
+
+func (s *PollsService) GetPoll(ctx context.Context, roomId uuid.UUID, pollId uuid.UUID) (*response.PollResponse, error) {
+	poll, err := s.findRoomPoll(ctx, roomId, pollId)
+	if err != nil {
+		return nil, err
+	}
+	return s.pollMapper.ToResponse(poll), nil
+}
+
+// VotePoll counts the vote of a participant. Each participant votes once per
+// poll, and only while the poll is open.
+func (s *PollsService) VotePoll(ctx context.Context, roomId uuid.UUID, pollId uuid.UUID, params *request.PollVoteRequest) (*response.PollResponse, error) {
+	poll, err := s.findRoomPoll(ctx, roomId, pollId)
+	if err != nil {
+		return nil, err
+	}
+	if poll.ClosedAt != nil {
+		return nil, internal_errors.NewErrBadRequest(ctx, "POLL_CLOSED")
+	}
+
+	optionId, err := uuid.Parse(params.OptionID)
+	if err != nil || !hasPollOption(poll, optionId) {
+		message, _ := locale.GetMessage(ctx, "INVALID_POLL_OPTION")
+		return nil, internal_errors.NewErrValidation(ctx, []response.ErrorsParam{{Param: "option_id", Message: message}})
+	}
+
+	var pollResponse *response.PollResponse
+	err = s.roomsService.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		counted, err := s.repository.SaveVote(ctx, pollId, optionId, params.ParticipantID)
+		if err != nil {
+			return err
+		}
+		if !counted {
+			return internal_errors.NewErrBadRequest(ctx, "ALREADY_VOTED")
+		}
+
+		poll, err := s.repository.FindPoll(ctx, pollId)
+		if err != nil {
+			return err
+		}
+
+		pollResponse = s.pollMapper.ToResponse(poll)
+		options, totalVotes := pollEventOptions(poll)
+		return s.roomsService.publish(ctx, events.PollVoted{
+			RoomID:     roomId,
+			PollID:     poll.ID,
+			Options:    options,
+			TotalVotes: totalVotes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pollResponse, nil
+}
+
+func (s *PollsService) ClosePoll(ctx context.Context, roomId uuid.UUID, pollId uuid.UUID) (*response.PollResponse, error) {
+	var pollResponse *response.PollResponse
+	err := s.roomsService.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.roomsService.lockHostedRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if _, err := s.findPollIn(ctx, room, pollId); err != nil {
+			return err
+		}
+
+		poll, err := s.repository.ClosePoll(ctx, pollId)
+		if err != nil {
+			return err
+		}
+
+		pollResponse = s.pollMapper.ToResponse(poll)
+		options, totalVotes := pollEventOptions(poll)
+		return s.roomsService.publish(ctx, events.PollClosed{
+			RoomID:     roomId,
+			PollID:     poll.ID,
+			Options:    options,
+			TotalVotes: totalVotes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pollResponse, nil
+}
+
+func (s *PollsService) findRoomPoll(ctx context.Context, roomId uuid.UUID, pollId uuid.UUID) (*models.Poll, error) {
+	room, err := s.roomsService.findAccessibleRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return s.findPollIn(ctx, room, pollId)
+}
+
+// findPollIn finds a poll of room.
+func (s *PollsService) findPollIn(ctx context.Context, room *models.Room, pollId uuid.UUID) (*models.Poll, error) {
+	poll, err := s.repository.FindPoll(ctx, pollId)
+	if err != nil {
+		return nil, err
+	}
+	if poll.RoomID != room.ID {
+		return nil, internal_errors.NewErrNotFound(ctx, "Poll")
+	}
+	return poll, nil
+}
+
+func hasPollOption(poll *models.Poll, optionId uuid.UUID) bool {
+	for _, option := range poll.Options {
+		if option.ID == optionId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// DefaultReactionKind is the reaction behind the like endpoints and the
+// likes_count of a message. Rooms allow only this kind unless configured
+// otherwise.
+const DefaultReactionKind = "like"
+
+func (s *RoomsService) ReactToRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID, kind string) (*response.MessageReactionResponse, error) {
+	var reaction *response.MessageReactionResponse
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkReaction(ctx, roomId, messageId, kind); err != nil {
+			return err
+		}
+
+		count, err := s.repository.ReactToMessage(ctx, messageId, kind)
+		if err != nil {
+			return err
+		}
+		reaction, err = s.reactionResponse(ctx, messageId, kind, count)
+		if err != nil {
+			return err
+		}
+		err = s.publish(ctx, events.ReactionChanged{
+			RoomID:    roomId,
+			MessageID: messageId,
+			Reaction:  kind,
+			Added:     true,
+			Count:     reaction.Count,
+			Reactions: reaction.Reactions,
+		})
+		if err != nil {
+			return err
+		}
+		return s.publishLikeScore(ctx, messageId, kind)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reaction, nil
+}
+
+func (s *RoomsService) RemoveRoomMessageReaction(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID, kind string) (*response.MessageReactionResponse, error) {
+	var reaction *response.MessageReactionResponse
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkReaction(ctx, roomId, messageId, kind); err != nil {
+			return err
+		}
+
+		count, err := s.repository.RemoveReactionFromMessage(ctx, messageId, kind)
+		if err != nil {
+			return err
+		}
+		reaction, err = s.reactionResponse(ctx, messageId, kind, count)
+		if err != nil {
+			return err
+		}
+		err = s.publish(ctx, events.ReactionChanged{
+			RoomID:    roomId,
+			MessageID: messageId,
+			Reaction:  kind,
+			Added:     false,
+			Count:     reaction.Count,
+			Reactions: reaction.Reactions,
+		})
+		if err != nil {
+			return err
+		}
+		return s.publishLikeScore(ctx, messageId, kind)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reaction, nil
+}
+
+// publishLikeScore publishes the new score of a message after a like was
+// given or taken back, likes being part of the score.
+func (s *RoomsService) publishLikeScore(ctx context.Context, messageId uuid.UUID, kind string) error {
+	if kind != DefaultReactionKind {
+		return nil
+	}
+
+	message, err := s.repository.FindMessage(ctx, messageId)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, scoreChangedEvent(message))
+}
+
+func (s *RoomsService) checkReaction(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID, kind string) error {
+	room, err := s.lockOpenRoomMessage(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+	return checkReactionKind(ctx, room, kind)
+}
+
+// lockOpenRoomMessage checks that the caller may vote on a message: the room
+// must be accessible and open, and the message must belong to it. The room
+// is locked so it can't be closed or deleted before the vote is counted, ctx
+// must belong to a unit of work.
+func (s *RoomsService) lockOpenRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (*models.Room, error) {
+	room, err := s.lockAccessibleRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoomOpen(ctx, room); err != nil {
+		return nil, err
+	}
+	if _, err := s.findMessageIn(ctx, room, messageId); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// findMessageIn finds a message of room.
+func (s *RoomsService) findMessageIn(ctx context.Context, room *models.Room, messageId uuid.UUID) (*models.Message, error) {
+	message, err := s.repository.FindMessage(ctx, messageId)
+	if err != nil {
+		return nil, err
+	}
+	// a hidden message is gone for the participants
+	if message.RoomID != room.ID || message.Hidden {
+		return nil, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+	return message, nil
+}
+
+func checkReactionKind(ctx context.Context, room *models.Room, kind string) error {
+	if slices.Contains(room.ReactionKinds, kind) {
+		return nil
+	}
+
+	message, _ := locale.GetMessage(ctx, "REACTION_KIND_NOT_ALLOWED")
+	return internal_errors.NewErrValidation(ctx, []response.ErrorsParam{{Param: "kind", Message: message}})
+}
+
+func (s *RoomsService) reactionResponse(ctx context.Context, messageId uuid.UUID, kind string, count int64) (*response.MessageReactionResponse, error) {
+	reactions, err := s.repository.FindMessageReactions(ctx, messageId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MessageReactionResponse{
+		ID:        messageId.String(),
+		Kind:      kind,
+		Count:     count,
+		Reactions: reactions,
+	}, nil
+}
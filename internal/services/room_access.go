@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/middlewares"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const roomAccessGrantTTL = 12 * time.Hour
+
+// hostGrantTTL outlasts the rooms scheduled weeks ahead, a host renews the
+// grant with the one it holds.
+const hostGrantTTL = 30 * 24 * time.Hour
+
+func hashPasscode(ctx context.Context, passcode string) (string, error) {
+	if passcode == "" {
+		return "", nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.DefaultCost)
+	if err != nil {
+		return "", internal_errors.NewErrInternal(ctx, err)
+	}
+	return string(hash), nil
+}
+
+// checkRoomAccess lets everyone into public rooms without a passcode. Other
+// rooms need a grant issued by GrantRoomAccess to be sent with the
+// request, or an API key, which opens every room.
+func (s *RoomsService) checkRoomAccess(ctx context.Context, room *models.Room) error {
+	if room.PasscodeHash == "" && !room.Private {
+		return nil
+	}
+	if apiKey, _ := ctx.Value(middlewares.APIKeyKey).(bool); apiKey {
+		return nil
+	}
+
+	if _, ok := s.grantRole(ctx, room); !ok {
+		return internal_errors.NewErrForbidden(ctx, "ROOM_ACCESS_REQUIRED")
+	}
+	return nil
+}
+
+// visibleRoomResponse is roomResponse for the requests that may not have
+// access to the room. They only learn what they need to ask for a grant.
+func (s *RoomsService) visibleRoomResponse(ctx context.Context, room *models.Room, err error) (*response.RoomResponse, error) {
+	if err == nil && s.checkRoomAccess(ctx, room) != nil {
+		return &response.RoomResponse{
+			ID:          room.ID.String(),
+			HasPasscode: room.PasscodeHash != "",
+			Private:     room.Private,
+		}, nil
+	}
+	return s.roomResponse(ctx, room, err)
+}
+
+// checkHostAccess fails unless the request was sent with the host grant of
+// the room or an API key. The grants of the participants don't do.
+func (s *RoomsService) checkHostAccess(ctx context.Context, room *models.Room) error {
+	if apiKey, _ := ctx.Value(middlewares.APIKeyKey).(bool); apiKey {
+		return nil
+	}
+
+	if role, ok := s.grantRole(ctx, room); !ok || role != access.RoleHost {
+		return internal_errors.NewErrForbidden(ctx, "HOST_ACCESS_REQUIRED")
+	}
+	return nil
+}
+
+// grantRole returns the role of the grant sent with the request, and false
+// when none was sent or it isn't valid for the room.
+func (s *RoomsService) grantRole(ctx context.Context, room *models.Room) (access.Role, bool) {
+	grant, _ := ctx.Value(middlewares.RoomAccessKey).(string)
+	if grant == "" {
+		return "", false
+	}
+	return s.grantSigner.Verify(grant, room.ID)
+}
+
+// hostAccess issues the host grant of a room.
+func (s *RoomsService) hostAccess(room *models.Room) *response.RoomAccessResponse {
+	expiresAt := time.Now().Add(hostGrantTTL)
+	return &response.RoomAccessResponse{
+		Token:     s.grantSigner.Issue(room.ID, access.RoleHost, expiresAt),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// checkAPIKey fails unless the request was sent with a valid API key.
+func checkAPIKey(ctx context.Context) error {
+	if apiKey, _ := ctx.Value(middlewares.APIKeyKey).(bool); !apiKey {
+		return internal_errors.NewErrForbidden(ctx, "API_KEY_REQUIRED")
+	}
+	return nil
+}
+
+// findAccessibleRoom finds a room and checks the request may read from and
+// write to it.
+func (s *RoomsService) findAccessibleRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := s.repository.FindRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return room, s.checkRoomAccess(ctx, room)
+}
+
+// lockAccessibleRoom is findAccessibleRoom for units of work that write to
+// the room.
+func (s *RoomsService) lockAccessibleRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := s.repository.LockRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return room, s.checkRoomAccess(ctx, room)
+}
+
+// findHostedRoom finds a room and checks the request comes from its host.
+func (s *RoomsService) findHostedRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := s.repository.FindRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return room, s.checkHostAccess(ctx, room)
+}
+
+// lockHostedRoom is findHostedRoom for units of work that write to the room.
+func (s *RoomsService) lockHostedRoom(ctx context.Context, roomId uuid.UUID) (*models.Room, error) {
+	room, err := s.repository.LockRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return room, s.checkHostAccess(ctx, room)
+}
+
+func (s *RoomsService) AuthorizeRoomAccess(ctx context.Context, roomId uuid.UUID) error {
+	_, err := s.findAccessibleRoom(ctx, roomId)
+	return err
+}
+
+func (s *RoomsService) GrantRoomAccess(ctx context.Context, roomId uuid.UUID, params *request.RoomAccessRequest) (*response.RoomAccessResponse, error) {
+	room, err := s.repository.FindRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Host {
+		if err := s.checkHostAccess(ctx, room); err != nil {
+			return nil, err
+		}
+		return s.hostAccess(room), nil
+	}
+
+	switch {
+	case room.PasscodeHash != "":
+		if err := bcrypt.CompareHashAndPassword([]byte(room.PasscodeHash), []byte(params.Passcode)); err != nil {
+			return nil, internal_errors.NewErrForbidden(ctx, "INVALID_PASSCODE")
+		}
+	case room.Private:
+		// with no passcode to present, the grant is the invitation
+		if err := checkAPIKey(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := time.Now().Add(roomAccessGrantTTL)
+	return &response.RoomAccessResponse{
+		Token:     s.grantSigner.Issue(room.ID, access.RoleParticipant, expiresAt),
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/joincode"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// SearchRooms finds the rooms whose subject, slug or host name contains
+// query, and the room query is the join code of. Private rooms are found
+// too, the search is meant for the admins.
+func (s *RoomsService) SearchRooms(ctx context.Context, query string) ([]response.RoomResponse, error) {
+	// a query that can't be a join code matches none
+	joinCode, _ := joincode.Normalize(query)
+	rooms, err := s.repository.SearchRooms(ctx, query, joinCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTagCounts(ctx, rooms); err != nil {
+		return nil, err
+	}
+	responseRooms := make([]response.RoomResponse, len(rooms))
+	for i, room := range rooms {
+		responseRooms[i] = *s.roomMapper.ToResponse(&room)
+	}
+	return responseRooms, nil
+}
+
+// GetRoomStats counts the messages of a room and what they got, the hidden
+// messages included.
+func (s *RoomsService) GetRoomStats(ctx context.Context, roomId uuid.UUID) (*response.RoomStatsResponse, error) {
+	if _, err := s.repository.FindRoom(ctx, roomId); err != nil {
+		return nil, err
+	}
+	messages, err := s.repository.FindAllRoomMessages(ctx, roomId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &response.RoomStatsResponse{
+		RoomID:    roomId.String(),
+		Reactions: map[string]int64{},
+	}
+	for _, message := range messages {
+		stats.MessagesCount++
+		if message.Answered {
+			stats.AnsweredCount++
+		}
+		if message.Pinned {
+			stats.PinnedCount++
+		}
+		if len(message.ModerationFlags) > 0 {
+			stats.FlaggedCount++
+		}
+		if message.Hidden {
+			stats.HiddenCount++
+		}
+		stats.LikesCount += message.LikesCount
+		stats.DownvotesCount += message.DownvotesCount
+		for kind, count := range message.Reactions {
+			stats.Reactions[kind] += count
+		}
+		if stats.LastMessageAt == nil || message.CreatedAt.After(*stats.LastMessageAt) {
+			createdAt := message.CreatedAt
+			stats.LastMessageAt = &createdAt
+		}
+	}
+	return stats, nil
+}
+
+// CloseRoom closes a room before its scheduled end, the participants are
+// told with a room_closed of reason manual.
+func (s *RoomsService) CloseRoom(ctx context.Context, roomId uuid.UUID) (*response.RoomResponse, error) {
+	var room *models.Room
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.repository.LockRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if err := checkRoomOpen(ctx, locked); err != nil {
+			return err
+		}
+
+		room, err = s.repository.CloseRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.RoomClosed{RoomID: roomId, Reason: events.CloseReasonManual})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.roomResponse(ctx, room, nil)
+}
+
+// ReopenRoom lets a closed room take questions again. The close jobs may
+// close it again once it ended or went inactive.
+func (s *RoomsService) ReopenRoom(ctx context.Context, roomId uuid.UUID) (*response.RoomResponse, error) {
+	var room *models.Room
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.repository.LockRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if locked.ClosedAt == nil {
+			return internal_errors.NewErrBadRequest(ctx, "ROOM_NOT_CLOSED")
+		}
+
+		room, err = s.repository.ReopenRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.RoomReopened{RoomID: roomId})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.roomResponse(ctx, room, nil)
+}
+
+// HideRoomMessage takes a message out of its room, or puts it back. Hidden
+// messages are left out of the listings and the exports and can't get
+// reactions, but they keep counting in the stats.
+func (s *RoomsService) HideRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID, hidden bool) (*response.MessageResponse, error) {
+	message, err := s.repository.FindMessage(ctx, messageId)
+	if err != nil {
+		return nil, err
+	}
+	if message.RoomID != roomId {
+		return nil, internal_errors.NewErrNotFound(ctx, "Message")
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		message, err = s.repository.SetMessageHidden(ctx, messageId, hidden)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.MessageHidden{
+			RoomID:    roomId,
+			MessageID: messageId,
+			Hidden:    hidden,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.messageMapper.ToResponse(message), nil
+}
+
+// RebuildLikeCounts sets the like counts of the messages of a room back to
+// their like reactions and returns how many were off.
+func (s *RoomsService) RebuildLikeCounts(ctx context.Context, roomId uuid.UUID) (int64, error) {
+	if _, err := s.repository.FindRoom(ctx, roomId); err != nil {
+		return 0, err
+	}
+	return s.repository.RecountMessageLikes(ctx, roomId)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// checkRoomOpen rejects new questions and reactions once a room was closed.
+func checkRoomOpen(ctx context.Context, room *models.Room) error {
+	if room.ClosedAt != nil {
+		return internal_errors.NewErrBadRequest(ctx, "ROOM_CLOSED")
+	}
+	return nil
+}
+
+// CloseEndedRooms closes every open room whose scheduled end lies before now.
+func (s *RoomsService) CloseEndedRooms(ctx context.Context, now time.Time) (int64, error) {
+	return s.closeRooms(ctx, events.CloseReasonEnded, func(ctx context.Context) ([]uuid.UUID, error) {
+		return s.repository.CloseEndedRooms(ctx, now)
+	})
+}
+
+// CloseInactiveRooms closes every open room that got no question since
+// inactiveSince.
+func (s *RoomsService) CloseInactiveRooms(ctx context.Context, inactiveSince time.Time) (int64, error) {
+	return s.closeRooms(ctx, events.CloseReasonInactive, func(ctx context.Context) ([]uuid.UUID, error) {
+		return s.repository.CloseInactiveRooms(ctx, inactiveSince)
+	})
+}
+
+// closeRooms publishes a RoomClosed for each room closeAll closed and returns
+// how many there were.
+func (s *RoomsService) closeRooms(ctx context.Context, reason events.CloseReason,
+	closeAll func(ctx context.Context) ([]uuid.UUID, error)) (int64, error) {
+	var closed []uuid.UUID
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		closed, err = closeAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, roomId := range closed {
+			if err := s.publish(ctx, events.RoomClosed{RoomID: roomId, Reason: reason}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(closed)), nil
+}
+
+// PurgeArchivedRooms deletes rooms closed before closedBefore together with
+// their messages, and publishes a RoomDeleted for each of them.
+func (s *RoomsService) PurgeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error) {
+	var deleted []uuid.UUID
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.repository.DeleteArchivedRooms(ctx, closedBefore)
+		if err != nil {
+			return err
+		}
+
+		for _, roomId := range deleted {
+			if err := s.publish(ctx, events.RoomDeleted{RoomID: roomId}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(deleted)), nil
+}
+
+// AnonymizeArchivedRooms keeps rooms closed before closedBefore and their
+// counters, but wipes the subjects, the question texts, the host details
+// and who voted in the polls.
+func (s *RoomsService) AnonymizeArchivedRooms(ctx context.Context, closedBefore time.Time) (int64, error) {
+	return s.repository.AnonymizeArchivedRooms(ctx, closedBefore)
+}
@@ -3,45 +3,127 @@ package services
 import (
 	"context"
 	"errors"
+	"io"
+	"slices"
+	"time"
 
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/contentfilter"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
 	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/exporter"
+	"github.com/JulioZittei/wsrs-ama-go/internal/importer"
 	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/joincode"
 	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
 	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/JulioZittei/wsrs-ama-go/internal/validator"
 	"github.com/google/uuid"
 )
 
 type RoomsService struct {
-	repository    *repositories.RoomsRepository
+	repository    repositories.RoomsRepository
+	publisher     events.Publisher
+	unitOfWork    repositories.UnitOfWork
 	roomMapper    *mappers.RoomMapper
 	messageMapper *mappers.MessageMapper
+	grantSigner   *access.GrantSigner
+	filters       contentfilter.Chain
 }
 
-func NewRoomsService(repository *repositories.RoomsRepository, roomMapper *mappers.RoomMapper,
-	messageMapper *mappers.MessageMapper) *RoomsService {
+func NewRoomsService(repository repositories.RoomsRepository, publisher events.Publisher, unitOfWork repositories.UnitOfWork,
+	roomMapper *mappers.RoomMapper, messageMapper *mappers.MessageMapper, grantSigner *access.GrantSigner, filters contentfilter.Chain) *RoomsService {
 	return &RoomsService{
 		repository:    repository,
+		publisher:     publisher,
+		unitOfWork:    unitOfWork,
 		roomMapper:    roomMapper,
 		messageMapper: messageMapper,
+		grantSigner:   grantSigner,
+		filters:       filters,
 	}
 }
 
 func (s *RoomsService) GetRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (*response.MessageResponse, error) {
-	_, err := s.repository.FindRoom(ctx, roomId)
+	room, err := s.findAccessibleRoom(ctx, roomId)
 	if err != nil {
 		return nil, err
 	}
-	message, err := s.repository.FindMessage(ctx, messageId)
-	return s.messageMapper.ToResponse(message), err
+	message, err := s.findMessageIn(ctx, room, messageId)
+	if err != nil {
+		return nil, err
+	}
+	return s.messageMapper.ToResponse(message), nil
 }
 
-func (s *RoomsService) CreateRoom(ctx context.Context, room *request.RoomRequest) (uuid.UUID, error) {
-	return s.repository.SaveRoom(ctx, room.Subject)
+// CreateRoom saves a room and issues its host grant, which the host sends to
+// manage the room.
+func (s *RoomsService) CreateRoom(ctx context.Context, params *request.RoomRequest) (*response.CreatedRoomResponse, error) {
+	slug, err := s.roomSlug(ctx, params.Slug, params.Subject, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := newJoinCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	passcodeHash, err := hashPasscode(ctx, params.Passcode)
+	if err != nil {
+		return nil, err
+	}
+
+	room := s.roomMapper.RequestToModel(params)
+	if len(room.ReactionKinds) == 0 {
+		room.ReactionKinds = []string{DefaultReactionKind}
+	}
+	room.Slug = slug
+	room.JoinCode = code
+	room.PasscodeHash = passcodeHash
+
+	saved, err := s.saveRoom(ctx, room, params.Slug == "")
+	roomResponse, err := s.roomResponse(ctx, saved, err)
+	if err != nil {
+		return nil, err
+	}
+	return &response.CreatedRoomResponse{
+		RoomResponse: *roomResponse,
+		HostAccess:   *s.hostAccess(saved),
+	}, nil
+}
+
+// saveRoom inserts a room. When another room took its join code, or its
+// derived slug, in the meantime another one is picked and the insert is
+// tried again.
+func (s *RoomsService) saveRoom(ctx context.Context, room *models.Room, derivedSlug bool) (*models.Room, error) {
+	codes, slugs := 1, 1
+	for {
+		saved, err := s.repository.SaveRoom(ctx, room)
+		switch {
+		case codes < maxJoinCodeAttempts && internal_errors.IsConflict(err, "ROOM_JOIN_CODE_CONFLICT"):
+			codes++
+			room.JoinCode, err = newJoinCode(ctx)
+		case derivedSlug && slugs < maxSlugAttempts && internal_errors.IsConflict(err, "ROOM_SLUG_CONFLICT"):
+			slugs++
+			room.Slug, err = s.roomSlug(ctx, "", room.Subject, uuid.Nil)
+		default:
+			return saved, err
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 }
 
 func (s *RoomsService) GetRooms(ctx context.Context) ([]response.RoomResponse, error) {
 	rooms, err := s.repository.FindAllRooms(ctx)
+	if err == nil {
+		err = s.loadTagCounts(ctx, rooms)
+	}
 	responseRooms := make([]response.RoomResponse, len(rooms))
 
 	for i, room := range rooms {
@@ -53,15 +135,153 @@ func (s *RoomsService) GetRooms(ctx context.Context) ([]response.RoomResponse, e
 
 func (s *RoomsService) GetRoom(ctx context.Context, roomId uuid.UUID) (*response.RoomResponse, error) {
 	room, err := s.repository.FindRoom(ctx, roomId)
-	return s.roomMapper.ToResponse(room), err
+	return s.visibleRoomResponse(ctx, room, err)
+}
+
+func (s *RoomsService) UpdateRoom(ctx context.Context, roomId uuid.UUID, params *request.UpdateRoomRequest) (*response.RoomResponse, error) {
+	room, err := s.findHostedRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Subject != nil {
+		room.Subject = *params.Subject
+	}
+	if params.Description != nil {
+		room.Description = *params.Description
+	}
+	if params.StartsAt != nil {
+		room.StartsAt = params.StartsAt
+	}
+	if params.EndsAt != nil {
+		room.EndsAt = params.EndsAt
+	}
+	if params.HostName != nil {
+		room.HostName = *params.HostName
+	}
+	if params.CoverImageURL != nil {
+		room.CoverImageURL = *params.CoverImageURL
+	}
+	if params.ReactionKinds != nil {
+		room.ReactionKinds = params.ReactionKinds
+	}
+	if params.Tags != nil {
+		room.Tags = params.Tags
+	}
+	if params.DownvotesEnabled != nil {
+		room.DownvotesEnabled = *params.DownvotesEnabled
+	}
+	if params.Private != nil {
+		room.Private = *params.Private
+	}
+	// an empty passcode removes it
+	if params.Passcode != nil {
+		room.PasscodeHash, err = hashPasscode(ctx, *params.Passcode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.Slug != nil && slugify(*params.Slug) != room.Slug {
+		room.Slug, err = s.roomSlug(ctx, *params.Slug, room.Subject, room.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the schedule may only be valid once merged with the stored values
+	if err := validator.ValidateStruct(ctx, s.roomMapper.ToRequest(room)); err != nil {
+		return nil, err
+	}
+
+	var roomResponse *response.RoomResponse
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		updated, err := s.repository.UpdateRoom(ctx, room)
+		roomResponse, err = s.roomResponse(ctx, updated, err)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, roomUpdatedEvent(updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roomResponse, nil
+}
+
+// DeleteRoom removes a room for good. Its messages are removed along with it
+// by the ON DELETE CASCADE on messages.room_id.
+func (s *RoomsService) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.lockHostedRoom(ctx, roomId); err != nil {
+			return err
+		}
+		if err := s.repository.DeleteRoom(ctx, roomId); err != nil {
+			return err
+		}
+		return s.publish(ctx, events.RoomDeleted{RoomID: roomId})
+	})
+}
+
+// GetRoomByJoinCode resolves a join code in any of the forms accepted by
+// joincode.Normalize.
+func (s *RoomsService) GetRoomByJoinCode(ctx context.Context, code string) (*response.RoomResponse, error) {
+	normalizedCode, ok := joincode.Normalize(code)
+	if !ok {
+		return nil, internal_errors.NewErrBadRequest(ctx, "INVALID_JOIN_CODE")
+	}
+
+	room, err := s.repository.FindRoomByJoinCode(ctx, normalizedCode)
+	return s.visibleRoomResponse(ctx, room, err)
+}
+
+func (s *RoomsService) RegenerateRoomJoinCode(ctx context.Context, roomId uuid.UUID) (*response.RoomResponse, error) {
+	// the conflict rolls the unit of work back, so the next code is tried
+	// in a new one
+	for attempt := 1; ; attempt++ {
+		roomResponse, err := s.regenerateRoomJoinCode(ctx, roomId)
+		if attempt == maxJoinCodeAttempts || !internal_errors.IsConflict(err, "ROOM_JOIN_CODE_CONFLICT") {
+			return roomResponse, err
+		}
+	}
 }
 
-func (s *RoomsService) GetRoomMessages(ctx context.Context, roomId uuid.UUID) ([]response.MessageResponse, error) {
-	_, err := s.repository.FindRoom(ctx, roomId)
+func (s *RoomsService) regenerateRoomJoinCode(ctx context.Context, roomId uuid.UUID) (*response.RoomResponse, error) {
+	code, err := newJoinCode(ctx)
 	if err != nil {
 		return nil, err
 	}
-	messages, err := s.repository.FindAllRoomMessages(ctx, roomId)
+
+	var roomResponse *response.RoomResponse
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.lockHostedRoom(ctx, roomId); err != nil {
+			return err
+		}
+		updated, err := s.repository.UpdateRoomJoinCode(ctx, roomId, code)
+		roomResponse, err = s.roomResponse(ctx, updated, err)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, roomUpdatedEvent(updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roomResponse, nil
+}
+
+func (s *RoomsService) GetRoomMessages(ctx context.Context, roomId uuid.UUID, by ranking.Sort, tag string) ([]response.MessageResponse, error) {
+	room, err := s.findAccessibleRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMessageTag(ctx, room, tag); err != nil {
+		return nil, err
+	}
+	messages, err := s.repository.FindAllRoomMessages(ctx, roomId, tag)
+	messages = slices.DeleteFunc(messages, func(message models.Message) bool {
+		return message.Hidden
+	})
+	ranking.SortMessages(messages, by, time.Now())
 	responseMessages := make([]response.MessageResponse, len(messages))
 
 	for i, message := range messages {
@@ -71,37 +291,190 @@ func (s *RoomsService) GetRoomMessages(ctx context.Context, roomId uuid.UUID) ([
 	return responseMessages, err
 }
 
+// CreateRoomMessage saves a message while holding the lock of its room, so
+// the room can't be closed or deleted between the checks and the insert.
 func (s *RoomsService) CreateRoomMessage(ctx context.Context, params *request.MessageRequest) (uuid.UUID, error) {
-	_, err := s.repository.FindRoom(ctx, params.RoomID)
+	messageId := uuid.Nil
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.lockAccessibleRoom(ctx, params.RoomID)
+		if err != nil {
+			if errors.Is(err, internal_errors.ErrNotFound) {
+				return internal_errors.NewErrBadRequest(ctx, "room not found")
+			}
+			return err
+		}
+		if err := checkRoomOpen(ctx, room); err != nil {
+			return err
+		}
+		if err := checkMessageTag(ctx, room, params.Tag); err != nil {
+			return err
+		}
+		moderationFlags, err := s.filterMessage(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		messageId, err = s.repository.SaveMessage(ctx, params, moderationFlags)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, events.MessageCreated{
+			RoomID:    params.RoomID,
+			MessageID: messageId,
+			Message:   params.Message,
+			Tag:       params.Tag,
+		})
+	})
+	return messageId, err
+}
+
+func (s *RoomsService) AnswerRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.lockHostedRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if _, err := s.findMessageIn(ctx, room, messageId); err != nil {
+			return err
+		}
+
+		if err := s.repository.MarkMessageAsAnswered(ctx, messageId); err != nil {
+			return err
+		}
+		return s.publish(ctx, events.MessageAnswered{
+			RoomID:    roomId,
+			MessageID: messageId,
+		})
+	})
+}
+
+func (s *RoomsService) ExportRoom(ctx context.Context, roomId uuid.UUID, format exporter.Format, w io.Writer) error {
+	room, err := s.findAccessibleRoom(ctx, roomId)
 	if err != nil {
-		if errors.Is(err, internal_errors.ErrNotFound) {
-			return params.RoomID, internal_errors.NewErrBadRequest(ctx, "room not found")
+		return err
+	}
+
+	roomExporter := exporter.NewRoomExporter(format, w)
+	if err := roomExporter.Begin(room); err != nil {
+		return err
+	}
+
+	err = s.repository.StreamRoomMessages(ctx, roomId, func(message *models.Message) error {
+		if message.Hidden {
+			return nil
 		}
-		return params.RoomID, err
+		return roomExporter.WriteMessage(message)
+	})
+	if err != nil {
+		return err
 	}
-	return s.repository.SaveMessage(ctx, params)
+
+	return roomExporter.End()
 }
 
-func (s *RoomsService) LikeRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (int64, error) {
-	_, err := s.repository.FindRoom(ctx, roomId)
+// ImportRoomMessages validates every row of an import file and saves the valid
+// ones in a single statement, so either all of them are stored or none is.
+// Invalid rows are skipped and reported with their 1-based row number. With
+// notify the subscribers of the room get a message_created event for every
+// imported message.
+func (s *RoomsService) ImportRoomMessages(ctx context.Context, roomId uuid.UUID, format importer.Format, r io.Reader, notify bool) (*response.MessageImportResponse, error) {
+	room, err := s.findAccessibleRoom(ctx, roomId)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if err := checkRoomOpen(ctx, room); err != nil {
+		return nil, err
 	}
-	return s.repository.LikeMessage(ctx, messageId)
-}
 
-func (s *RoomsService) RemoveLikeRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (int64, error) {
-	_, err := s.repository.FindRoom(ctx, roomId)
+	rows, err := importer.ReadMessages(format, r)
 	if err != nil {
-		return 0, err
+		return nil, internal_errors.NewErrBadRequest(ctx, "INVALID_IMPORT_FILE")
 	}
-	return s.repository.RemoveLikeMessage(ctx, messageId)
-}
 
-func (s *RoomsService) AnswerRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) error {
-	_, err := s.repository.FindRoom(ctx, roomId)
+	result := &response.MessageImportResponse{
+		Messages: []response.MessageResponse{},
+		Errors:   []response.MessageImportErrorResponse{},
+	}
+
+	messages := make([]string, 0, len(rows))
+	tags := make([]string, 0, len(rows))
+	flags := make([][]string, 0, len(rows))
+	for i, row := range rows {
+		row.RoomID = roomId
+		var moderationFlags []string
+		err := validator.ValidateStruct(ctx, &row)
+		if err == nil {
+			err = checkMessageTag(ctx, room, row.Tag)
+		}
+		if err == nil {
+			moderationFlags, err = s.filterMessage(ctx, &row)
+		}
+		if err != nil {
+			var errValidation *internal_errors.ErrorValidation
+			if !errors.As(err, &errValidation) {
+				return nil, err
+			}
+
+			result.Errors = append(result.Errors, response.MessageImportErrorResponse{
+				Row:           i + 1,
+				InvalidParams: errValidation.ErrorsParam,
+			})
+			continue
+		}
+		messages = append(messages, row.Message)
+		tags = append(tags, row.Tag)
+		flags = append(flags, moderationFlags)
+	}
+	result.Failed = len(result.Errors)
+
+	if len(messages) == 0 {
+		return result, nil
+	}
+
+	// the file may take a while to read, so the room is checked again once
+	// it is locked
+	var messageIds []uuid.UUID
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		room, err := s.repository.LockRoom(ctx, roomId)
+		if err != nil {
+			return err
+		}
+		if err := checkRoomOpen(ctx, room); err != nil {
+			return err
+		}
+
+		messageIds, err = s.repository.SaveMessages(ctx, roomId, messages, tags, flags)
+		if err != nil || !notify {
+			return err
+		}
+
+		for i, messageId := range messageIds {
+			err := s.publish(ctx, events.MessageCreated{
+				RoomID:    roomId,
+				MessageID: messageId,
+				Message:   messages[i],
+				Tag:       tags[i],
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	for i, messageId := range messageIds {
+		result.Messages = append(result.Messages, response.MessageResponse{
+			ID:              messageId.String(),
+			RoomID:          roomId.String(),
+			Message:         messages[i],
+			Tag:             tags[i],
+			ModerationFlags: flags[i],
+		})
 	}
-	return s.repository.MarkMessageAsAnswered(ctx, messageId)
+	result.Imported = len(result.Messages)
+
+	return result, nil
 }
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/ranking"
+	"github.com/google/uuid"
+)
+
+func (s *RoomsService) DownvoteRoomMessage(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (*response.MessageScoreResponse, error) {
+	var score *response.MessageScoreResponse
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkDownvote(ctx, roomId, messageId); err != nil {
+			return err
+		}
+
+		_, err := s.repository.DownvoteMessage(ctx, messageId)
+		if err != nil {
+			return err
+		}
+
+		message, err := s.repository.FindMessage(ctx, messageId)
+		if err != nil {
+			return err
+		}
+		score = scoreResponse(message)
+		return s.publish(ctx, scoreChangedEvent(message))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+func (s *RoomsService) RemoveRoomMessageDownvote(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) (*response.MessageScoreResponse, error) {
+	var score *response.MessageScoreResponse
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkDownvote(ctx, roomId, messageId); err != nil {
+			return err
+		}
+
+		_, err := s.repository.RemoveDownvoteFromMessage(ctx, messageId)
+		if err != nil {
+			return err
+		}
+
+		message, err := s.repository.FindMessage(ctx, messageId)
+		if err != nil {
+			return err
+		}
+		score = scoreResponse(message)
+		return s.publish(ctx, scoreChangedEvent(message))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+func (s *RoomsService) checkDownvote(ctx context.Context, roomId uuid.UUID, messageId uuid.UUID) error {
+	room, err := s.lockOpenRoomMessage(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+	if !room.DownvotesEnabled {
+		return internal_errors.NewErrBadRequest(ctx, "DOWNVOTES_DISABLED")
+	}
+	return nil
+}
+
+func scoreResponse(message *models.Message) *response.MessageScoreResponse {
+	return &response.MessageScoreResponse{
+		ID:             message.ID.String(),
+		LikesCount:     message.LikesCount,
+		DownvotesCount: message.DownvotesCount,
+		Score:          ranking.NetScore(message.LikesCount, message.DownvotesCount),
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/google/uuid"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxSlugLength = 64
+
+// maxSlugAttempts bounds the inserts of a room whose slug another room took
+// between picking and saving it.
+const maxSlugAttempts = 3
+
+func slugify(value string) string {
+	value, _, _ = transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), value)
+
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(value) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && builder.Len() > 0 {
+			builder.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(builder.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return "room"
+	}
+	return slug
+}
+
+// roomSlug picks the slug of a room. A slug chosen by the host must be free,
+// a slug derived from the subject gets the lowest numeric suffix that is. The
+// unique index on the slugs settles the rooms saved at the same time.
+func (s *RoomsService) roomSlug(ctx context.Context, requested string, subject string, roomId uuid.UUID) (string, error) {
+	if requested != "" {
+		slug := slugify(requested)
+		taken, err := s.repository.IsRoomSlugTaken(ctx, slug, roomId)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			message, _ := locale.GetMessage(ctx, "SLUG_TAKEN")
+			return "", internal_errors.NewErrValidation(ctx, []response.ErrorsParam{{Param: "slug", Message: message}})
+		}
+		return slug, nil
+	}
+
+	base := slugify(subject)
+	slugs, err := s.repository.FindRoomSlugs(ctx, base, roomId)
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		taken[slug] = true
+	}
+
+	slug := base
+	for i := 2; taken[slug]; i++ {
+		slug = base + "-" + strconv.Itoa(i)
+	}
+	return slug, nil
+}
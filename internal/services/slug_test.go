@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/access"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// staleSlugsRepository misses the slugs taken when its first lookup runs,
+// as if another room took them right after.
+type staleSlugsRepository struct {
+	repositories.RoomsRepository
+	looked bool
+}
+
+func (r *staleSlugsRepository) FindRoomSlugs(ctx context.Context, slug string, roomId uuid.UUID) ([]string, error) {
+	if !r.looked {
+		r.looked = true
+		return []string{}, nil
+	}
+	return r.RoomsRepository.FindRoomSlugs(ctx, slug, roomId)
+}
+
+func newTestService(repository repositories.RoomsRepository, unitOfWork repositories.UnitOfWork) *RoomsService {
+	return NewRoomsService(repository, &recordingPublisher{}, unitOfWork, &mappers.RoomMapper{},
+		&mappers.MessageMapper{}, access.NewGrantSigner(nil), nil)
+}
+
+func TestRoomSlugTakesTheLowestFreeSuffix(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	service := newTestService(store.Rooms, store.UnitOfWork)
+
+	var ids []string
+	for _, want := range []string{"town-hall", "town-hall-2", "town-hall-3"} {
+		room, err := service.CreateRoom(ctx, &request.RoomRequest{Subject: "Town Hall"})
+		if err != nil {
+			t.Fatalf("creating the room: %v", err)
+		}
+		if room.Slug != want {
+			t.Errorf("slug = %q, want %q", room.Slug, want)
+		}
+		ids = append(ids, room.ID)
+	}
+
+	if err := store.Rooms.DeleteRoom(ctx, uuid.MustParse(ids[1])); err != nil {
+		t.Fatalf("deleting the room: %v", err)
+	}
+	room, err := service.CreateRoom(ctx, &request.RoomRequest{Subject: "Town Hall"})
+	if err != nil {
+		t.Fatalf("creating the room: %v", err)
+	}
+	if room.Slug != "town-hall-2" {
+		t.Errorf("slug = %q, want the freed town-hall-2", room.Slug)
+	}
+}
+
+func TestCreateRoomRetriesATakenSlug(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	if _, err := newTestService(store.Rooms, store.UnitOfWork).CreateRoom(ctx, &request.RoomRequest{Subject: "Town Hall"}); err != nil {
+		t.Fatalf("creating the first room: %v", err)
+	}
+
+	service := newTestService(&staleSlugsRepository{RoomsRepository: store.Rooms}, store.UnitOfWork)
+	room, err := service.CreateRoom(ctx, &request.RoomRequest{Subject: "Town Hall"})
+	if err != nil {
+		t.Fatalf("creating the room after its slug was taken: %v", err)
+	}
+	if room.Slug != "town-hall-2" {
+		t.Errorf("slug = %q, want town-hall-2", room.Slug)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	locale "github.com/JulioZittei/wsrs-ama-go/internal/locale/message_bundle"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// checkMessageTag accepts untagged messages and messages tagged with one of
+// the tags defined by the host of the room.
+func checkMessageTag(ctx context.Context, room *models.Room, tag string) error {
+	if tag == "" || slices.Contains(room.Tags, tag) {
+		return nil
+	}
+
+	message, _ := locale.GetMessage(ctx, "TAG_NOT_ALLOWED")
+	return internal_errors.NewErrValidation(ctx, []response.ErrorsParam{{Param: "tag", Message: message}})
+}
+
+// loadTagCounts fills in the number of messages per tag of the given rooms.
+func (s *RoomsService) loadTagCounts(ctx context.Context, rooms []models.Room) error {
+	roomIds := make([]uuid.UUID, len(rooms))
+	for i, room := range rooms {
+		roomIds[i] = room.ID
+	}
+
+	counts, err := s.repository.FindRoomsTagCounts(ctx, roomIds)
+	if err != nil {
+		return err
+	}
+	for i := range rooms {
+		rooms[i].TagCounts = counts[rooms[i].ID]
+	}
+	return nil
+}
+
+// roomResponse maps a room found or saved by the repository, with its tag
+// counts unless the repository failed.
+func (s *RoomsService) roomResponse(ctx context.Context, room *models.Room, err error) (*response.RoomResponse, error) {
+	if err != nil {
+		return s.roomMapper.ToResponse(room), err
+	}
+
+	counts, err := s.repository.FindRoomsTagCounts(ctx, []uuid.UUID{room.ID})
+	room.TagCounts = counts[room.ID]
+	return s.roomMapper.ToResponse(room), err
+}
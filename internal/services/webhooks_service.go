@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/request"
+	"github.com/JulioZittei/wsrs-ama-go/internal/controllers/contracts/response"
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/mappers"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/JulioZittei/wsrs-ama-go/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+const webhookSecretSize = 32
+
+type WebhooksService struct {
+	repository    repositories.WebhooksRepository
+	roomsService  *RoomsService
+	webhookMapper *mappers.WebhookMapper
+}
+
+func NewWebhooksService(repository repositories.WebhooksRepository, roomsService *RoomsService,
+	webhookMapper *mappers.WebhookMapper) *WebhooksService {
+	return &WebhooksService{
+		repository:    repository,
+		roomsService:  roomsService,
+		webhookMapper: webhookMapper,
+	}
+}
+
+// CreateWebhook registers a webhook for the events of a room, or of every
+// room when roomId is nil. A secret is generated when none is sent. The
+// response is the only one that carries it.
+func (s *WebhooksService) CreateWebhook(ctx context.Context, roomId *uuid.UUID, params *request.WebhookRequest) (*response.WebhookResponse, error) {
+	if err := s.checkWebhookScope(ctx, roomId); err != nil {
+		return nil, err
+	}
+	if err := webhooks.CheckURL(ctx, params.URL); err != nil {
+		return nil, internal_errors.NewErrBadRequest(ctx, "WEBHOOK_URL_NOT_ALLOWED")
+	}
+
+	secret := params.Secret
+	if secret == "" {
+		generated := make([]byte, webhookSecretSize)
+		if _, err := rand.Read(generated); err != nil {
+			return nil, internal_errors.NewErrInternal(ctx, err)
+		}
+		secret = hex.EncodeToString(generated)
+	}
+
+	eventKinds := params.EventKinds
+	if eventKinds == nil {
+		eventKinds = []string{}
+	}
+
+	webhook, err := s.repository.SaveWebhook(ctx, &models.Webhook{
+		RoomID:     roomId,
+		URL:        params.URL,
+		EventKinds: eventKinds,
+		Secret:     secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhookResponse := s.webhookMapper.ToResponse(webhook)
+	webhookResponse.Secret = webhook.Secret
+	return webhookResponse, nil
+}
+
+// GetWebhooks returns the webhooks of a room, or the global ones when
+// roomId is nil.
+func (s *WebhooksService) GetWebhooks(ctx context.Context, roomId *uuid.UUID) ([]response.WebhookResponse, error) {
+	if err := s.checkWebhookScope(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.repository.FindWebhooks(ctx, roomId)
+	responseWebhooks := make([]response.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responseWebhooks[i] = *s.webhookMapper.ToResponse(&webhook)
+	}
+	return responseWebhooks, err
+}
+
+func (s *WebhooksService) GetWebhook(ctx context.Context, webhookId uuid.UUID) (*response.WebhookResponse, error) {
+	webhook, err := s.findAccessibleWebhook(ctx, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookMapper.ToResponse(webhook), nil
+}
+
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, webhookId uuid.UUID) error {
+	if _, err := s.findAccessibleWebhook(ctx, webhookId); err != nil {
+		return err
+	}
+	return s.repository.DeleteWebhook(ctx, webhookId)
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a webhook, newest
+// first. An empty status returns them all.
+func (s *WebhooksService) GetWebhookDeliveries(ctx context.Context, webhookId uuid.UUID, status string, limit int32) ([]response.WebhookDeliveryResponse, error) {
+	if _, err := s.findAccessibleWebhook(ctx, webhookId); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repository.FindDeliveries(ctx, webhookId, status, limit)
+	responseDeliveries := make([]response.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responseDeliveries[i] = *s.webhookMapper.DeliveryToResponse(&delivery)
+	}
+	return responseDeliveries, err
+}
+
+// findAccessibleWebhook finds a webhook the request may manage.
+func (s *WebhooksService) findAccessibleWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error) {
+	webhook, err := s.repository.FindWebhook(ctx, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, s.checkWebhookScope(ctx, webhook.RoomID)
+}
+
+// checkWebhookScope lets the requests that may access a room manage its
+// webhooks. The global ones see the events of every room, they need an API
+// key.
+func (s *WebhooksService) checkWebhookScope(ctx context.Context, roomId *uuid.UUID) error {
+	if roomId == nil {
+		return checkAPIKey(ctx)
+	}
+	_, err := s.roomsService.findAccessibleRoom(ctx, *roomId)
+	return err
+}
@@ -0,0 +1,117 @@
+// Package slashcommand reads the slash commands chat platforms such as Slack
+// and Mattermost send, and writes the messages they answer with.
+package slashcommand
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	// a signed request older than maxAge is refused, so a captured one
+	// can't be replayed later
+	maxAge = 5 * time.Minute
+)
+
+type Action string
+
+const (
+	ActionAsk Action = "ask"
+	ActionTop Action = "top"
+)
+
+var ErrUsage = errors.New("slashcommand: unknown command")
+
+// Command is what the text typed after the slash command asks for.
+type Command struct {
+	Action   Action
+	JoinCode string
+	Question string
+}
+
+// Parse reads `ask <join code> <question>` and `top <join code>`.
+func Parse(text string) (Command, error) {
+	action, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	code, question, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	question = strings.TrimSpace(question)
+
+	command := Command{Action: Action(strings.ToLower(action)), JoinCode: code, Question: question}
+	switch {
+	case code == "":
+		return command, ErrUsage
+	case command.Action == ActionAsk && question != "":
+		return command, nil
+	case command.Action == ActionTop && question == "":
+		return command, nil
+	default:
+		return command, ErrUsage
+	}
+}
+
+// Verify tells whether a command was sent by the platform. Slack signs the
+// body with the signing secret of the app. Mattermost sends the token of
+// the command in the form instead, it is compared to the same secret.
+func Verify(secret []byte, header http.Header, body []byte, form url.Values, now time.Time) bool {
+	if len(secret) == 0 {
+		return false
+	}
+
+	signature := header.Get(SignatureHeader)
+	if signature == "" {
+		token := form.Get("token")
+		return token != "" && subtle.ConstantTimeCompare([]byte(token), secret) == 1
+	}
+
+	timestamp := header.Get(TimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxAge || age < -maxAge {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte("v0="+Sign(secret, timestamp, body)))
+}
+
+// Sign returns the hex signature Slack sends for a body.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Escape keeps the text of a message from being read as markup. The
+// platforms only treat &, < and > specially.
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type ResponseType string
+
+const (
+	// Ephemeral messages are only shown to whoever typed the command.
+	Ephemeral ResponseType = "ephemeral"
+	InChannel ResponseType = "in_channel"
+)
+
+// Message is the answer to a command, in the format Slack and Mattermost
+// share.
+type Message struct {
+	ResponseType ResponseType `json:"response_type"`
+	Text         string       `json:"text"`
+}
@@ -0,0 +1,88 @@
+package pgstore
+
+import (
+	"context"
+	"embed"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/migrate"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLock keys the advisory lock held while migrating, any number
+// works as long as no other code locks it.
+const migrationLock int64 = 0x77737273
+
+// Migrate applies the migrations that are newer than the schema version
+// stored in the database. Replicas started together wait for each other
+// instead of running the same migration twice.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	migrator, release, err := NewMigrator(ctx, pool)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return migrator.Up(ctx)
+}
+
+// NewMigrator returns the migrator of the schema once the advisory lock is
+// taken, waiting while another process holds it. The lock belongs to the
+// session, so the migrator keeps a connection of pool for itself until
+// release is called.
+func NewMigrator(ctx context.Context, pool *pgxpool.Pool) (*migrate.Migrator, func(), error) {
+	list, err := migrate.Load(migrations, "migrations")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLock); err != nil {
+		conn.Release()
+		return nil, nil, err
+	}
+
+	release := func() {
+		// a connection that may still hold the lock is closed rather than
+		// returned to the pool
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLock); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}
+
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return migrate.New(schema{conn.Conn()}, list), release, nil
+}
+
+type schema struct {
+	conn *pgx.Conn
+}
+
+func (s schema) Version(ctx context.Context) (int, error) {
+	var version int
+	err := s.conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+func (s schema) Apply(ctx context.Context, statements string, version int) error {
+	return pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, statements); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_version`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_version (version) VALUES ($1)`, version)
+		return err
+	})
+}
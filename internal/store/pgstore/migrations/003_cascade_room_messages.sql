@@ -0,0 +1,9 @@
+ALTER TABLE messages
+    DROP CONSTRAINT IF EXISTS messages_room_id_fkey,
+    ADD CONSTRAINT messages_room_id_fkey FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+ALTER TABLE messages
+    DROP CONSTRAINT IF EXISTS messages_room_id_fkey,
+    ADD CONSTRAINT messages_room_id_fkey FOREIGN KEY (room_id) REFERENCES rooms(id);
@@ -0,0 +1,26 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "description"       TEXT           NOT NULL   DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "starts_at"         TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS "ends_at"           TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS "host_name"         VARCHAR(255)   NOT NULL   DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "cover_image_url"   TEXT           NOT NULL   DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "slug"              VARCHAR(255);
+
+UPDATE rooms SET slug = id::text WHERE slug IS NULL;
+
+ALTER TABLE rooms
+    ALTER COLUMN "slug" SET NOT NULL,
+    ADD CONSTRAINT rooms_slug_key UNIQUE (slug),
+    ADD CONSTRAINT rooms_schedule_check CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at);
+
+---- create above / drop below ----
+
+ALTER TABLE rooms
+    DROP CONSTRAINT IF EXISTS rooms_schedule_check,
+    DROP CONSTRAINT IF EXISTS rooms_slug_key,
+    DROP COLUMN IF EXISTS "slug",
+    DROP COLUMN IF EXISTS "cover_image_url",
+    DROP COLUMN IF EXISTS "host_name",
+    DROP COLUMN IF EXISTS "ends_at",
+    DROP COLUMN IF EXISTS "starts_at",
+    DROP COLUMN IF EXISTS "description";
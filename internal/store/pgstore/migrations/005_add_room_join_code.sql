@@ -0,0 +1,20 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "join_code" VARCHAR(6);
+
+UPDATE rooms
+SET join_code = (
+    SELECT string_agg(substr('23456789ABCDEFGHJKMNPQRSTUVWXYZ', floor(random() * 31)::int + 1, 1), '')
+    FROM generate_series(1, 6)
+    WHERE rooms.id IS NOT NULL
+)
+WHERE join_code IS NULL;
+
+ALTER TABLE rooms
+    ALTER COLUMN "join_code" SET NOT NULL,
+    ADD CONSTRAINT rooms_join_code_key UNIQUE (join_code);
+
+---- create above / drop below ----
+
+ALTER TABLE rooms
+    DROP CONSTRAINT IF EXISTS rooms_join_code_key,
+    DROP COLUMN IF EXISTS "join_code";
@@ -0,0 +1,9 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "passcode_hash"   TEXT      NOT NULL   DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "private"         BOOLEAN   NOT NULL   DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "private",
+    DROP COLUMN IF EXISTS "passcode_hash";
@@ -0,0 +1,21 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "created_at"      TIMESTAMPTZ   NOT NULL   DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS "closed_at"       TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS "anonymized_at"   TIMESTAMPTZ;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS "created_at"      TIMESTAMPTZ   NOT NULL   DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS messages_room_id_created_at_idx ON messages (room_id, created_at);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS messages_room_id_created_at_idx;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS "created_at";
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "anonymized_at",
+    DROP COLUMN IF EXISTS "closed_at",
+    DROP COLUMN IF EXISTS "created_at";
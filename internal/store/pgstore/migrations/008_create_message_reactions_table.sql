@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS message_reactions (
+"message_id"    uuid                       NOT NULL,
+"kind"          VARCHAR(32)                NOT NULL,
+"count"         BIGINT                     NOT NULL   DEFAULT 0,
+PRIMARY KEY (message_id, kind),
+FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+INSERT INTO message_reactions (message_id, kind, count)
+SELECT id, 'like', likes_count FROM messages WHERE likes_count > 0
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "reaction_kinds" TEXT[] NOT NULL DEFAULT ARRAY['like'];
+
+---- create above / drop below ----
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "reaction_kinds";
+
+DROP TABLE IF EXISTS message_reactions;
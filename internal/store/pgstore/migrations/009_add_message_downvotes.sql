@@ -0,0 +1,13 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "downvotes_enabled" BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS "downvotes_count" BIGINT NOT NULL DEFAULT 0;
+
+---- create above / drop below ----
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS "downvotes_count";
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "downvotes_enabled";
@@ -0,0 +1,13 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS "pinned" BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "spotlight_message_id" uuid NULL REFERENCES messages(id) ON DELETE SET NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "spotlight_message_id";
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS "pinned";
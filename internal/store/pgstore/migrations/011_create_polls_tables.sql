@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS polls (
+"id"            uuid         PRIMARY KEY   NOT NULL   DEFAULT gen_random_uuid(),
+"room_id"       uuid                       NOT NULL,
+"question"      VARCHAR(255)               NOT NULL,
+"created_at"    TIMESTAMPTZ                NOT NULL   DEFAULT now(),
+"closed_at"     TIMESTAMPTZ                NULL,
+FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+"id"            uuid         PRIMARY KEY   NOT NULL   DEFAULT gen_random_uuid(),
+"poll_id"       uuid                       NOT NULL,
+"position"      INTEGER                    NOT NULL,
+"label"         VARCHAR(255)               NOT NULL,
+"votes_count"   BIGINT                     NOT NULL   DEFAULT 0,
+FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+"poll_id"        uuid                      NOT NULL,
+"participant_id" VARCHAR(64)               NOT NULL,
+"option_id"      uuid                      NOT NULL,
+"created_at"     TIMESTAMPTZ               NOT NULL   DEFAULT now(),
+PRIMARY KEY (poll_id, participant_id),
+FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS polls_room_id_idx ON polls (room_id);
+CREATE INDEX IF NOT EXISTS poll_options_poll_id_idx ON poll_options (poll_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
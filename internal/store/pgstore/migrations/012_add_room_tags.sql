@@ -0,0 +1,17 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS "tags" TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS "tag" VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS messages_room_id_tag_idx ON messages (room_id, tag);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS messages_room_id_tag_idx;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS "tag";
+
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS "tags";
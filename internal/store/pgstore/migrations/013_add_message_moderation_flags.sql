@@ -0,0 +1,7 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS "moderation_flags" TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
+
+---- create above / drop below ----
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS "moderation_flags";
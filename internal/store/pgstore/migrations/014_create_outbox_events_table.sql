@@ -0,0 +1,17 @@
+-- the events outlive their room, the room_deleted event is recorded along
+-- with the delete, so room_id has no foreign key
+CREATE TABLE IF NOT EXISTS outbox_events (
+"id"            BIGSERIAL    PRIMARY KEY   NOT NULL,
+"room_id"       uuid                       NOT NULL,
+"kind"          VARCHAR(64)                NOT NULL,
+"payload"       JSONB                      NOT NULL,
+"attempts"      INTEGER                    NOT NULL   DEFAULT 0,
+"created_at"    TIMESTAMPTZ                NOT NULL   DEFAULT now(),
+"dispatched_at" TIMESTAMPTZ                NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (id) WHERE dispatched_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,35 @@
+-- a webhook without a room gets the events of every room, one with an empty
+-- event_kinds gets every kind
+CREATE TABLE IF NOT EXISTS webhooks (
+"id"            uuid          PRIMARY KEY   NOT NULL   DEFAULT gen_random_uuid(),
+"room_id"       uuid                        NULL,
+"url"           VARCHAR(2048)               NOT NULL,
+"event_kinds"   TEXT[]                      NOT NULL   DEFAULT '{}',
+"secret"        VARCHAR(255)                NOT NULL,
+"created_at"    TIMESTAMPTZ                 NOT NULL   DEFAULT now(),
+FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+"id"              uuid          PRIMARY KEY   NOT NULL   DEFAULT gen_random_uuid(),
+"webhook_id"      uuid                        NOT NULL,
+"event_kind"      VARCHAR(64)                 NOT NULL,
+"payload"         JSONB                       NOT NULL,
+"status"          VARCHAR(16)                 NOT NULL   DEFAULT 'pending',
+"attempts"        INTEGER                     NOT NULL   DEFAULT 0,
+"response_status" INTEGER                     NULL,
+"last_error"      TEXT                        NOT NULL   DEFAULT '',
+"next_attempt_at" TIMESTAMPTZ                 NOT NULL   DEFAULT now(),
+"created_at"      TIMESTAMPTZ                 NOT NULL   DEFAULT now(),
+"completed_at"    TIMESTAMPTZ                 NULL,
+FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_room_id_idx ON webhooks (room_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
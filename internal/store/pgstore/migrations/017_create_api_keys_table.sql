@@ -0,0 +1,13 @@
+-- only the hash of a key is kept, the prefix tells the keys apart once the
+-- key itself was handed out
+CREATE TABLE IF NOT EXISTS api_keys (
+"id"            uuid          PRIMARY KEY   NOT NULL   DEFAULT gen_random_uuid(),
+"name"          VARCHAR(255)                NOT NULL,
+"prefix"        VARCHAR(16)                 NOT NULL,
+"key_hash"      VARCHAR(64)   UNIQUE        NOT NULL,
+"created_at"    TIMESTAMPTZ                 NOT NULL   DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,12 @@
+-- "handled" counts the subscribers that took the event, in the order they
+-- are handed it, a retry starts from the first one that failed.
+-- "claimed_until" keeps an event from being dispatched twice at once.
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS "handled"       INTEGER     NOT NULL   DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "claimed_until" TIMESTAMPTZ NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS "claimed_until",
+    DROP COLUMN IF EXISTS "handled";
@@ -6,17 +6,112 @@ package pgstore
 
 import (
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID        uuid.UUID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	CreatedAt pgtype.Timestamptz
+}
+
 type Message struct {
+	ID              uuid.UUID
+	RoomID          uuid.UUID
+	Message         string
+	LikesCount      int64
+	Answered        bool
+	CreatedAt       pgtype.Timestamptz
+	DownvotesCount  int64
+	Pinned          bool
+	Tag             string
+	ModerationFlags []string
+	Hidden          bool
+}
+
+type MessageReaction struct {
+	MessageID uuid.UUID
+	Kind      string
+	Count     int64
+}
+
+type OutboxEvent struct {
+	ID           int64
+	RoomID       uuid.UUID
+	Kind         string
+	Payload      []byte
+	Attempts     int32
+	CreatedAt    pgtype.Timestamptz
+	DispatchedAt pgtype.Timestamptz
+	Handled      int32
+	ClaimedUntil pgtype.Timestamptz
+}
+
+type Poll struct {
+	ID        uuid.UUID
+	RoomID    uuid.UUID
+	Question  string
+	CreatedAt pgtype.Timestamptz
+	ClosedAt  pgtype.Timestamptz
+}
+
+type PollOption struct {
 	ID         uuid.UUID
-	RoomID     uuid.UUID
-	Message    string
-	LikesCount int64
-	Answered   bool
+	PollID     uuid.UUID
+	Position   int32
+	Label      string
+	VotesCount int64
+}
+
+type PollVote struct {
+	PollID        uuid.UUID
+	ParticipantID string
+	OptionID      uuid.UUID
+	CreatedAt     pgtype.Timestamptz
 }
 
 type Room struct {
-	ID      uuid.UUID
-	Subject string
+	ID                 uuid.UUID
+	Subject            string
+	Description        string
+	StartsAt           pgtype.Timestamptz
+	EndsAt             pgtype.Timestamptz
+	HostName           string
+	CoverImageUrl      string
+	Slug               string
+	JoinCode           string
+	PasscodeHash       string
+	Private            bool
+	CreatedAt          pgtype.Timestamptz
+	ClosedAt           pgtype.Timestamptz
+	AnonymizedAt       pgtype.Timestamptz
+	ReactionKinds      []string
+	DownvotesEnabled   bool
+	SpotlightMessageID pgtype.UUID
+	Tags               []string
+}
+
+type Webhook struct {
+	ID         uuid.UUID
+	RoomID     pgtype.UUID
+	Url        string
+	EventKinds []string
+	Secret     string
+	CreatedAt  pgtype.Timestamptz
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventKind      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	ResponseStatus pgtype.Int4
+	LastError      string
+	NextAttemptAt  pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	CompletedAt    pgtype.Timestamptz
 }
@@ -9,85 +9,1828 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeArchivedRooms = `-- name: AnonymizeArchivedRooms :execrows
+WITH archived AS (
+    UPDATE rooms
+    SET
+        subject = '[removed]',
+        slug = id::text,
+        description = '',
+        host_name = '',
+        cover_image_url = '',
+        anonymized_at = now()
+    WHERE
+        rooms.closed_at IS NOT NULL
+        AND rooms.closed_at < $1
+        AND rooms.anonymized_at IS NULL
+    RETURNING rooms.id
+),
+voters AS (
+    UPDATE poll_votes
+    SET
+        participant_id = gen_random_uuid()::text
+    WHERE
+        poll_id IN (SELECT polls.id FROM polls WHERE polls.room_id IN (SELECT id FROM archived))
+)
+UPDATE messages
+SET
+    message = '[removed]'
+WHERE
+    room_id IN (SELECT id FROM archived)
+`
+
+// the votes keep counting, but can't be told apart anymore
+func (q *Queries) AnonymizeArchivedRooms(ctx context.Context, closedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, anonymizeArchivedRooms, closedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = $1
+WHERE
+    id IN (
+        SELECT id FROM webhook_deliveries AS due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= now()
+        ORDER BY due.next_attempt_at
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseUntil    pgtype.Timestamptz
+	MaxDeliveries int32
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.LeaseUntil, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventKind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimPendingOutboxEvents = `-- name: ClaimPendingOutboxEvents :many
+UPDATE outbox_events
+SET
+    claimed_until = $1
+WHERE
+    id IN (
+        SELECT id FROM outbox_events AS pending
+        WHERE pending.dispatched_at IS NULL
+            AND (pending.claimed_until IS NULL OR pending.claimed_until <= now())
+        ORDER BY pending.id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+`
+
+type ClaimPendingOutboxEventsParams struct {
+	LeaseUntil pgtype.Timestamptz
+	MaxEvents  int32
+}
+
+func (q *Queries) ClaimPendingOutboxEvents(ctx context.Context, arg ClaimPendingOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.Query(ctx, claimPendingOutboxEvents, arg.LeaseUntil, arg.MaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Kind,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.DispatchedAt,
+			&i.Handled,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closeEndedRooms = `-- name: CloseEndedRooms :many
+UPDATE rooms
+SET
+    closed_at = now()
+WHERE
+    closed_at IS NULL
+    AND ends_at IS NOT NULL
+    AND ends_at < $1
+RETURNING id
+`
+
+func (q *Queries) CloseEndedRooms(ctx context.Context, endsAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, closeEndedRooms, endsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closeInactiveRooms = `-- name: CloseInactiveRooms :many
+UPDATE rooms
+SET
+    closed_at = now()
+WHERE
+    closed_at IS NULL
+    AND GREATEST(rooms.created_at, rooms.starts_at) < $1
+    AND (rooms.starts_at IS NULL OR rooms.starts_at <= now())
+    AND NOT EXISTS (
+        SELECT 1 FROM messages WHERE messages.room_id = rooms.id AND messages.created_at >= $1
+    )
+RETURNING id
+`
+
+func (q *Queries) CloseInactiveRooms(ctx context.Context, createdAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, closeInactiveRooms, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closePoll = `-- name: ClosePoll :one
+UPDATE polls
+SET
+    closed_at = COALESCE(closed_at, now())
+WHERE
+    id = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at"
+`
+
+func (q *Queries) ClosePoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRow(ctx, closePoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const closeRoom = `-- name: CloseRoom :one
+UPDATE rooms
+SET
+    closed_at = COALESCE(closed_at, now())
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+func (q *Queries) CloseRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRow(ctx, closeRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const completeWebhookDelivery = `-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    last_error = $4,
+    completed_at = now()
+WHERE
+    id = $1
+`
+
+type CompleteWebhookDeliveryParams struct {
+	ID             uuid.UUID
+	Status         string
+	ResponseStatus pgtype.Int4
+	LastError      string
+}
+
+func (q *Queries) CompleteWebhookDelivery(ctx context.Context, arg CompleteWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, completeWebhookDelivery,
+		arg.ID,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.LastError,
+	)
+	return err
+}
+
+const decrementMessageDownvotes = `-- name: DecrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = GREATEST(downvotes_count - 1, 0)
+WHERE
+    id = $1
+RETURNING "downvotes_count"
+`
+
+func (q *Queries) DecrementMessageDownvotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, decrementMessageDownvotes, id)
+	var downvotes_count int64
+	err := row.Scan(&downvotes_count)
+	return downvotes_count, err
+}
+
+const decrementMessageReaction = `-- name: DecrementMessageReaction :one
+WITH reaction AS (
+    UPDATE message_reactions
+    SET
+        count = GREATEST(count - 1, 0)
+    WHERE
+        message_id = $1
+        AND kind = $2
+    RETURNING "kind", "count"
+), likes AS (
+    UPDATE messages
+    SET
+        likes_count = reaction.count
+    FROM reaction
+    WHERE
+        messages.id = $1
+        AND reaction.kind = 'like'
+)
+SELECT "count" FROM reaction
+`
+
+type DecrementMessageReactionParams struct {
+	MessageID uuid.UUID
+	Kind      string
+}
+
+func (q *Queries) DecrementMessageReaction(ctx context.Context, arg DecrementMessageReactionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, decrementMessageReaction, arg.MessageID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteApiKey = `-- name: DeleteApiKey :execrows
+DELETE FROM api_keys
+WHERE
+    id = $1
+`
+
+func (q *Queries) DeleteApiKey(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteApiKey, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteArchivedRooms = `-- name: DeleteArchivedRooms :many
+DELETE FROM rooms
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < $1
+RETURNING id
+`
+
+func (q *Queries) DeleteArchivedRooms(ctx context.Context, closedAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, deleteArchivedRooms, closedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteCompletedWebhookDeliveries = `-- name: DeleteCompletedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE
+    completed_at IS NOT NULL
+    AND completed_at < $1
+`
+
+func (q *Queries) DeleteCompletedWebhookDeliveries(ctx context.Context, completedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCompletedWebhookDeliveries, completedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDispatchedOutboxEvents = `-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE
+    dispatched_at IS NOT NULL
+    AND dispatched_at < $1
+`
+
+func (q *Queries) DeleteDispatchedOutboxEvents(ctx context.Context, dispatchedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDispatchedOutboxEvents, dispatchedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRoom = `-- name: DeleteRoom :execrows
+DELETE FROM rooms
+WHERE
+    id = $1
+`
+
+func (q *Queries) DeleteRoom(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoom, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE
+    id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhook, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failOutboxEvent = `-- name: FailOutboxEvent :exec
+UPDATE outbox_events
+SET
+    attempts = CASE WHEN handled = $1 THEN attempts + 1 ELSE 1 END,
+    handled = $1,
+    claimed_until = NULL
+WHERE
+    id = $2
+`
+
+type FailOutboxEventParams struct {
+	Handled int32
+	ID      int64
+}
+
+func (q *Queries) FailOutboxEvent(ctx context.Context, arg FailOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, failOutboxEvent, arg.Handled, arg.ID)
+	return err
+}
+
+const getApiKeyByHash = `-- name: GetApiKeyByHash :one
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+WHERE
+    key_hash = $1
+`
+
+func (q *Queries) GetApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getApiKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApiKeys = `-- name: GetApiKeys :many
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+ORDER BY created_at
+`
+
+func (q *Queries) GetApiKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, getApiKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventWebhooks = `-- name: GetEventWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = $1
+    OR room_id IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetEventWebhooks(ctx context.Context, roomID pgtype.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getEventWebhooks, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGlobalWebhooks = `-- name: GetGlobalWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetGlobalWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getGlobalWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMessage = `-- name: GetMessage :one
 SELECT
-    "id", "room_id", "message", "likes_count", "answered"
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetMessage(ctx context.Context, id uuid.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, getMessage, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
+}
+
+const getMessageReactions = `-- name: GetMessageReactions :many
+SELECT
+    "message_id", "kind", "count"
+FROM message_reactions
+WHERE
+    message_id = $1
+    AND count > 0
+`
+
+func (q *Queries) GetMessageReactions(ctx context.Context, messageID uuid.UUID) ([]MessageReaction, error) {
+	rows, err := q.db.Query(ctx, getMessageReactions, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageReaction
+	for rows.Next() {
+		var i MessageReaction
+		if err := rows.Scan(&i.MessageID, &i.Kind, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingOutboxEvents = `-- name: GetPendingOutboxEvents :many
+SELECT
+    "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+FROM outbox_events
+WHERE
+    dispatched_at IS NULL
+ORDER BY id
+LIMIT $1
+`
+
+func (q *Queries) GetPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	rows, err := q.db.Query(ctx, getPendingOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Kind,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.DispatchedAt,
+			&i.Handled,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPoll = `-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetPoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRow(ctx, getPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getPollOptions = `-- name: GetPollOptions :many
+SELECT
+    "id", "poll_id", "position", "label", "votes_count"
+FROM poll_options
+WHERE
+    poll_id = $1
+ORDER BY position
+`
+
+func (q *Queries) GetPollOptions(ctx context.Context, pollID uuid.UUID) ([]PollOption, error) {
+	rows, err := q.db.Query(ctx, getPollOptions, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+			&i.VotesCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoom = `-- name: GetRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = $1
+`
+
+func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRow(ctx, getRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getRoomByJoinCode = `-- name: GetRoomByJoinCode :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE join_code = $1
+`
+
+func (q *Queries) GetRoomByJoinCode(ctx context.Context, joinCode string) (Room, error) {
+	row := q.db.QueryRow(ctx, getRoomByJoinCode, joinCode)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getRoomMessageReactions = `-- name: GetRoomMessageReactions :many
+SELECT
+    message_reactions.message_id, message_reactions.kind, message_reactions.count
+FROM message_reactions
+JOIN messages ON messages.id = message_reactions.message_id
+WHERE
+    messages.room_id = $1
+    AND message_reactions.count > 0
+`
+
+func (q *Queries) GetRoomMessageReactions(ctx context.Context, roomID uuid.UUID) ([]MessageReaction, error) {
+	rows, err := q.db.Query(ctx, getRoomMessageReactions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageReaction
+	for rows.Next() {
+		var i MessageReaction
+		if err := rows.Scan(&i.MessageID, &i.Kind, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessages = `-- name: GetRoomMessages :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getRoomMessages, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessagesByTag = `-- name: GetRoomMessagesByTag :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = $1
+    AND tag = $2
+ORDER BY created_at, id
+`
+
+type GetRoomMessagesByTagParams struct {
+	RoomID uuid.UUID
+	Tag    string
+}
+
+func (q *Queries) GetRoomMessagesByTag(ctx context.Context, arg GetRoomMessagesByTagParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getRoomMessagesByTag, arg.RoomID, arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessagesPage = `-- name: GetRoomMessagesPage :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = $1
+    AND (created_at, id) > ($2::timestamptz, $3::uuid)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type GetRoomMessagesPageParams struct {
+	RoomID         uuid.UUID
+	AfterCreatedAt pgtype.Timestamptz
+	AfterID        uuid.UUID
+	PageSize       int32
+}
+
+func (q *Queries) GetRoomMessagesPage(ctx context.Context, arg GetRoomMessagesPageParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getRoomMessagesPage,
+		arg.RoomID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPollOptions = `-- name: GetRoomPollOptions :many
+SELECT
+    poll_options.id, poll_options.poll_id, poll_options.position, poll_options.label, poll_options.votes_count
+FROM poll_options
+JOIN polls ON polls.id = poll_options.poll_id
+WHERE
+    polls.room_id = $1
+ORDER BY poll_options.poll_id, poll_options.position
+`
+
+func (q *Queries) GetRoomPollOptions(ctx context.Context, roomID uuid.UUID) ([]PollOption, error) {
+	rows, err := q.db.Query(ctx, getRoomPollOptions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+			&i.VotesCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPolls = `-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    room_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetRoomPolls(ctx context.Context, roomID uuid.UUID) ([]Poll, error) {
+	rows, err := q.db.Query(ctx, getRoomPolls, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Question,
+			&i.CreatedAt,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomSlugs = `-- name: GetRoomSlugs :many
+SELECT slug FROM rooms
+WHERE
+    (slug = $1::text OR slug LIKE $1::text || '-%')
+    AND id <> $2
+`
+
+type GetRoomSlugsParams struct {
+	Slug string
+	ID   uuid.UUID
+}
+
+func (q *Queries) GetRoomSlugs(ctx context.Context, arg GetRoomSlugsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, getRoomSlugs, arg.Slug, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		items = append(items, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomWebhooks = `-- name: GetRoomWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetRoomWebhooks(ctx context.Context, roomID pgtype.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getRoomWebhooks, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRooms = `-- name: GetRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE private = false
+`
+
+func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
+	rows, err := q.db.Query(ctx, getRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.Description,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.HostName,
+			&i.CoverImageUrl,
+			&i.Slug,
+			&i.JoinCode,
+			&i.PasscodeHash,
+			&i.Private,
+			&i.CreatedAt,
+			&i.ClosedAt,
+			&i.AnonymizedAt,
+			&i.ReactionKinds,
+			&i.DownvotesEnabled,
+			&i.SpotlightMessageID,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomsTagCounts = `-- name: GetRoomsTagCounts :many
+SELECT
+    "room_id", "tag", count(*) AS messages_count
 FROM messages
 WHERE
-    id = $1
+    room_id = ANY($1::uuid[])
+    AND tag <> ''
+GROUP BY room_id, tag
+`
+
+type GetRoomsTagCountsRow struct {
+	RoomID        uuid.UUID
+	Tag           string
+	MessagesCount int64
+}
+
+func (q *Queries) GetRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) ([]GetRoomsTagCountsRow, error) {
+	rows, err := q.db.Query(ctx, getRoomsTagCounts, roomIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomsTagCountsRow
+	for rows.Next() {
+		var i GetRoomsTagCountsRow
+		if err := rows.Scan(&i.RoomID, &i.Tag, &i.MessagesCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Url,
+		&i.EventKinds,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT
+    "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+FROM webhook_deliveries
+WHERE
+    webhook_id = $1
+    AND ($2::text = '' OR status = $2::text)
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type GetWebhookDeliveriesParams struct {
+	WebhookID     uuid.UUID
+	Status        string
+	MaxDeliveries int32
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, getWebhookDeliveries, arg.WebhookID, arg.Status, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventKind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementMessageDownvotes = `-- name: IncrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = downvotes_count + 1
+WHERE
+    id = $1
+RETURNING "downvotes_count"
+`
+
+func (q *Queries) IncrementMessageDownvotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, incrementMessageDownvotes, id)
+	var downvotes_count int64
+	err := row.Scan(&downvotes_count)
+	return downvotes_count, err
+}
+
+const incrementMessageReaction = `-- name: IncrementMessageReaction :one
+WITH reaction AS (
+    INSERT INTO message_reactions
+        ( "message_id", "kind", "count" ) VALUES
+        ( $1, $2, 1 )
+    ON CONFLICT ( "message_id", "kind" ) DO UPDATE
+    SET
+        count = message_reactions.count + 1
+    RETURNING "kind", "count"
+), likes AS (
+    UPDATE messages
+    SET
+        likes_count = reaction.count
+    FROM reaction
+    WHERE
+        messages.id = $1
+        AND reaction.kind = 'like'
+)
+SELECT "count" FROM reaction
+`
+
+type IncrementMessageReactionParams struct {
+	MessageID uuid.UUID
+	Kind      string
+}
+
+func (q *Queries) IncrementMessageReaction(ctx context.Context, arg IncrementMessageReactionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, incrementMessageReaction, arg.MessageID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const insertApiKey = `-- name: InsertApiKey :one
+INSERT INTO api_keys
+    ( "name", "prefix", "key_hash" ) VALUES
+    ( $1, $2, $3 )
+RETURNING "id", "name", "prefix", "key_hash", "created_at"
+`
+
+type InsertApiKeyParams struct {
+	Name    string
+	Prefix  string
+	KeyHash string
+}
+
+func (q *Queries) InsertApiKey(ctx context.Context, arg InsertApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, insertApiKey, arg.Name, arg.Prefix, arg.KeyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertMessage = `-- name: InsertMessage :one
+INSERT INTO messages
+    ( "room_id", "message", "tag", "moderation_flags" ) VALUES
+    ( $1, $2, $3, $4 )
+RETURNING "id"
+`
+
+type InsertMessageParams struct {
+	RoomID          uuid.UUID
+	Message         string
+	Tag             string
+	ModerationFlags []string
+}
+
+func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, insertMessage,
+		arg.RoomID,
+		arg.Message,
+		arg.Tag,
+		arg.ModerationFlags,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertMessages = `-- name: InsertMessages :many
+WITH input AS MATERIALIZED (
+    SELECT gen_random_uuid() AS id, input.message, input.tag, input.moderation_flags, input.position
+    FROM ROWS FROM (
+        unnest($1::text[]), unnest($2::text[]), unnest($3::text[])
+    ) WITH ORDINALITY AS input(message, tag, moderation_flags, position)
+), inserted AS (
+    INSERT INTO messages
+        ( "id", "room_id", "message", "tag", "moderation_flags", "created_at" )
+    SELECT
+        input.id, $4::uuid, input.message, input.tag, string_to_array(input.moderation_flags, ','),
+        -- keeps the messages in the order of the input, now() is the same
+        -- for every row
+        now() + input.position * interval '1 microsecond'
+    FROM input
+    RETURNING "id"
+)
+SELECT inserted.id, input.position::bigint AS position
+FROM inserted
+JOIN input ON input.id = inserted.id
+ORDER BY input.position
+`
+
+type InsertMessagesParams struct {
+	Messages        []string
+	Tags            []string
+	ModerationFlags []string
+	RoomID          uuid.UUID
+}
+
+type InsertMessagesRow struct {
+	ID       uuid.UUID
+	Position int64
+}
+
+func (q *Queries) InsertMessages(ctx context.Context, arg InsertMessagesParams) ([]InsertMessagesRow, error) {
+	rows, err := q.db.Query(ctx, insertMessages,
+		arg.Messages,
+		arg.Tags,
+		arg.ModerationFlags,
+		arg.RoomID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InsertMessagesRow
+	for rows.Next() {
+		var i InsertMessagesRow
+		if err := rows.Scan(&i.ID, &i.Position); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events
+    ( "room_id", "kind", "payload" ) VALUES
+    ( $1, $2, $3 )
+`
+
+type InsertOutboxEventParams struct {
+	RoomID  uuid.UUID
+	Kind    string
+	Payload []byte
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, insertOutboxEvent, arg.RoomID, arg.Kind, arg.Payload)
+	return err
+}
+
+const insertPoll = `-- name: InsertPoll :one
+WITH poll AS (
+    INSERT INTO polls
+        ( "room_id", "question" ) VALUES
+        ( $1, $2 )
+    RETURNING "id", "room_id", "question", "created_at", "closed_at"
+), options AS (
+    INSERT INTO poll_options
+        ( "poll_id", "position", "label" )
+    SELECT
+        poll.id, option.position, option.label
+    FROM poll, unnest($3::text[]) WITH ORDINALITY AS option(label, position)
+)
+SELECT "id" FROM poll
+`
+
+type InsertPollParams struct {
+	RoomID   uuid.UUID
+	Question string
+	Options  []string
+}
+
+func (q *Queries) InsertPoll(ctx context.Context, arg InsertPollParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, insertPoll, arg.RoomID, arg.Question, arg.Options)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertPollVote = `-- name: InsertPollVote :execrows
+WITH vote AS (
+    INSERT INTO poll_votes
+        ( "poll_id", "participant_id", "option_id" ) VALUES
+        ( $1, $2, $3 )
+    ON CONFLICT ( "poll_id", "participant_id" ) DO NOTHING
+    RETURNING "option_id"
+)
+UPDATE poll_options
+SET
+    votes_count = votes_count + 1
+FROM vote
+WHERE
+    poll_options.id = vote.option_id
 `
 
-func (q *Queries) GetMessage(ctx context.Context, id uuid.UUID) (Message, error) {
-	row := q.db.QueryRow(ctx, getMessage, id)
-	var i Message
+type InsertPollVoteParams struct {
+	PollID        uuid.UUID
+	ParticipantID string
+	OptionID      uuid.UUID
+}
+
+func (q *Queries) InsertPollVote(ctx context.Context, arg InsertPollVoteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertPollVote, arg.PollID, arg.ParticipantID, arg.OptionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const insertRoom = `-- name: InsertRoom :one
+INSERT INTO rooms
+    ( "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "reaction_kinds", "downvotes_enabled", "tags" ) VALUES
+    ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13 )
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type InsertRoomParams struct {
+	Subject          string
+	Description      string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	HostName         string
+	CoverImageUrl    string
+	Slug             string
+	JoinCode         string
+	PasscodeHash     string
+	Private          bool
+	ReactionKinds    []string
+	DownvotesEnabled bool
+	Tags             []string
+}
+
+func (q *Queries) InsertRoom(ctx context.Context, arg InsertRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, insertRoom,
+		arg.Subject,
+		arg.Description,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.HostName,
+		arg.CoverImageUrl,
+		arg.Slug,
+		arg.JoinCode,
+		arg.PasscodeHash,
+		arg.Private,
+		arg.ReactionKinds,
+		arg.DownvotesEnabled,
+		arg.Tags,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const insertWebhook = `-- name: InsertWebhook :one
+INSERT INTO webhooks
+    ( "room_id", "url", "event_kinds", "secret" ) VALUES
+    ( $1, $2, $3, $4 )
+RETURNING "id", "room_id", "url", "event_kinds", "secret", "created_at"
+`
+
+type InsertWebhookParams struct {
+	RoomID     pgtype.UUID
+	Url        string
+	EventKinds []string
+	Secret     string
+}
+
+func (q *Queries) InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, insertWebhook,
+		arg.RoomID,
+		arg.Url,
+		arg.EventKinds,
+		arg.Secret,
+	)
+	var i Webhook
 	err := row.Scan(
 		&i.ID,
 		&i.RoomID,
-		&i.Message,
-		&i.LikesCount,
-		&i.Answered,
+		&i.Url,
+		&i.EventKinds,
+		&i.Secret,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getRoom = `-- name: GetRoom :one
+const insertWebhookDelivery = `-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries
+    ( "webhook_id", "event_kind", "payload" ) VALUES
+    ( $1, $2, $3 )
+`
+
+type InsertWebhookDeliveryParams struct {
+	WebhookID uuid.UUID
+	EventKind string
+	Payload   []byte
+}
+
+func (q *Queries) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, insertWebhookDelivery, arg.WebhookID, arg.EventKind, arg.Payload)
+	return err
+}
+
+const isRoomSlugTaken = `-- name: IsRoomSlugTaken :one
+SELECT EXISTS (
+    SELECT 1 FROM rooms WHERE slug = $1 AND id <> $2
+)
+`
+
+type IsRoomSlugTakenParams struct {
+	Slug string
+	ID   uuid.UUID
+}
+
+func (q *Queries) IsRoomSlugTaken(ctx context.Context, arg IsRoomSlugTakenParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isRoomSlugTaken, arg.Slug, arg.ID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const lockRoom = `-- name: LockRoom :one
 SELECT
-    "id", "subject"
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 FROM rooms
 WHERE id = $1
+FOR SHARE
 `
 
-func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
-	row := q.db.QueryRow(ctx, getRoom, id)
+func (q *Queries) LockRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRow(ctx, lockRoom, id)
 	var i Room
-	err := row.Scan(&i.ID, &i.Subject)
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
 	return i, err
 }
 
-const getRoomMessages = `-- name: GetRoomMessages :many
-SELECT
-    "id", "room_id", "message", "likes_count", "answered"
-FROM messages
+const markMessageAsAnswered = `-- name: MarkMessageAsAnswered :exec
+UPDATE messages
+SET
+    answered = true
+WHERE
+    id = $1
+`
+
+func (q *Queries) MarkMessageAsAnswered(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markMessageAsAnswered, id)
+	return err
+}
+
+const markOutboxEventDispatched = `-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET
+    dispatched_at = now()
+WHERE
+    id = $1
+`
+
+func (q *Queries) MarkOutboxEventDispatched(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, markOutboxEventDispatched, id)
+	return err
+}
+
+const recountRoomMessageLikes = `-- name: RecountRoomMessageLikes :execrows
+UPDATE messages
+SET
+    likes_count = COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
 WHERE
     room_id = $1
+    AND likes_count <> COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
 `
 
-func (q *Queries) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Message, error) {
-	rows, err := q.db.Query(ctx, getRoomMessages, roomID)
+func (q *Queries) RecountRoomMessageLikes(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, recountRoomMessageLikes, roomID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Message
-	for rows.Next() {
-		var i Message
-		if err := rows.Scan(
-			&i.ID,
-			&i.RoomID,
-			&i.Message,
-			&i.LikesCount,
-			&i.Answered,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getRooms = `-- name: GetRooms :many
+const releaseOutboxEvents = `-- name: ReleaseOutboxEvents :exec
+UPDATE outbox_events
+SET
+    claimed_until = NULL
+WHERE
+    id = ANY($1::bigint[])
+`
+
+func (q *Queries) ReleaseOutboxEvents(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, releaseOutboxEvents, ids)
+	return err
+}
+
+const reopenRoom = `-- name: ReopenRoom :one
+UPDATE rooms
+SET
+    closed_at = NULL
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+func (q *Queries) ReopenRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRow(ctx, reopenRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = $3,
+    next_attempt_at = $4
+WHERE
+    id = $1
+`
+
+type RetryWebhookDeliveryParams struct {
+	ID             uuid.UUID
+	ResponseStatus pgtype.Int4
+	LastError      string
+	NextAttemptAt  pgtype.Timestamptz
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, retryWebhookDelivery,
+		arg.ID,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const searchRooms = `-- name: SearchRooms :many
 SELECT
-    "id", "subject"
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 FROM rooms
+WHERE
+    $1::text = ''
+    OR strpos(lower(subject), lower($1::text)) > 0
+    OR strpos(lower(slug), lower($1::text)) > 0
+    OR strpos(lower(host_name), lower($1::text)) > 0
+    OR join_code = $2::text
+ORDER BY created_at
 `
 
-func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
-	rows, err := q.db.Query(ctx, getRooms)
+type SearchRoomsParams struct {
+	Query    string
+	JoinCode string
+}
+
+func (q *Queries) SearchRooms(ctx context.Context, arg SearchRoomsParams) ([]Room, error) {
+	rows, err := q.db.Query(ctx, searchRooms, arg.Query, arg.JoinCode)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +1838,26 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 	var items []Room
 	for rows.Next() {
 		var i Room
-		if err := rows.Scan(&i.ID, &i.Subject); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.Description,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.HostName,
+			&i.CoverImageUrl,
+			&i.Slug,
+			&i.JoinCode,
+			&i.PasscodeHash,
+			&i.Private,
+			&i.CreatedAt,
+			&i.ClosedAt,
+			&i.AnonymizedAt,
+			&i.ReactionKinds,
+			&i.DownvotesEnabled,
+			&i.SpotlightMessageID,
+			&i.Tags,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -106,80 +1868,224 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 	return items, nil
 }
 
-const insertMessage = `-- name: InsertMessage :one
-INSERT INTO messages
-    ( "room_id", "message" ) VALUES
-    ( $1, $2 )
-RETURNING "id"
+const setMessageHidden = `-- name: SetMessageHidden :one
+UPDATE messages
+SET
+    hidden = $2
+WHERE
+    id = $1
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
 `
 
-type InsertMessageParams struct {
-	RoomID  uuid.UUID
-	Message string
+type SetMessageHiddenParams struct {
+	ID     uuid.UUID
+	Hidden bool
 }
 
-func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, insertMessage, arg.RoomID, arg.Message)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+func (q *Queries) SetMessageHidden(ctx context.Context, arg SetMessageHiddenParams) (Message, error) {
+	row := q.db.QueryRow(ctx, setMessageHidden, arg.ID, arg.Hidden)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
 }
 
-const insertRoom = `-- name: InsertRoom :one
-INSERT INTO rooms
-    ( "subject" ) VALUES
-    ( $1 )
-RETURNING "id"
+const setMessagePinned = `-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = $2
+WHERE
+    id = $1
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
 `
 
-func (q *Queries) InsertRoom(ctx context.Context, subject string) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, insertRoom, subject)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+type SetMessagePinnedParams struct {
+	ID     uuid.UUID
+	Pinned bool
 }
 
-const markMessageAsAnswered = `-- name: MarkMessageAsAnswered :exec
-UPDATE messages
+func (q *Queries) SetMessagePinned(ctx context.Context, arg SetMessagePinnedParams) (Message, error) {
+	row := q.db.QueryRow(ctx, setMessagePinned, arg.ID, arg.Pinned)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
+}
+
+const updateRoom = `-- name: UpdateRoom :one
+UPDATE rooms
 SET
-    answered = true
+    subject = $2,
+    description = $3,
+    starts_at = $4,
+    ends_at = $5,
+    host_name = $6,
+    cover_image_url = $7,
+    slug = $8,
+    passcode_hash = $9,
+    private = $10,
+    reaction_kinds = $11,
+    downvotes_enabled = $12,
+    tags = $13
 WHERE
     id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 `
 
-func (q *Queries) MarkMessageAsAnswered(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, markMessageAsAnswered, id)
-	return err
+type UpdateRoomParams struct {
+	ID               uuid.UUID
+	Subject          string
+	Description      string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	HostName         string
+	CoverImageUrl    string
+	Slug             string
+	PasscodeHash     string
+	Private          bool
+	ReactionKinds    []string
+	DownvotesEnabled bool
+	Tags             []string
 }
 
-const reactToMessage = `-- name: ReactToMessage :one
-UPDATE messages
+func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoom,
+		arg.ID,
+		arg.Subject,
+		arg.Description,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.HostName,
+		arg.CoverImageUrl,
+		arg.Slug,
+		arg.PasscodeHash,
+		arg.Private,
+		arg.ReactionKinds,
+		arg.DownvotesEnabled,
+		arg.Tags,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const updateRoomJoinCode = `-- name: UpdateRoomJoinCode :one
+UPDATE rooms
 SET
-    likes_count = likes_count + 1
+    join_code = $2
 WHERE
     id = $1
-RETURNING likes_count
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 `
 
-func (q *Queries) ReactToMessage(ctx context.Context, id uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, reactToMessage, id)
-	var likes_count int64
-	err := row.Scan(&likes_count)
-	return likes_count, err
+type UpdateRoomJoinCodeParams struct {
+	ID       uuid.UUID
+	JoinCode string
 }
 
-const removeReactionFromMessage = `-- name: RemoveReactionFromMessage :one
-UPDATE messages
+func (q *Queries) UpdateRoomJoinCode(ctx context.Context, arg UpdateRoomJoinCodeParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoomJoinCode, arg.ID, arg.JoinCode)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const updateRoomSpotlight = `-- name: UpdateRoomSpotlight :one
+UPDATE rooms
 SET
-    likes_count = likes_count - 1
+    spotlight_message_id = $2
 WHERE
     id = $1
-RETURNING likes_count
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 `
 
-func (q *Queries) RemoveReactionFromMessage(ctx context.Context, id uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, removeReactionFromMessage, id)
-	var likes_count int64
-	err := row.Scan(&likes_count)
-	return likes_count, err
+type UpdateRoomSpotlightParams struct {
+	ID                 uuid.UUID
+	SpotlightMessageID pgtype.UUID
+}
+
+func (q *Queries) UpdateRoomSpotlight(ctx context.Context, arg UpdateRoomSpotlightParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoomSpotlight, arg.ID, arg.SpotlightMessageID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
 }
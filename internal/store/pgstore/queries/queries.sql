@@ -1,59 +1,601 @@
 -- name: GetRoom :one
 SELECT
-    "id", "subject"
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
 FROM rooms
 WHERE id = $1;
 
+-- name: LockRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = $1
+FOR SHARE;
+
 -- name: GetRooms :many
 SELECT
-    "id", "subject"
-FROM rooms;
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE private = false;
 
 -- name: InsertRoom :one
 INSERT INTO rooms
-    ( "subject" ) VALUES
-    ( $1 )
-RETURNING "id";
+    ( "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "reaction_kinds", "downvotes_enabled", "tags" ) VALUES
+    ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13 )
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: UpdateRoom :one
+UPDATE rooms
+SET
+    subject = $2,
+    description = $3,
+    starts_at = $4,
+    ends_at = $5,
+    host_name = $6,
+    cover_image_url = $7,
+    slug = $8,
+    passcode_hash = $9,
+    private = $10,
+    reaction_kinds = $11,
+    downvotes_enabled = $12,
+    tags = $13
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: IsRoomSlugTaken :one
+SELECT EXISTS (
+    SELECT 1 FROM rooms WHERE slug = $1 AND id <> $2
+);
+
+-- name: GetRoomSlugs :many
+SELECT slug FROM rooms
+WHERE
+    (slug = sqlc.arg(slug)::text OR slug LIKE sqlc.arg(slug)::text || '-%')
+    AND id <> sqlc.arg(id);
+
+-- name: GetRoomByJoinCode :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE join_code = $1;
+
+-- name: UpdateRoomJoinCode :one
+UPDATE rooms
+SET
+    join_code = $2
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: UpdateRoomSpotlight :one
+UPDATE rooms
+SET
+    spotlight_message_id = $2
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: DeleteRoom :execrows
+DELETE FROM rooms
+WHERE
+    id = $1;
+
+-- name: SearchRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE
+    sqlc.arg(query)::text = ''
+    OR strpos(lower(subject), lower(sqlc.arg(query)::text)) > 0
+    OR strpos(lower(slug), lower(sqlc.arg(query)::text)) > 0
+    OR strpos(lower(host_name), lower(sqlc.arg(query)::text)) > 0
+    OR join_code = sqlc.arg(join_code)::text
+ORDER BY created_at;
+
+-- name: CloseRoom :one
+UPDATE rooms
+SET
+    closed_at = COALESCE(closed_at, now())
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: ReopenRoom :one
+UPDATE rooms
+SET
+    closed_at = NULL
+WHERE
+    id = $1
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: CloseEndedRooms :many
+UPDATE rooms
+SET
+    closed_at = now()
+WHERE
+    closed_at IS NULL
+    AND ends_at IS NOT NULL
+    AND ends_at < $1
+RETURNING id;
+
+-- name: CloseInactiveRooms :many
+UPDATE rooms
+SET
+    closed_at = now()
+WHERE
+    closed_at IS NULL
+    AND GREATEST(rooms.created_at, rooms.starts_at) < $1
+    AND (rooms.starts_at IS NULL OR rooms.starts_at <= now())
+    AND NOT EXISTS (
+        SELECT 1 FROM messages WHERE messages.room_id = rooms.id AND messages.created_at >= $1
+    )
+RETURNING id;
+
+-- name: DeleteArchivedRooms :many
+DELETE FROM rooms
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < $1
+RETURNING id;
+
+-- name: AnonymizeArchivedRooms :execrows
+WITH archived AS (
+    UPDATE rooms
+    SET
+        subject = '[removed]',
+        slug = id::text,
+        description = '',
+        host_name = '',
+        cover_image_url = '',
+        anonymized_at = now()
+    WHERE
+        rooms.closed_at IS NOT NULL
+        AND rooms.closed_at < $1
+        AND rooms.anonymized_at IS NULL
+    RETURNING rooms.id
+),
+-- the votes keep counting, but can't be told apart anymore
+voters AS (
+    UPDATE poll_votes
+    SET
+        participant_id = gen_random_uuid()::text
+    WHERE
+        poll_id IN (SELECT polls.id FROM polls WHERE polls.room_id IN (SELECT id FROM archived))
+)
+UPDATE messages
+SET
+    message = '[removed]'
+WHERE
+    room_id IN (SELECT id FROM archived);
 
 -- name: GetMessage :one
 SELECT
-    "id", "room_id", "message", "likes_count", "answered"
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
 FROM messages
 WHERE
     id = $1;
 
 -- name: GetRoomMessages :many
 SELECT
-    "id", "room_id", "message", "likes_count", "answered"
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = $1
+ORDER BY created_at, id;
+
+-- name: GetRoomMessagesPage :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
 FROM messages
 WHERE
-    room_id = $1;
+    room_id = sqlc.arg(room_id)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetRoomMessagesByTag :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = $1
+    AND tag = $2
+ORDER BY created_at, id;
+
+-- name: GetRoomsTagCounts :many
+SELECT
+    "room_id", "tag", count(*) AS messages_count
+FROM messages
+WHERE
+    room_id = ANY(sqlc.arg(room_ids)::uuid[])
+    AND tag <> ''
+GROUP BY room_id, tag;
 
 -- name: InsertMessage :one
 INSERT INTO messages
-    ( "room_id", "message" ) VALUES
-    ( $1, $2 )
+    ( "room_id", "message", "tag", "moderation_flags" ) VALUES
+    ( $1, $2, $3, $4 )
 RETURNING "id";
 
--- name: ReactToMessage :one
+-- name: InsertMessages :many
+WITH input AS MATERIALIZED (
+    SELECT gen_random_uuid() AS id, input.message, input.tag, input.moderation_flags, input.position
+    FROM ROWS FROM (
+        unnest(sqlc.arg(messages)::text[]), unnest(sqlc.arg(tags)::text[]), unnest(sqlc.arg(moderation_flags)::text[])
+    ) WITH ORDINALITY AS input(message, tag, moderation_flags, position)
+), inserted AS (
+    INSERT INTO messages
+        ( "id", "room_id", "message", "tag", "moderation_flags", "created_at" )
+    SELECT
+        input.id, sqlc.arg(room_id)::uuid, input.message, input.tag, string_to_array(input.moderation_flags, ','),
+        -- keeps the messages in the order of the input, now() is the same
+        -- for every row
+        now() + input.position * interval '1 microsecond'
+    FROM input
+    RETURNING "id"
+)
+SELECT inserted.id, input.position::bigint AS position
+FROM inserted
+JOIN input ON input.id = inserted.id
+ORDER BY input.position;
+
+-- name: IncrementMessageReaction :one
+WITH reaction AS (
+    INSERT INTO message_reactions
+        ( "message_id", "kind", "count" ) VALUES
+        ( $1, $2, 1 )
+    ON CONFLICT ( "message_id", "kind" ) DO UPDATE
+    SET
+        count = message_reactions.count + 1
+    RETURNING "kind", "count"
+), likes AS (
+    UPDATE messages
+    SET
+        likes_count = reaction.count
+    FROM reaction
+    WHERE
+        messages.id = $1
+        AND reaction.kind = 'like'
+)
+SELECT "count" FROM reaction;
+
+-- name: DecrementMessageReaction :one
+WITH reaction AS (
+    UPDATE message_reactions
+    SET
+        count = GREATEST(count - 1, 0)
+    WHERE
+        message_id = $1
+        AND kind = $2
+    RETURNING "kind", "count"
+), likes AS (
+    UPDATE messages
+    SET
+        likes_count = reaction.count
+    FROM reaction
+    WHERE
+        messages.id = $1
+        AND reaction.kind = 'like'
+)
+SELECT "count" FROM reaction;
+
+-- name: IncrementMessageDownvotes :one
 UPDATE messages
 SET
-    likes_count = likes_count + 1
+    downvotes_count = downvotes_count + 1
 WHERE
     id = $1
-RETURNING likes_count;
+RETURNING "downvotes_count";
 
--- name: RemoveReactionFromMessage :one
+-- name: DecrementMessageDownvotes :one
 UPDATE messages
 SET
-    likes_count = likes_count - 1
+    downvotes_count = GREATEST(downvotes_count - 1, 0)
 WHERE
     id = $1
-RETURNING likes_count;
+RETURNING "downvotes_count";
+
+-- name: GetMessageReactions :many
+SELECT
+    "message_id", "kind", "count"
+FROM message_reactions
+WHERE
+    message_id = $1
+    AND count > 0;
+
+-- name: GetRoomMessageReactions :many
+SELECT
+    message_reactions.message_id, message_reactions.kind, message_reactions.count
+FROM message_reactions
+JOIN messages ON messages.id = message_reactions.message_id
+WHERE
+    messages.room_id = $1
+    AND message_reactions.count > 0;
+
+-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = $2
+WHERE
+    id = $1
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden";
 
 -- name: MarkMessageAsAnswered :exec
 UPDATE messages
 SET
     answered = true
 WHERE
-    id = $1;
\ No newline at end of file
+    id = $1;
+
+-- name: SetMessageHidden :one
+UPDATE messages
+SET
+    hidden = $2
+WHERE
+    id = $1
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden";
+
+-- name: RecountRoomMessageLikes :execrows
+UPDATE messages
+SET
+    likes_count = COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
+WHERE
+    room_id = $1
+    AND likes_count <> COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0);
+
+-- name: InsertPoll :one
+WITH poll AS (
+    INSERT INTO polls
+        ( "room_id", "question" ) VALUES
+        ( sqlc.arg(room_id), sqlc.arg(question) )
+    RETURNING "id", "room_id", "question", "created_at", "closed_at"
+), options AS (
+    INSERT INTO poll_options
+        ( "poll_id", "position", "label" )
+    SELECT
+        poll.id, option.position, option.label
+    FROM poll, unnest(sqlc.arg(options)::text[]) WITH ORDINALITY AS option(label, position)
+)
+SELECT "id" FROM poll;
+
+-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    id = $1;
+
+-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    room_id = $1
+ORDER BY created_at;
+
+-- name: GetPollOptions :many
+SELECT
+    "id", "poll_id", "position", "label", "votes_count"
+FROM poll_options
+WHERE
+    poll_id = $1
+ORDER BY position;
+
+-- name: GetRoomPollOptions :many
+SELECT
+    poll_options.id, poll_options.poll_id, poll_options.position, poll_options.label, poll_options.votes_count
+FROM poll_options
+JOIN polls ON polls.id = poll_options.poll_id
+WHERE
+    polls.room_id = $1
+ORDER BY poll_options.poll_id, poll_options.position;
+
+-- name: InsertPollVote :execrows
+WITH vote AS (
+    INSERT INTO poll_votes
+        ( "poll_id", "participant_id", "option_id" ) VALUES
+        ( $1, $2, $3 )
+    ON CONFLICT ( "poll_id", "participant_id" ) DO NOTHING
+    RETURNING "option_id"
+)
+UPDATE poll_options
+SET
+    votes_count = votes_count + 1
+FROM vote
+WHERE
+    poll_options.id = vote.option_id;
+
+-- name: ClosePoll :one
+UPDATE polls
+SET
+    closed_at = COALESCE(closed_at, now())
+WHERE
+    id = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at";
+
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events
+    ( "room_id", "kind", "payload" ) VALUES
+    ( $1, $2, $3 );
+
+-- name: ClaimPendingOutboxEvents :many
+UPDATE outbox_events
+SET
+    claimed_until = sqlc.arg(lease_until)
+WHERE
+    id IN (
+        SELECT id FROM outbox_events AS pending
+        WHERE pending.dispatched_at IS NULL
+            AND (pending.claimed_until IS NULL OR pending.claimed_until <= now())
+        ORDER BY pending.id
+        LIMIT sqlc.arg(max_events)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until";
+
+-- name: GetPendingOutboxEvents :many
+SELECT
+    "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+FROM outbox_events
+WHERE
+    dispatched_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET
+    dispatched_at = now()
+WHERE
+    id = $1;
+
+-- name: FailOutboxEvent :exec
+UPDATE outbox_events
+SET
+    attempts = CASE WHEN handled = sqlc.arg(handled) THEN attempts + 1 ELSE 1 END,
+    handled = sqlc.arg(handled),
+    claimed_until = NULL
+WHERE
+    id = sqlc.arg(id);
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox_events
+SET
+    claimed_until = NULL
+WHERE
+    id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE
+    dispatched_at IS NOT NULL
+    AND dispatched_at < $1;
+
+-- name: InsertWebhook :one
+INSERT INTO webhooks
+    ( "room_id", "url", "event_kinds", "secret" ) VALUES
+    ( $1, $2, $3, $4 )
+RETURNING "id", "room_id", "url", "event_kinds", "secret", "created_at";
+
+-- name: GetWebhook :one
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    id = $1;
+
+-- name: GetRoomWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = $1
+ORDER BY created_at;
+
+-- name: GetGlobalWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id IS NULL
+ORDER BY created_at;
+
+-- name: GetEventWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = $1
+    OR room_id IS NULL
+ORDER BY created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE
+    id = $1;
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries
+    ( "webhook_id", "event_kind", "payload" ) VALUES
+    ( $1, $2, $3 );
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = sqlc.arg(lease_until)
+WHERE
+    id IN (
+        SELECT id FROM webhook_deliveries AS due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= now()
+        ORDER BY due.next_attempt_at
+        LIMIT sqlc.arg(max_deliveries)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at";
+
+-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    last_error = $4,
+    completed_at = now()
+WHERE
+    id = $1;
+
+-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = $3,
+    next_attempt_at = $4
+WHERE
+    id = $1;
+
+-- name: GetWebhookDeliveries :many
+SELECT
+    "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+FROM webhook_deliveries
+WHERE
+    webhook_id = sqlc.arg(webhook_id)
+    AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_deliveries);
+
+-- name: DeleteCompletedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE
+    completed_at IS NOT NULL
+    AND completed_at < $1;
+
+-- name: InsertApiKey :one
+INSERT INTO api_keys
+    ( "name", "prefix", "key_hash" ) VALUES
+    ( $1, $2, $3 )
+RETURNING "id", "name", "prefix", "key_hash", "created_at";
+
+-- name: GetApiKeyByHash :one
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+WHERE
+    key_hash = $1;
+
+-- name: GetApiKeys :many
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+ORDER BY created_at;
+
+-- name: DeleteApiKey :execrows
+DELETE FROM api_keys
+WHERE
+    id = $1;
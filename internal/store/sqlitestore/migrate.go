@@ -0,0 +1,86 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"net/url"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/migrate"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open opens the database at path, creating it when it doesn't exist.
+// Foreign keys are off by default in SQLite, so they are turned on for every
+// connection.
+func Open(path string) (*sql.DB, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer, queueing the writes here is cheaper than
+	// retrying them on SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// Migrate applies the migrations that are newer than the schema version
+// stored in the database, each one in its own transaction.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	migrator, err := NewMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(ctx)
+}
+
+// NewMigrator returns the migrator of the schema of db. No lock is taken,
+// the single connection of the database already keeps the migrations apart.
+func NewMigrator(ctx context.Context, db *sql.DB) (*migrate.Migrator, error) {
+	list, err := migrate.Load(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return nil, err
+	}
+	return migrate.New(schema{db}, list), nil
+}
+
+type schema struct {
+	db *sql.DB
+}
+
+func (s schema) Version(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+func (s schema) Apply(ctx context.Context, statements string, version int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_version`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES (?)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,92 @@
+-- SQLite has no uuid, timestamp or array types: ids are stored as text,
+-- timestamps as unix microseconds and lists as JSON arrays.
+
+CREATE TABLE IF NOT EXISTS rooms (
+"id"                   TEXT      PRIMARY KEY   NOT NULL,
+"subject"              TEXT                    NOT NULL,
+"description"          TEXT                    NOT NULL   DEFAULT '',
+"starts_at"            INTEGER,
+"ends_at"              INTEGER,
+"host_name"            TEXT                    NOT NULL   DEFAULT '',
+"cover_image_url"      TEXT                    NOT NULL   DEFAULT '',
+"slug"                 TEXT                    NOT NULL,
+"join_code"            TEXT                    NOT NULL,
+"passcode_hash"        TEXT                    NOT NULL   DEFAULT '',
+"private"              BOOLEAN                 NOT NULL   DEFAULT false,
+"created_at"           INTEGER                 NOT NULL,
+"closed_at"            INTEGER,
+"anonymized_at"        INTEGER,
+"reaction_kinds"       TEXT                    NOT NULL   DEFAULT '["like"]',
+"downvotes_enabled"    BOOLEAN                 NOT NULL   DEFAULT false,
+"spotlight_message_id" TEXT,
+"tags"                 TEXT                    NOT NULL   DEFAULT '[]',
+CONSTRAINT rooms_slug_key UNIQUE (slug),
+CONSTRAINT rooms_join_code_key UNIQUE (join_code),
+CONSTRAINT rooms_schedule_check CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at),
+FOREIGN KEY (spotlight_message_id) REFERENCES messages(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+"id"               TEXT      PRIMARY KEY   NOT NULL,
+"room_id"          TEXT                    NOT NULL,
+"message"          TEXT                    NOT NULL,
+"likes_count"      INTEGER                 NOT NULL   DEFAULT 0,
+"answered"         BOOLEAN                 NOT NULL   DEFAULT false,
+"created_at"       INTEGER                 NOT NULL,
+"downvotes_count"  INTEGER                 NOT NULL   DEFAULT 0,
+"pinned"           BOOLEAN                 NOT NULL   DEFAULT false,
+"tag"              TEXT                    NOT NULL   DEFAULT '',
+"moderation_flags" TEXT                    NOT NULL   DEFAULT '[]',
+FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS messages_room_id_created_at_idx ON messages (room_id, created_at);
+CREATE INDEX IF NOT EXISTS messages_room_id_tag_idx ON messages (room_id, tag);
+
+CREATE TABLE IF NOT EXISTS message_reactions (
+"message_id"    TEXT                       NOT NULL,
+"kind"          TEXT                       NOT NULL,
+"count"         INTEGER                    NOT NULL   DEFAULT 0,
+PRIMARY KEY (message_id, kind),
+FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS polls (
+"id"            TEXT         PRIMARY KEY   NOT NULL,
+"room_id"       TEXT                       NOT NULL,
+"question"      TEXT                       NOT NULL,
+"created_at"    INTEGER                    NOT NULL,
+"closed_at"     INTEGER,
+FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+"id"            TEXT         PRIMARY KEY   NOT NULL,
+"poll_id"       TEXT                       NOT NULL,
+"position"      INTEGER                    NOT NULL,
+"label"         TEXT                       NOT NULL,
+"votes_count"   INTEGER                    NOT NULL   DEFAULT 0,
+FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+"poll_id"        TEXT                      NOT NULL,
+"participant_id" TEXT                      NOT NULL,
+"option_id"      TEXT                      NOT NULL,
+"created_at"     INTEGER                   NOT NULL,
+PRIMARY KEY (poll_id, participant_id),
+FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS polls_room_id_idx ON polls (room_id);
+CREATE INDEX IF NOT EXISTS poll_options_poll_id_idx ON poll_options (poll_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS message_reactions;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS rooms;
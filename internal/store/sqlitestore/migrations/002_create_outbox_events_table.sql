@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+"id"            INTEGER   PRIMARY KEY   AUTOINCREMENT,
+"room_id"       TEXT                    NOT NULL,
+"kind"          TEXT                    NOT NULL,
+"payload"       TEXT                    NOT NULL,
+"attempts"      INTEGER                 NOT NULL   DEFAULT 0,
+"created_at"    INTEGER                 NOT NULL,
+"dispatched_at" INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (id) WHERE dispatched_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+"id"            TEXT      PRIMARY KEY   NOT NULL,
+"room_id"       TEXT,
+"url"           TEXT                    NOT NULL,
+"event_kinds"   TEXT                    NOT NULL   DEFAULT '[]',
+"secret"        TEXT                    NOT NULL,
+"created_at"    INTEGER                 NOT NULL,
+FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+"id"              TEXT      PRIMARY KEY   NOT NULL,
+"webhook_id"      TEXT                    NOT NULL,
+"event_kind"      TEXT                    NOT NULL,
+"payload"         TEXT                    NOT NULL,
+"status"          TEXT                    NOT NULL   DEFAULT 'pending',
+"attempts"        INTEGER                 NOT NULL   DEFAULT 0,
+"response_status" INTEGER,
+"last_error"      TEXT                    NOT NULL   DEFAULT '',
+"next_attempt_at" INTEGER                 NOT NULL,
+"created_at"      INTEGER                 NOT NULL,
+"completed_at"    INTEGER,
+FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_room_id_idx ON webhooks (room_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,7 @@
+ALTER TABLE messages
+    ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE messages
+    DROP COLUMN hidden;
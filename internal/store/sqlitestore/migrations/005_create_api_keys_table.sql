@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+"id"            TEXT      PRIMARY KEY   NOT NULL,
+"name"          TEXT                    NOT NULL,
+"prefix"        TEXT                    NOT NULL,
+"key_hash"      TEXT      UNIQUE        NOT NULL,
+"created_at"    INTEGER                 NOT NULL
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,11 @@
+ALTER TABLE outbox_events
+    ADD COLUMN handled INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE outbox_events
+    ADD COLUMN claimed_until INTEGER;
+
+---- create above / drop below ----
+
+ALTER TABLE outbox_events
+    DROP COLUMN claimed_until;
+ALTER TABLE outbox_events
+    DROP COLUMN handled;
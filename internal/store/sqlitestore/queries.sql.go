@@ -0,0 +1,2217 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: queries.sql
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const anonymizeArchivedRoomMessages = `-- name: AnonymizeArchivedRoomMessages :execrows
+UPDATE messages
+SET
+    message = '[removed]'
+WHERE
+    room_id IN (
+        SELECT id FROM rooms
+        WHERE
+            closed_at IS NOT NULL
+            AND closed_at < CAST(?1 AS INTEGER)
+            AND anonymized_at IS NULL
+    )
+`
+
+func (q *Queries) AnonymizeArchivedRoomMessages(ctx context.Context, closedBefore int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeArchivedRoomMessages, closedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const anonymizeArchivedRoomVotes = `-- name: AnonymizeArchivedRoomVotes :exec
+UPDATE poll_votes
+SET
+    participant_id = lower(hex(randomblob(16)))
+WHERE
+    poll_id IN (
+        SELECT polls.id FROM polls
+        JOIN rooms ON rooms.id = polls.room_id
+        WHERE
+            rooms.closed_at IS NOT NULL
+            AND rooms.closed_at < CAST(?1 AS INTEGER)
+            AND rooms.anonymized_at IS NULL
+    )
+`
+
+func (q *Queries) AnonymizeArchivedRoomVotes(ctx context.Context, closedBefore int64) error {
+	_, err := q.db.ExecContext(ctx, anonymizeArchivedRoomVotes, closedBefore)
+	return err
+}
+
+const anonymizeArchivedRooms = `-- name: AnonymizeArchivedRooms :execrows
+UPDATE rooms
+SET
+    subject = '[removed]',
+    slug = id,
+    description = '',
+    host_name = '',
+    cover_image_url = '',
+    anonymized_at = CAST(?1 AS INTEGER)
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < CAST(?2 AS INTEGER)
+    AND anonymized_at IS NULL
+`
+
+type AnonymizeArchivedRoomsParams struct {
+	Now          int64
+	ClosedBefore int64
+}
+
+func (q *Queries) AnonymizeArchivedRooms(ctx context.Context, arg AnonymizeArchivedRoomsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeArchivedRooms, arg.Now, arg.ClosedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = CAST(?1 AS INTEGER)
+WHERE
+    id IN (
+        SELECT due.id FROM webhook_deliveries AS due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= CAST(?2 AS INTEGER)
+        ORDER BY due.next_attempt_at
+        LIMIT ?3
+    )
+RETURNING "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseUntil    int64
+	Now           int64
+	MaxDeliveries int64
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueWebhookDeliveries, arg.LeaseUntil, arg.Now, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventKind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimPendingOutboxEvents = `-- name: ClaimPendingOutboxEvents :many
+UPDATE outbox_events
+SET
+    claimed_until = CAST(?1 AS INTEGER)
+WHERE
+    id IN (
+        SELECT pending.id FROM outbox_events AS pending
+        WHERE pending.dispatched_at IS NULL
+            AND (pending.claimed_until IS NULL OR pending.claimed_until <= CAST(?2 AS INTEGER))
+        ORDER BY pending.id
+        LIMIT ?3
+    )
+RETURNING "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+`
+
+type ClaimPendingOutboxEventsParams struct {
+	LeaseUntil int64
+	Now        int64
+	MaxEvents  int64
+}
+
+func (q *Queries) ClaimPendingOutboxEvents(ctx context.Context, arg ClaimPendingOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, claimPendingOutboxEvents, arg.LeaseUntil, arg.Now, arg.MaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Kind,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.DispatchedAt,
+			&i.Handled,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closeEndedRooms = `-- name: CloseEndedRooms :many
+UPDATE rooms
+SET
+    closed_at = CAST(?1 AS INTEGER)
+WHERE
+    closed_at IS NULL
+    AND ends_at IS NOT NULL
+    AND ends_at < CAST(?2 AS INTEGER)
+RETURNING id
+`
+
+type CloseEndedRoomsParams struct {
+	Now         int64
+	EndedBefore int64
+}
+
+func (q *Queries) CloseEndedRooms(ctx context.Context, arg CloseEndedRoomsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, closeEndedRooms, arg.Now, arg.EndedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closeInactiveRooms = `-- name: CloseInactiveRooms :many
+UPDATE rooms
+SET
+    closed_at = CAST(?1 AS INTEGER)
+WHERE
+    closed_at IS NULL
+    AND MAX(rooms.created_at, COALESCE(rooms.starts_at, rooms.created_at)) < CAST(?2 AS INTEGER)
+    AND (rooms.starts_at IS NULL OR rooms.starts_at <= CAST(?1 AS INTEGER))
+    -- sqlc leaves the arguments of a NOT EXISTS subquery as they are
+    AND rooms.id NOT IN (
+        SELECT messages.room_id FROM messages WHERE messages.created_at >= CAST(?2 AS INTEGER)
+    )
+RETURNING id
+`
+
+type CloseInactiveRoomsParams struct {
+	Now           int64
+	InactiveSince int64
+}
+
+func (q *Queries) CloseInactiveRooms(ctx context.Context, arg CloseInactiveRoomsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, closeInactiveRooms, arg.Now, arg.InactiveSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closePoll = `-- name: ClosePoll :one
+UPDATE polls
+SET
+    closed_at = COALESCE(closed_at, CAST(?1 AS INTEGER))
+WHERE
+    id = ?2
+RETURNING "id", "room_id", "question", "created_at", "closed_at"
+`
+
+type ClosePollParams struct {
+	Now int64
+	ID  uuid.UUID
+}
+
+func (q *Queries) ClosePoll(ctx context.Context, arg ClosePollParams) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, closePoll, arg.Now, arg.ID)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const closeRoom = `-- name: CloseRoom :one
+UPDATE rooms
+SET
+    closed_at = COALESCE(closed_at, CAST(?1 AS INTEGER))
+WHERE
+    id = ?2
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type CloseRoomParams struct {
+	Now int64
+	ID  uuid.UUID
+}
+
+func (q *Queries) CloseRoom(ctx context.Context, arg CloseRoomParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, closeRoom, arg.Now, arg.ID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const completeWebhookDelivery = `-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = ?1,
+    attempts = attempts + 1,
+    response_status = ?2,
+    last_error = ?3,
+    completed_at = CAST(?4 AS INTEGER)
+WHERE
+    id = ?5
+`
+
+type CompleteWebhookDeliveryParams struct {
+	Status         string
+	ResponseStatus sql.NullInt64
+	LastError      string
+	Now            int64
+	ID             uuid.UUID
+}
+
+func (q *Queries) CompleteWebhookDelivery(ctx context.Context, arg CompleteWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, completeWebhookDelivery,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.Now,
+		arg.ID,
+	)
+	return err
+}
+
+const decrementMessageDownvotes = `-- name: DecrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = MAX(downvotes_count - 1, 0)
+WHERE
+    id = ?
+RETURNING "downvotes_count"
+`
+
+func (q *Queries) DecrementMessageDownvotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, decrementMessageDownvotes, id)
+	var downvotes_count int64
+	err := row.Scan(&downvotes_count)
+	return downvotes_count, err
+}
+
+const decrementMessageReaction = `-- name: DecrementMessageReaction :one
+UPDATE message_reactions
+SET
+    count = MAX(count - 1, 0)
+WHERE
+    message_id = ?
+    AND kind = ?
+RETURNING "count"
+`
+
+type DecrementMessageReactionParams struct {
+	MessageID uuid.UUID
+	Kind      string
+}
+
+func (q *Queries) DecrementMessageReaction(ctx context.Context, arg DecrementMessageReactionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, decrementMessageReaction, arg.MessageID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteApiKey = `-- name: DeleteApiKey :execrows
+DELETE FROM api_keys
+WHERE
+    id = ?
+`
+
+func (q *Queries) DeleteApiKey(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteApiKey, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteArchivedRooms = `-- name: DeleteArchivedRooms :many
+DELETE FROM rooms
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < CAST(?1 AS INTEGER)
+RETURNING id
+`
+
+func (q *Queries) DeleteArchivedRooms(ctx context.Context, closedBefore int64) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteArchivedRooms, closedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteCompletedWebhookDeliveries = `-- name: DeleteCompletedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE
+    completed_at IS NOT NULL
+    AND completed_at < CAST(?1 AS INTEGER)
+`
+
+func (q *Queries) DeleteCompletedWebhookDeliveries(ctx context.Context, completedBefore int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCompletedWebhookDeliveries, completedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteDispatchedOutboxEvents = `-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE
+    dispatched_at IS NOT NULL
+    AND dispatched_at < CAST(?1 AS INTEGER)
+`
+
+func (q *Queries) DeleteDispatchedOutboxEvents(ctx context.Context, dispatchedBefore int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDispatchedOutboxEvents, dispatchedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRoom = `-- name: DeleteRoom :execrows
+DELETE FROM rooms
+WHERE
+    id = ?
+`
+
+func (q *Queries) DeleteRoom(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRoom, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE
+    id = ?
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhook, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failOutboxEvent = `-- name: FailOutboxEvent :exec
+UPDATE outbox_events
+SET
+    attempts = CASE WHEN handled = ?1 THEN attempts + 1 ELSE 1 END,
+    handled = ?1,
+    claimed_until = NULL
+WHERE
+    id = ?2
+`
+
+type FailOutboxEventParams struct {
+	Handled int64
+	ID      int64
+}
+
+func (q *Queries) FailOutboxEvent(ctx context.Context, arg FailOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, failOutboxEvent, arg.Handled, arg.ID)
+	return err
+}
+
+const getApiKeyByHash = `-- name: GetApiKeyByHash :one
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+WHERE
+    key_hash = ?
+`
+
+func (q *Queries) GetApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getApiKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApiKeys = `-- name: GetApiKeys :many
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+ORDER BY created_at
+`
+
+func (q *Queries) GetApiKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, getApiKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventWebhooks = `-- name: GetEventWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = ?1
+    OR room_id IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetEventWebhooks(ctx context.Context, roomID uuid.NullUUID) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getEventWebhooks, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGlobalWebhooks = `-- name: GetGlobalWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetGlobalWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getGlobalWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMessage = `-- name: GetMessage :one
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    id = ?
+`
+
+func (q *Queries) GetMessage(ctx context.Context, id uuid.UUID) (Message, error) {
+	row := q.db.QueryRowContext(ctx, getMessage, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
+}
+
+const getMessageReactions = `-- name: GetMessageReactions :many
+SELECT
+    "message_id", "kind", "count"
+FROM message_reactions
+WHERE
+    message_id = ?
+    AND count > 0
+`
+
+func (q *Queries) GetMessageReactions(ctx context.Context, messageID uuid.UUID) ([]MessageReaction, error) {
+	rows, err := q.db.QueryContext(ctx, getMessageReactions, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageReaction
+	for rows.Next() {
+		var i MessageReaction
+		if err := rows.Scan(&i.MessageID, &i.Kind, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingOutboxEvents = `-- name: GetPendingOutboxEvents :many
+SELECT
+    "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+FROM outbox_events
+WHERE
+    dispatched_at IS NULL
+ORDER BY id
+LIMIT ?
+`
+
+func (q *Queries) GetPendingOutboxEvents(ctx context.Context, limit int64) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Kind,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.DispatchedAt,
+			&i.Handled,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPoll = `-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    id = ?
+`
+
+func (q *Queries) GetPoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, getPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getPollOptions = `-- name: GetPollOptions :many
+SELECT
+    "id", "poll_id", "position", "label", "votes_count"
+FROM poll_options
+WHERE
+    poll_id = ?
+ORDER BY position
+`
+
+func (q *Queries) GetPollOptions(ctx context.Context, pollID uuid.UUID) ([]PollOption, error) {
+	rows, err := q.db.QueryContext(ctx, getPollOptions, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+			&i.VotesCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoom = `-- name: GetRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = ?
+`
+
+func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRowContext(ctx, getRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getRoomByJoinCode = `-- name: GetRoomByJoinCode :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE join_code = ?
+`
+
+func (q *Queries) GetRoomByJoinCode(ctx context.Context, joinCode string) (Room, error) {
+	row := q.db.QueryRowContext(ctx, getRoomByJoinCode, joinCode)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getRoomMessageReactions = `-- name: GetRoomMessageReactions :many
+SELECT
+    message_reactions.message_id, message_reactions.kind, message_reactions.count
+FROM message_reactions
+JOIN messages ON messages.id = message_reactions.message_id
+WHERE
+    messages.room_id = ?
+    AND message_reactions.count > 0
+`
+
+func (q *Queries) GetRoomMessageReactions(ctx context.Context, roomID uuid.UUID) ([]MessageReaction, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomMessageReactions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageReaction
+	for rows.Next() {
+		var i MessageReaction
+		if err := rows.Scan(&i.MessageID, &i.Kind, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessages = `-- name: GetRoomMessages :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = ?
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomMessages, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessagesByTag = `-- name: GetRoomMessagesByTag :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = ?1
+    AND tag = ?2
+ORDER BY created_at, id
+`
+
+type GetRoomMessagesByTagParams struct {
+	RoomID uuid.UUID
+	Tag    string
+}
+
+func (q *Queries) GetRoomMessagesByTag(ctx context.Context, arg GetRoomMessagesByTagParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomMessagesByTag, arg.RoomID, arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomMessagesPage = `-- name: GetRoomMessagesPage :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = ?1
+    AND (created_at > ?2 OR (created_at = ?2 AND id > ?3))
+ORDER BY created_at, id
+LIMIT ?4
+`
+
+type GetRoomMessagesPageParams struct {
+	RoomID         uuid.UUID
+	AfterCreatedAt int64
+	AfterID        uuid.UUID
+	PageSize       int64
+}
+
+func (q *Queries) GetRoomMessagesPage(ctx context.Context, arg GetRoomMessagesPageParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomMessagesPage,
+		arg.RoomID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.LikesCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.DownvotesCount,
+			&i.Pinned,
+			&i.Tag,
+			&i.ModerationFlags,
+			&i.Hidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPollOptions = `-- name: GetRoomPollOptions :many
+SELECT
+    poll_options.id, poll_options.poll_id, poll_options.position, poll_options.label, poll_options.votes_count
+FROM poll_options
+JOIN polls ON polls.id = poll_options.poll_id
+WHERE
+    polls.room_id = ?
+ORDER BY poll_options.poll_id, poll_options.position
+`
+
+func (q *Queries) GetRoomPollOptions(ctx context.Context, roomID uuid.UUID) ([]PollOption, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomPollOptions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+			&i.VotesCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPolls = `-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    room_id = ?
+ORDER BY created_at
+`
+
+func (q *Queries) GetRoomPolls(ctx context.Context, roomID uuid.UUID) ([]Poll, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomPolls, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Question,
+			&i.CreatedAt,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomSlugs = `-- name: GetRoomSlugs :many
+SELECT slug FROM rooms
+WHERE
+    (slug = ?1 OR slug LIKE ?1 || '-%')
+    AND id <> ?2
+`
+
+type GetRoomSlugsParams struct {
+	Slug string
+	ID   uuid.UUID
+}
+
+func (q *Queries) GetRoomSlugs(ctx context.Context, arg GetRoomSlugsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomSlugs, arg.Slug, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		items = append(items, slug)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomWebhooks = `-- name: GetRoomWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = ?
+ORDER BY created_at
+`
+
+func (q *Queries) GetRoomWebhooks(ctx context.Context, roomID uuid.NullUUID) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomWebhooks, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Url,
+			&i.EventKinds,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRooms = `-- name: GetRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE private = false
+ORDER BY created_at
+`
+
+func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
+	rows, err := q.db.QueryContext(ctx, getRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.Description,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.HostName,
+			&i.CoverImageUrl,
+			&i.Slug,
+			&i.JoinCode,
+			&i.PasscodeHash,
+			&i.Private,
+			&i.CreatedAt,
+			&i.ClosedAt,
+			&i.AnonymizedAt,
+			&i.ReactionKinds,
+			&i.DownvotesEnabled,
+			&i.SpotlightMessageID,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomsTagCounts = `-- name: GetRoomsTagCounts :many
+SELECT
+    "room_id", "tag", count(*) AS messages_count
+FROM messages
+WHERE
+    room_id IN (/*SLICE:room_ids*/?)
+    AND tag <> ''
+GROUP BY room_id, tag
+`
+
+type GetRoomsTagCountsRow struct {
+	RoomID        uuid.UUID
+	Tag           string
+	MessagesCount int64
+}
+
+func (q *Queries) GetRoomsTagCounts(ctx context.Context, roomIds []uuid.UUID) ([]GetRoomsTagCountsRow, error) {
+	query := getRoomsTagCounts
+	var queryParams []interface{}
+	if len(roomIds) > 0 {
+		for _, v := range roomIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:room_ids*/?", strings.Repeat(",?", len(roomIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:room_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomsTagCountsRow
+	for rows.Next() {
+		var i GetRoomsTagCountsRow
+		if err := rows.Scan(&i.RoomID, &i.Tag, &i.MessagesCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    id = ?
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Url,
+		&i.EventKinds,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT
+    "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+FROM webhook_deliveries
+WHERE
+    webhook_id = ?1
+    AND (CAST(?2 AS TEXT) = '' OR status = CAST(?2 AS TEXT))
+ORDER BY created_at DESC
+LIMIT ?3
+`
+
+type GetWebhookDeliveriesParams struct {
+	WebhookID     uuid.UUID
+	Status        string
+	MaxDeliveries int64
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveries, arg.WebhookID, arg.Status, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventKind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementMessageDownvotes = `-- name: IncrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = downvotes_count + 1
+WHERE
+    id = ?
+RETURNING "downvotes_count"
+`
+
+func (q *Queries) IncrementMessageDownvotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, incrementMessageDownvotes, id)
+	var downvotes_count int64
+	err := row.Scan(&downvotes_count)
+	return downvotes_count, err
+}
+
+const incrementMessageReaction = `-- name: IncrementMessageReaction :one
+INSERT INTO message_reactions
+    ( "message_id", "kind", "count" ) VALUES
+    ( ?, ?, 1 )
+ON CONFLICT ( "message_id", "kind" ) DO UPDATE
+SET
+    count = message_reactions.count + 1
+RETURNING "count"
+`
+
+type IncrementMessageReactionParams struct {
+	MessageID uuid.UUID
+	Kind      string
+}
+
+func (q *Queries) IncrementMessageReaction(ctx context.Context, arg IncrementMessageReactionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, incrementMessageReaction, arg.MessageID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const incrementPollOptionVotes = `-- name: IncrementPollOptionVotes :exec
+UPDATE poll_options
+SET
+    votes_count = votes_count + 1
+WHERE
+    id = ?
+`
+
+func (q *Queries) IncrementPollOptionVotes(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementPollOptionVotes, id)
+	return err
+}
+
+const insertApiKey = `-- name: InsertApiKey :exec
+INSERT INTO api_keys
+    ( "id", "name", "prefix", "key_hash", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ? )
+`
+
+type InsertApiKeyParams struct {
+	ID        uuid.UUID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	CreatedAt int64
+}
+
+func (q *Queries) InsertApiKey(ctx context.Context, arg InsertApiKeyParams) error {
+	_, err := q.db.ExecContext(ctx, insertApiKey,
+		arg.ID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertMessage = `-- name: InsertMessage :exec
+INSERT INTO messages
+    ( "id", "room_id", "message", "tag", "moderation_flags", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? )
+`
+
+type InsertMessageParams struct {
+	ID              uuid.UUID
+	RoomID          uuid.UUID
+	Message         string
+	Tag             string
+	ModerationFlags string
+	CreatedAt       int64
+}
+
+func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) error {
+	_, err := q.db.ExecContext(ctx, insertMessage,
+		arg.ID,
+		arg.RoomID,
+		arg.Message,
+		arg.Tag,
+		arg.ModerationFlags,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events
+    ( "room_id", "kind", "payload", "created_at" ) VALUES
+    ( ?, ?, ?, ? )
+`
+
+type InsertOutboxEventParams struct {
+	RoomID    uuid.UUID
+	Kind      string
+	Payload   string
+	CreatedAt int64
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertOutboxEvent,
+		arg.RoomID,
+		arg.Kind,
+		arg.Payload,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertPoll = `-- name: InsertPoll :exec
+INSERT INTO polls
+    ( "id", "room_id", "question", "created_at" ) VALUES
+    ( ?, ?, ?, ? )
+`
+
+type InsertPollParams struct {
+	ID        uuid.UUID
+	RoomID    uuid.UUID
+	Question  string
+	CreatedAt int64
+}
+
+func (q *Queries) InsertPoll(ctx context.Context, arg InsertPollParams) error {
+	_, err := q.db.ExecContext(ctx, insertPoll,
+		arg.ID,
+		arg.RoomID,
+		arg.Question,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertPollOption = `-- name: InsertPollOption :exec
+INSERT INTO poll_options
+    ( "id", "poll_id", "position", "label" ) VALUES
+    ( ?, ?, ?, ? )
+`
+
+type InsertPollOptionParams struct {
+	ID       uuid.UUID
+	PollID   uuid.UUID
+	Position int64
+	Label    string
+}
+
+func (q *Queries) InsertPollOption(ctx context.Context, arg InsertPollOptionParams) error {
+	_, err := q.db.ExecContext(ctx, insertPollOption,
+		arg.ID,
+		arg.PollID,
+		arg.Position,
+		arg.Label,
+	)
+	return err
+}
+
+const insertPollVote = `-- name: InsertPollVote :execrows
+INSERT INTO poll_votes
+    ( "poll_id", "participant_id", "option_id", "created_at" ) VALUES
+    ( ?, ?, ?, ? )
+ON CONFLICT ( "poll_id", "participant_id" ) DO NOTHING
+`
+
+type InsertPollVoteParams struct {
+	PollID        uuid.UUID
+	ParticipantID string
+	OptionID      uuid.UUID
+	CreatedAt     int64
+}
+
+func (q *Queries) InsertPollVote(ctx context.Context, arg InsertPollVoteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertPollVote,
+		arg.PollID,
+		arg.ParticipantID,
+		arg.OptionID,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const insertRoom = `-- name: InsertRoom :one
+INSERT INTO rooms
+    ( "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "reaction_kinds", "downvotes_enabled", "tags" ) VALUES
+    ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type InsertRoomParams struct {
+	ID               uuid.UUID
+	Subject          string
+	Description      string
+	StartsAt         sql.NullInt64
+	EndsAt           sql.NullInt64
+	HostName         string
+	CoverImageUrl    string
+	Slug             string
+	JoinCode         string
+	PasscodeHash     string
+	Private          bool
+	CreatedAt        int64
+	ReactionKinds    string
+	DownvotesEnabled bool
+	Tags             string
+}
+
+func (q *Queries) InsertRoom(ctx context.Context, arg InsertRoomParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, insertRoom,
+		arg.ID,
+		arg.Subject,
+		arg.Description,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.HostName,
+		arg.CoverImageUrl,
+		arg.Slug,
+		arg.JoinCode,
+		arg.PasscodeHash,
+		arg.Private,
+		arg.CreatedAt,
+		arg.ReactionKinds,
+		arg.DownvotesEnabled,
+		arg.Tags,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const insertWebhook = `-- name: InsertWebhook :exec
+INSERT INTO webhooks
+    ( "id", "room_id", "url", "event_kinds", "secret", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? )
+`
+
+type InsertWebhookParams struct {
+	ID         uuid.UUID
+	RoomID     uuid.NullUUID
+	Url        string
+	EventKinds string
+	Secret     string
+	CreatedAt  int64
+}
+
+func (q *Queries) InsertWebhook(ctx context.Context, arg InsertWebhookParams) error {
+	_, err := q.db.ExecContext(ctx, insertWebhook,
+		arg.ID,
+		arg.RoomID,
+		arg.Url,
+		arg.EventKinds,
+		arg.Secret,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertWebhookDelivery = `-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries
+    ( "id", "webhook_id", "event_kind", "payload", "next_attempt_at", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? )
+`
+
+type InsertWebhookDeliveryParams struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	EventKind     string
+	Payload       string
+	NextAttemptAt int64
+	CreatedAt     int64
+}
+
+func (q *Queries) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, insertWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.EventKind,
+		arg.Payload,
+		arg.NextAttemptAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const isRoomSlugTaken = `-- name: IsRoomSlugTaken :one
+SELECT CAST(EXISTS (
+    SELECT 1 FROM rooms WHERE slug = ?1 AND id <> ?2
+) AS BOOLEAN)
+`
+
+type IsRoomSlugTakenParams struct {
+	Slug string
+	ID   uuid.UUID
+}
+
+func (q *Queries) IsRoomSlugTaken(ctx context.Context, arg IsRoomSlugTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isRoomSlugTaken, arg.Slug, arg.ID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const lockRoom = `-- name: LockRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = ?
+`
+
+func (q *Queries) LockRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRowContext(ctx, lockRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const markMessageAsAnswered = `-- name: MarkMessageAsAnswered :exec
+UPDATE messages
+SET
+    answered = true
+WHERE
+    id = ?
+`
+
+func (q *Queries) MarkMessageAsAnswered(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markMessageAsAnswered, id)
+	return err
+}
+
+const markOutboxEventDispatched = `-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET
+    dispatched_at = CAST(?1 AS INTEGER)
+WHERE
+    id = ?2
+`
+
+type MarkOutboxEventDispatchedParams struct {
+	Now int64
+	ID  int64
+}
+
+func (q *Queries) MarkOutboxEventDispatched(ctx context.Context, arg MarkOutboxEventDispatchedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventDispatched, arg.Now, arg.ID)
+	return err
+}
+
+const recountRoomMessageLikes = `-- name: RecountRoomMessageLikes :execrows
+UPDATE messages
+SET
+    likes_count = COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
+WHERE
+    room_id = ?
+    AND likes_count <> COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
+`
+
+func (q *Queries) RecountRoomMessageLikes(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recountRoomMessageLikes, roomID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseOutboxEvents = `-- name: ReleaseOutboxEvents :exec
+UPDATE outbox_events
+SET
+    claimed_until = NULL
+WHERE
+    id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ReleaseOutboxEvents(ctx context.Context, ids []int64) error {
+	query := releaseOutboxEvents
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const reopenRoom = `-- name: ReopenRoom :one
+UPDATE rooms
+SET
+    closed_at = NULL
+WHERE
+    id = ?
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+func (q *Queries) ReopenRoom(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRowContext(ctx, reopenRoom, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    response_status = ?1,
+    last_error = ?2,
+    next_attempt_at = CAST(?3 AS INTEGER)
+WHERE
+    id = ?4
+`
+
+type RetryWebhookDeliveryParams struct {
+	ResponseStatus sql.NullInt64
+	LastError      string
+	NextAttemptAt  int64
+	ID             uuid.UUID
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, retryWebhookDelivery,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const searchRooms = `-- name: SearchRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE
+    CAST(?1 AS TEXT) = ''
+    OR instr(lower(subject), lower(?1)) > 0
+    OR instr(lower(slug), lower(?1)) > 0
+    OR instr(lower(host_name), lower(?1)) > 0
+    OR join_code = CAST(?2 AS TEXT)
+ORDER BY created_at
+`
+
+type SearchRoomsParams struct {
+	Query    string
+	JoinCode string
+}
+
+func (q *Queries) SearchRooms(ctx context.Context, arg SearchRoomsParams) ([]Room, error) {
+	rows, err := q.db.QueryContext(ctx, searchRooms, arg.Query, arg.JoinCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.Description,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.HostName,
+			&i.CoverImageUrl,
+			&i.Slug,
+			&i.JoinCode,
+			&i.PasscodeHash,
+			&i.Private,
+			&i.CreatedAt,
+			&i.ClosedAt,
+			&i.AnonymizedAt,
+			&i.ReactionKinds,
+			&i.DownvotesEnabled,
+			&i.SpotlightMessageID,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMessageHidden = `-- name: SetMessageHidden :one
+UPDATE messages
+SET
+    hidden = ?1
+WHERE
+    id = ?2
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+`
+
+type SetMessageHiddenParams struct {
+	Hidden bool
+	ID     uuid.UUID
+}
+
+func (q *Queries) SetMessageHidden(ctx context.Context, arg SetMessageHiddenParams) (Message, error) {
+	row := q.db.QueryRowContext(ctx, setMessageHidden, arg.Hidden, arg.ID)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
+}
+
+const setMessagePinned = `-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = ?1
+WHERE
+    id = ?2
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+`
+
+type SetMessagePinnedParams struct {
+	Pinned bool
+	ID     uuid.UUID
+}
+
+func (q *Queries) SetMessagePinned(ctx context.Context, arg SetMessagePinnedParams) (Message, error) {
+	row := q.db.QueryRowContext(ctx, setMessagePinned, arg.Pinned, arg.ID)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.LikesCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.DownvotesCount,
+		&i.Pinned,
+		&i.Tag,
+		&i.ModerationFlags,
+		&i.Hidden,
+	)
+	return i, err
+}
+
+const updateMessageLikes = `-- name: UpdateMessageLikes :exec
+UPDATE messages
+SET
+    likes_count = ?1
+WHERE
+    id = ?2
+`
+
+type UpdateMessageLikesParams struct {
+	LikesCount int64
+	ID         uuid.UUID
+}
+
+func (q *Queries) UpdateMessageLikes(ctx context.Context, arg UpdateMessageLikesParams) error {
+	_, err := q.db.ExecContext(ctx, updateMessageLikes, arg.LikesCount, arg.ID)
+	return err
+}
+
+const updateRoom = `-- name: UpdateRoom :one
+UPDATE rooms
+SET
+    subject = ?1,
+    description = ?2,
+    starts_at = ?3,
+    ends_at = ?4,
+    host_name = ?5,
+    cover_image_url = ?6,
+    slug = ?7,
+    passcode_hash = ?8,
+    private = ?9,
+    reaction_kinds = ?10,
+    downvotes_enabled = ?11,
+    tags = ?12
+WHERE
+    id = ?13
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type UpdateRoomParams struct {
+	Subject          string
+	Description      string
+	StartsAt         sql.NullInt64
+	EndsAt           sql.NullInt64
+	HostName         string
+	CoverImageUrl    string
+	Slug             string
+	PasscodeHash     string
+	Private          bool
+	ReactionKinds    string
+	DownvotesEnabled bool
+	Tags             string
+	ID               uuid.UUID
+}
+
+func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, updateRoom,
+		arg.Subject,
+		arg.Description,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.HostName,
+		arg.CoverImageUrl,
+		arg.Slug,
+		arg.PasscodeHash,
+		arg.Private,
+		arg.ReactionKinds,
+		arg.DownvotesEnabled,
+		arg.Tags,
+		arg.ID,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const updateRoomJoinCode = `-- name: UpdateRoomJoinCode :one
+UPDATE rooms
+SET
+    join_code = ?1
+WHERE
+    id = ?2
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type UpdateRoomJoinCodeParams struct {
+	JoinCode string
+	ID       uuid.UUID
+}
+
+func (q *Queries) UpdateRoomJoinCode(ctx context.Context, arg UpdateRoomJoinCodeParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, updateRoomJoinCode, arg.JoinCode, arg.ID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const updateRoomSpotlight = `-- name: UpdateRoomSpotlight :one
+UPDATE rooms
+SET
+    spotlight_message_id = ?1
+WHERE
+    id = ?2
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+`
+
+type UpdateRoomSpotlightParams struct {
+	SpotlightMessageID uuid.NullUUID
+	ID                 uuid.UUID
+}
+
+func (q *Queries) UpdateRoomSpotlight(ctx context.Context, arg UpdateRoomSpotlightParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, updateRoomSpotlight, arg.SpotlightMessageID, arg.ID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.Description,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.HostName,
+		&i.CoverImageUrl,
+		&i.Slug,
+		&i.JoinCode,
+		&i.PasscodeHash,
+		&i.Private,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.AnonymizedAt,
+		&i.ReactionKinds,
+		&i.DownvotesEnabled,
+		&i.SpotlightMessageID,
+		&i.Tags,
+	)
+	return i, err
+}
@@ -0,0 +1,564 @@
+-- name: GetRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = ?;
+
+-- name: LockRoom :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE id = ?;
+
+-- name: GetRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE private = false
+ORDER BY created_at;
+
+-- name: InsertRoom :one
+INSERT INTO rooms
+    ( "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "reaction_kinds", "downvotes_enabled", "tags" ) VALUES
+    ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: UpdateRoom :one
+UPDATE rooms
+SET
+    subject = sqlc.arg(subject),
+    description = sqlc.arg(description),
+    starts_at = sqlc.arg(starts_at),
+    ends_at = sqlc.arg(ends_at),
+    host_name = sqlc.arg(host_name),
+    cover_image_url = sqlc.arg(cover_image_url),
+    slug = sqlc.arg(slug),
+    passcode_hash = sqlc.arg(passcode_hash),
+    private = sqlc.arg(private),
+    reaction_kinds = sqlc.arg(reaction_kinds),
+    downvotes_enabled = sqlc.arg(downvotes_enabled),
+    tags = sqlc.arg(tags)
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: IsRoomSlugTaken :one
+SELECT CAST(EXISTS (
+    SELECT 1 FROM rooms WHERE slug = sqlc.arg(slug) AND id <> sqlc.arg(id)
+) AS BOOLEAN);
+
+-- name: GetRoomSlugs :many
+SELECT slug FROM rooms
+WHERE
+    (slug = sqlc.arg(slug) OR slug LIKE sqlc.arg(slug) || '-%')
+    AND id <> sqlc.arg(id);
+
+-- name: GetRoomByJoinCode :one
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE join_code = ?;
+
+-- name: UpdateRoomJoinCode :one
+UPDATE rooms
+SET
+    join_code = sqlc.arg(join_code)
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: UpdateRoomSpotlight :one
+UPDATE rooms
+SET
+    spotlight_message_id = sqlc.arg(spotlight_message_id)
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: DeleteRoom :execrows
+DELETE FROM rooms
+WHERE
+    id = ?;
+
+-- name: SearchRooms :many
+SELECT
+    "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags"
+FROM rooms
+WHERE
+    CAST(sqlc.arg(query) AS TEXT) = ''
+    OR instr(lower(subject), lower(sqlc.arg(query))) > 0
+    OR instr(lower(slug), lower(sqlc.arg(query))) > 0
+    OR instr(lower(host_name), lower(sqlc.arg(query))) > 0
+    OR join_code = CAST(sqlc.arg(join_code) AS TEXT)
+ORDER BY created_at;
+
+-- name: CloseRoom :one
+UPDATE rooms
+SET
+    closed_at = COALESCE(closed_at, CAST(sqlc.arg(now) AS INTEGER))
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: ReopenRoom :one
+UPDATE rooms
+SET
+    closed_at = NULL
+WHERE
+    id = ?
+RETURNING "id", "subject", "description", "starts_at", "ends_at", "host_name", "cover_image_url", "slug", "join_code", "passcode_hash", "private", "created_at", "closed_at", "anonymized_at", "reaction_kinds", "downvotes_enabled", "spotlight_message_id", "tags";
+
+-- name: CloseEndedRooms :many
+UPDATE rooms
+SET
+    closed_at = CAST(sqlc.arg(now) AS INTEGER)
+WHERE
+    closed_at IS NULL
+    AND ends_at IS NOT NULL
+    AND ends_at < CAST(sqlc.arg(ended_before) AS INTEGER)
+RETURNING id;
+
+-- name: CloseInactiveRooms :many
+UPDATE rooms
+SET
+    closed_at = CAST(sqlc.arg(now) AS INTEGER)
+WHERE
+    closed_at IS NULL
+    AND MAX(rooms.created_at, COALESCE(rooms.starts_at, rooms.created_at)) < CAST(sqlc.arg(inactive_since) AS INTEGER)
+    AND (rooms.starts_at IS NULL OR rooms.starts_at <= CAST(sqlc.arg(now) AS INTEGER))
+    -- sqlc leaves the arguments of a NOT EXISTS subquery as they are
+    AND rooms.id NOT IN (
+        SELECT messages.room_id FROM messages WHERE messages.created_at >= CAST(sqlc.arg(inactive_since) AS INTEGER)
+    )
+RETURNING id;
+
+-- name: DeleteArchivedRooms :many
+DELETE FROM rooms
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < CAST(sqlc.arg(closed_before) AS INTEGER)
+RETURNING id;
+
+-- name: AnonymizeArchivedRoomMessages :execrows
+UPDATE messages
+SET
+    message = '[removed]'
+WHERE
+    room_id IN (
+        SELECT id FROM rooms
+        WHERE
+            closed_at IS NOT NULL
+            AND closed_at < CAST(sqlc.arg(closed_before) AS INTEGER)
+            AND anonymized_at IS NULL
+    );
+
+-- name: AnonymizeArchivedRoomVotes :exec
+UPDATE poll_votes
+SET
+    participant_id = lower(hex(randomblob(16)))
+WHERE
+    poll_id IN (
+        SELECT polls.id FROM polls
+        JOIN rooms ON rooms.id = polls.room_id
+        WHERE
+            rooms.closed_at IS NOT NULL
+            AND rooms.closed_at < CAST(sqlc.arg(closed_before) AS INTEGER)
+            AND rooms.anonymized_at IS NULL
+    );
+
+-- name: AnonymizeArchivedRooms :execrows
+UPDATE rooms
+SET
+    subject = '[removed]',
+    slug = id,
+    description = '',
+    host_name = '',
+    cover_image_url = '',
+    anonymized_at = CAST(sqlc.arg(now) AS INTEGER)
+WHERE
+    closed_at IS NOT NULL
+    AND closed_at < CAST(sqlc.arg(closed_before) AS INTEGER)
+    AND anonymized_at IS NULL;
+
+-- name: GetMessage :one
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    id = ?;
+
+-- name: GetRoomMessages :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = ?
+ORDER BY created_at, id;
+
+-- name: GetRoomMessagesPage :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = sqlc.arg(room_id)
+    AND (created_at > sqlc.arg(after_created_at) OR (created_at = sqlc.arg(after_created_at) AND id > sqlc.arg(after_id)))
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetRoomMessagesByTag :many
+SELECT
+    "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden"
+FROM messages
+WHERE
+    room_id = sqlc.arg(room_id)
+    AND tag = sqlc.arg(tag)
+ORDER BY created_at, id;
+
+-- name: GetRoomsTagCounts :many
+SELECT
+    "room_id", "tag", count(*) AS messages_count
+FROM messages
+WHERE
+    room_id IN (sqlc.slice(room_ids))
+    AND tag <> ''
+GROUP BY room_id, tag;
+
+-- name: InsertMessage :exec
+INSERT INTO messages
+    ( "id", "room_id", "message", "tag", "moderation_flags", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? );
+
+-- name: IncrementMessageReaction :one
+INSERT INTO message_reactions
+    ( "message_id", "kind", "count" ) VALUES
+    ( ?, ?, 1 )
+ON CONFLICT ( "message_id", "kind" ) DO UPDATE
+SET
+    count = message_reactions.count + 1
+RETURNING "count";
+
+-- name: DecrementMessageReaction :one
+UPDATE message_reactions
+SET
+    count = MAX(count - 1, 0)
+WHERE
+    message_id = ?
+    AND kind = ?
+RETURNING "count";
+
+-- name: UpdateMessageLikes :exec
+UPDATE messages
+SET
+    likes_count = sqlc.arg(likes_count)
+WHERE
+    id = sqlc.arg(id);
+
+-- name: IncrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = downvotes_count + 1
+WHERE
+    id = ?
+RETURNING "downvotes_count";
+
+-- name: DecrementMessageDownvotes :one
+UPDATE messages
+SET
+    downvotes_count = MAX(downvotes_count - 1, 0)
+WHERE
+    id = ?
+RETURNING "downvotes_count";
+
+-- name: GetMessageReactions :many
+SELECT
+    "message_id", "kind", "count"
+FROM message_reactions
+WHERE
+    message_id = ?
+    AND count > 0;
+
+-- name: GetRoomMessageReactions :many
+SELECT
+    message_reactions.message_id, message_reactions.kind, message_reactions.count
+FROM message_reactions
+JOIN messages ON messages.id = message_reactions.message_id
+WHERE
+    messages.room_id = ?
+    AND message_reactions.count > 0;
+
+-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = sqlc.arg(pinned)
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden";
+
+-- name: MarkMessageAsAnswered :exec
+UPDATE messages
+SET
+    answered = true
+WHERE
+    id = ?;
+
+-- name: SetMessageHidden :one
+UPDATE messages
+SET
+    hidden = sqlc.arg(hidden)
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "room_id", "message", "likes_count", "answered", "created_at", "downvotes_count", "pinned", "tag", "moderation_flags", "hidden";
+
+-- name: RecountRoomMessageLikes :execrows
+UPDATE messages
+SET
+    likes_count = COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0)
+WHERE
+    room_id = ?
+    AND likes_count <> COALESCE((
+        SELECT count FROM message_reactions
+        WHERE message_reactions.message_id = messages.id AND message_reactions.kind = 'like'
+    ), 0);
+
+-- name: InsertPoll :exec
+INSERT INTO polls
+    ( "id", "room_id", "question", "created_at" ) VALUES
+    ( ?, ?, ?, ? );
+
+-- name: InsertPollOption :exec
+INSERT INTO poll_options
+    ( "id", "poll_id", "position", "label" ) VALUES
+    ( ?, ?, ?, ? );
+
+-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    id = ?;
+
+-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM polls
+WHERE
+    room_id = ?
+ORDER BY created_at;
+
+-- name: GetPollOptions :many
+SELECT
+    "id", "poll_id", "position", "label", "votes_count"
+FROM poll_options
+WHERE
+    poll_id = ?
+ORDER BY position;
+
+-- name: GetRoomPollOptions :many
+SELECT
+    poll_options.id, poll_options.poll_id, poll_options.position, poll_options.label, poll_options.votes_count
+FROM poll_options
+JOIN polls ON polls.id = poll_options.poll_id
+WHERE
+    polls.room_id = ?
+ORDER BY poll_options.poll_id, poll_options.position;
+
+-- name: InsertPollVote :execrows
+INSERT INTO poll_votes
+    ( "poll_id", "participant_id", "option_id", "created_at" ) VALUES
+    ( ?, ?, ?, ? )
+ON CONFLICT ( "poll_id", "participant_id" ) DO NOTHING;
+
+-- name: IncrementPollOptionVotes :exec
+UPDATE poll_options
+SET
+    votes_count = votes_count + 1
+WHERE
+    id = ?;
+
+-- name: ClosePoll :one
+UPDATE polls
+SET
+    closed_at = COALESCE(closed_at, CAST(sqlc.arg(now) AS INTEGER))
+WHERE
+    id = sqlc.arg(id)
+RETURNING "id", "room_id", "question", "created_at", "closed_at";
+
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events
+    ( "room_id", "kind", "payload", "created_at" ) VALUES
+    ( ?, ?, ?, ? );
+
+-- name: ClaimPendingOutboxEvents :many
+UPDATE outbox_events
+SET
+    claimed_until = CAST(sqlc.arg(lease_until) AS INTEGER)
+WHERE
+    id IN (
+        SELECT pending.id FROM outbox_events AS pending
+        WHERE pending.dispatched_at IS NULL
+            AND (pending.claimed_until IS NULL OR pending.claimed_until <= CAST(sqlc.arg(now) AS INTEGER))
+        ORDER BY pending.id
+        LIMIT sqlc.arg(max_events)
+    )
+RETURNING "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until";
+
+-- name: GetPendingOutboxEvents :many
+SELECT
+    "id", "room_id", "kind", "payload", "attempts", "created_at", "dispatched_at", "handled", "claimed_until"
+FROM outbox_events
+WHERE
+    dispatched_at IS NULL
+ORDER BY id
+LIMIT ?;
+
+-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET
+    dispatched_at = CAST(sqlc.arg(now) AS INTEGER)
+WHERE
+    id = sqlc.arg(id);
+
+-- name: FailOutboxEvent :exec
+UPDATE outbox_events
+SET
+    attempts = CASE WHEN handled = sqlc.arg(handled) THEN attempts + 1 ELSE 1 END,
+    handled = sqlc.arg(handled),
+    claimed_until = NULL
+WHERE
+    id = sqlc.arg(id);
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox_events
+SET
+    claimed_until = NULL
+WHERE
+    id IN (sqlc.slice(ids));
+
+-- name: DeleteDispatchedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE
+    dispatched_at IS NOT NULL
+    AND dispatched_at < CAST(sqlc.arg(dispatched_before) AS INTEGER);
+
+-- name: InsertWebhook :exec
+INSERT INTO webhooks
+    ( "id", "room_id", "url", "event_kinds", "secret", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? );
+
+-- name: GetWebhook :one
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    id = ?;
+
+-- name: GetRoomWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = ?
+ORDER BY created_at;
+
+-- name: GetGlobalWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id IS NULL
+ORDER BY created_at;
+
+-- name: GetEventWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "secret", "created_at"
+FROM webhooks
+WHERE
+    room_id = sqlc.arg(room_id)
+    OR room_id IS NULL
+ORDER BY created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE
+    id = ?;
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries
+    ( "id", "webhook_id", "event_kind", "payload", "next_attempt_at", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ?, ? );
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = CAST(sqlc.arg(lease_until) AS INTEGER)
+WHERE
+    id IN (
+        SELECT due.id FROM webhook_deliveries AS due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= CAST(sqlc.arg(now) AS INTEGER)
+        ORDER BY due.next_attempt_at
+        LIMIT sqlc.arg(max_deliveries)
+    )
+RETURNING "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at";
+
+-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = sqlc.arg(status),
+    attempts = attempts + 1,
+    response_status = sqlc.narg(response_status),
+    last_error = sqlc.arg(last_error),
+    completed_at = CAST(sqlc.arg(now) AS INTEGER)
+WHERE
+    id = sqlc.arg(id);
+
+-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    response_status = sqlc.narg(response_status),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = CAST(sqlc.arg(next_attempt_at) AS INTEGER)
+WHERE
+    id = sqlc.arg(id);
+
+-- name: GetWebhookDeliveries :many
+SELECT
+    "id", "webhook_id", "event_kind", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "created_at", "completed_at"
+FROM webhook_deliveries
+WHERE
+    webhook_id = sqlc.arg(webhook_id)
+    AND (CAST(sqlc.arg(status) AS TEXT) = '' OR status = CAST(sqlc.arg(status) AS TEXT))
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_deliveries);
+
+-- name: DeleteCompletedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE
+    completed_at IS NOT NULL
+    AND completed_at < CAST(sqlc.arg(completed_before) AS INTEGER);
+
+-- name: InsertApiKey :exec
+INSERT INTO api_keys
+    ( "id", "name", "prefix", "key_hash", "created_at" ) VALUES
+    ( ?, ?, ?, ?, ? );
+
+-- name: GetApiKeyByHash :one
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+WHERE
+    key_hash = ?;
+
+-- name: GetApiKeys :many
+SELECT
+    "id", "name", "prefix", "key_hash", "created_at"
+FROM api_keys
+ORDER BY created_at;
+
+-- name: DeleteApiKey :execrows
+DELETE FROM api_keys
+WHERE
+    id = ?;
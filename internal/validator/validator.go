@@ -13,6 +13,8 @@ import (
 
 func ValidateStruct(ctx context.Context, obj interface{}) error {
 	validate := validator.New()
+	// omitempty doesn't skip an empty string behind a pointer
+	validate.RegisterAlias("passcode", "eq=|min=4")
 	err := validate.Struct(obj)
 	if err == nil {
 		return nil
@@ -27,6 +29,9 @@ func ValidateStruct(ctx context.Context, obj interface{}) error {
 		key := fmt.Sprint(v.Tag())
 		field := fmt.Sprint(strings.ToLower(v.Field()[0:1]) + v.Field()[1:])
 		paramValue := fmt.Sprint(v.Param())
+		if strings.HasSuffix(key, "field") && paramValue != "" {
+			paramValue = strings.ToLower(paramValue[0:1]) + paramValue[1:]
+		}
 
 		message, err := locale.GetMessage(ctx, strings.ToUpper(key), field, paramValue)
 		if err != nil {
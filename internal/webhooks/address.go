@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrAddressNotAllowed is returned for the webhook URLs that lead to the
+// loopback, private, link-local or otherwise internal addresses, so a
+// webhook can't be used to reach the network the server runs in.
+var ErrAddressNotAllowed = errors.New("webhook address not allowed")
+
+// CheckURL resolves the host of a webhook URL and fails when any of its
+// addresses isn't public. The sender checks the address again when it
+// connects, the host may resolve differently by then.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrAddressNotAllowed, u.Hostname(), addr)
+		}
+	}
+	return nil
+}
+
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, internal too but not
+// reported by IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// dialPublic is the Control of the sender dialer, it refuses to connect to
+// the addresses CheckURL rejects.
+func dialPublic(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
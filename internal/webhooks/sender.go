@@ -0,0 +1,256 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/internal_errors"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+)
+
+const (
+	// DefaultInterval is how often the due deliveries are looked for.
+	DefaultInterval = time.Second
+
+	// The headers of a delivery. The signature is the hex HMAC-SHA256 of
+	// the timestamp, a dot and the body, keyed with the secret of the
+	// webhook, so a receiver can tell the body is ours and fresh.
+	EventHeader     = "X-Wsrs-Event"
+	DeliveryHeader  = "X-Wsrs-Delivery"
+	TimestampHeader = "X-Wsrs-Timestamp"
+	SignatureHeader = "X-Wsrs-Signature"
+
+	batchSize = 20
+	timeout   = 10 * time.Second
+	// lease keeps a claimed delivery from being claimed again while it is
+	// being sent. When the sender stops halfway the delivery is sent again
+	// once the lease runs out.
+	lease = time.Minute
+	// a failed delivery is retried after retryDelay, doubled on every
+	// attempt up to maxRetryDelay, and given up on after maxAttempts
+	retryDelay    = 10 * time.Second
+	maxRetryDelay = time.Hour
+	maxAttempts   = 8
+	// completed deliveries are kept for a while as the delivery log
+	retention  = 30 * 24 * time.Hour
+	pruneEvery = time.Hour
+)
+
+// Sender posts the due deliveries to their webhooks. A delivery counts as
+// delivered once the receiver answers with a 2xx status, anything else is
+// retried with an exponential backoff.
+type Sender struct {
+	repository repositories.WebhooksRepository
+	client     *http.Client
+	interval   time.Duration
+	stop       chan struct{}
+	done       chan struct{}
+	cancel     context.CancelFunc
+}
+
+func NewSender(repository repositories.WebhooksRepository, interval time.Duration) *Sender {
+	return &Sender{
+		repository: repository,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport(),
+			// a redirect isn't followed, the receiver is expected to
+			// answer at the URL it registered
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *Sender) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		var prunedAt time.Time
+		for {
+			s.send(ctx)
+			if time.Since(prunedAt) >= pruneEvery {
+				s.prune(ctx)
+				prunedAt = time.Now()
+			}
+
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the deliveries being sent. Once ctx is done they are
+// cancelled instead and sent again after their lease runs out.
+func (s *Sender) Stop(ctx context.Context) {
+	close(s.stop)
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+}
+
+func (s *Sender) send(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := s.repository.ClaimDueDeliveries(ctx, time.Now().Add(lease), batchSize)
+		if err != nil {
+			slog.Error("failed to claim webhook deliveries", "error", err)
+			return
+		}
+
+		// the receivers are independent, one slow receiver only delays
+		// its own deliveries
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery models.WebhookDelivery) {
+				defer wg.Done()
+				s.deliver(ctx, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+func (s *Sender) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	webhook, err := s.repository.FindWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		var notFound *internal_errors.ErrorNotFound
+		if !errors.As(err, &notFound) {
+			slog.Error("failed to find webhook of delivery", "id", delivery.ID, "webhook_id", delivery.WebhookID, "error", err)
+		}
+		// a deleted webhook takes its deliveries along
+		return
+	}
+
+	responseStatus, err := s.post(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		// stopping, the lease brings the delivery back later
+		return
+	}
+
+	if err == nil {
+		_ = s.repository.CompleteDelivery(ctx, delivery.ID, models.WebhookDeliveryDelivered, responseStatus, "")
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	slog.Warn("failed to deliver webhook", "id", delivery.ID, "webhook_id", webhook.ID, "attempt", attempts, "error", err)
+	if attempts >= maxAttempts {
+		// the repository logs its own errors
+		_ = s.repository.CompleteDelivery(ctx, delivery.ID, models.WebhookDeliveryFailed, responseStatus, err.Error())
+		return
+	}
+	_ = s.repository.RetryDelivery(ctx, delivery.ID, responseStatus, err.Error(), time.Now().Add(backoff(attempts)))
+}
+
+// post sends a delivery and returns the status the receiver answered with,
+// nil when it didn't answer at all.
+func (s *Sender) post(ctx context.Context, webhook *models.Webhook, delivery models.WebhookDelivery) (*int32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wsrs-webhooks")
+	req.Header.Set(EventHeader, delivery.EventKind)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	// drained so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	status := int32(res.StatusCode)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &status, fmt.Errorf("receiver answered %s", res.Status)
+	}
+	return &status, nil
+}
+
+// transport is the default transport with a dialer that only connects to
+// public addresses.
+func transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublic,
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the receiver
+	t.Proxy = nil
+	t.DialContext = dialer.DialContext
+	return t
+}
+
+// Sign returns the hex signature of a delivery body sent at timestamp.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns how long to wait before the next attempt after the given
+// number of failed ones.
+func backoff(attempts int32) time.Duration {
+	delay := retryDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func (s *Sender) prune(ctx context.Context) {
+	deleted, err := s.repository.DeleteCompletedDeliveries(ctx, time.Now().Add(-retention))
+	if err != nil {
+		slog.Error("failed to delete completed webhook deliveries", "error", err)
+	} else if deleted > 0 {
+		slog.Info("deleted completed webhook deliveries", "count", deleted)
+	}
+}
@@ -0,0 +1,234 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var testPayload = []byte(`{"kind":"message_created"}`)
+
+// newTestSender returns a sender posting to a receiver served by handler,
+// with one delivery due. The receiver listens on the loopback, so the
+// sender gets the client of the test server instead of its own.
+func newTestSender(t *testing.T, handler http.HandlerFunc) (*Sender, repositories.WebhooksRepository, *models.Webhook) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	repository := repositories.NewMemoryStore().Webhooks
+	webhook, err := repository.SaveWebhook(ctx, &models.Webhook{
+		URL:        server.URL,
+		EventKinds: []string{},
+		Secret:     testSecret,
+	})
+	if err != nil {
+		t.Fatalf("saving the webhook: %v", err)
+	}
+	if err := repository.SaveDelivery(ctx, webhook.ID, "message_created", testPayload); err != nil {
+		t.Fatalf("saving the delivery: %v", err)
+	}
+
+	sender := NewSender(repository, DefaultInterval)
+	sender.client = server.Client()
+	return sender, repository, webhook
+}
+
+func claimDelivery(t *testing.T, repository repositories.WebhooksRepository) models.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := repository.ClaimDueDeliveries(context.Background(), time.Now().Add(lease), batchSize)
+	if err != nil {
+		t.Fatalf("claiming the deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func findDelivery(t *testing.T, repository repositories.WebhooksRepository, webhook *models.Webhook) models.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := repository.FindDeliveries(context.Background(), webhook.ID, "", batchSize)
+	if err != nil {
+		t.Fatalf("finding the deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("found %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func TestSenderSignsDeliveries(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	sender, repository, webhook := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	sender.send(context.Background())
+
+	if received == nil {
+		t.Fatal("the receiver got no delivery")
+	}
+	if string(body) != string(testPayload) {
+		t.Errorf("body = %s, want %s", body, testPayload)
+	}
+	if got := received.Header.Get(EventHeader); got != "message_created" {
+		t.Errorf("%s = %q, want message_created", EventHeader, got)
+	}
+	timestamp := received.Header.Get(TimestampHeader)
+	if timestamp == "" {
+		t.Fatalf("%s is missing", TimestampHeader)
+	}
+	want := "sha256=" + Sign(testSecret, timestamp, testPayload)
+	if got := received.Header.Get(SignatureHeader); got != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+
+	delivery := findDelivery(t, repository, webhook)
+	if got := received.Header.Get(DeliveryHeader); got != delivery.ID.String() {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, delivery.ID)
+	}
+	if delivery.Status != models.WebhookDeliveryDelivered {
+		t.Errorf("status = %s, want %s", delivery.Status, models.WebhookDeliveryDelivered)
+	}
+	if delivery.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", delivery.Attempts)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("response status = %v, want %d", delivery.ResponseStatus, http.StatusNoContent)
+	}
+	if delivery.CompletedAt == nil {
+		t.Error("the delivery wasn't completed")
+	}
+}
+
+func TestSenderRetriesFailedDeliveries(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	sender, repository, webhook := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	attemptedAt := time.Now()
+	sender.deliver(context.Background(), claimDelivery(t, repository))
+
+	delivery := findDelivery(t, repository, webhook)
+	if delivery.Status != models.WebhookDeliveryPending {
+		t.Fatalf("status = %s, want %s", delivery.Status, models.WebhookDeliveryPending)
+	}
+	if delivery.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", delivery.Attempts)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Errorf("response status = %v, want %d", delivery.ResponseStatus, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(delivery.LastError, "503") {
+		t.Errorf("last error = %q, want the status of the receiver", delivery.LastError)
+	}
+	if next := delivery.NextAttemptAt.Sub(attemptedAt); next < retryDelay || next > retryDelay+time.Minute {
+		t.Errorf("next attempt in %s, want about %s", next, retryDelay)
+	}
+
+	failing.Store(false)
+	sender.deliver(context.Background(), delivery)
+
+	delivery = findDelivery(t, repository, webhook)
+	if delivery.Status != models.WebhookDeliveryDelivered {
+		t.Errorf("status = %s, want %s", delivery.Status, models.WebhookDeliveryDelivered)
+	}
+	if delivery.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", delivery.Attempts)
+	}
+	if delivery.LastError != "" {
+		t.Errorf("last error = %q, want none", delivery.LastError)
+	}
+}
+
+func TestSenderGivesUpAfterMaxAttempts(t *testing.T) {
+	sender, repository, webhook := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// the attempts before the last one are taken as done already
+	delivery := claimDelivery(t, repository)
+	delivery.Attempts = maxAttempts - 1
+	sender.deliver(context.Background(), delivery)
+
+	delivery = findDelivery(t, repository, webhook)
+	if delivery.Status != models.WebhookDeliveryFailed {
+		t.Errorf("status = %s, want %s", delivery.Status, models.WebhookDeliveryFailed)
+	}
+	if delivery.CompletedAt == nil {
+		t.Error("the delivery wasn't completed")
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("response status = %v, want %d", delivery.ResponseStatus, http.StatusInternalServerError)
+	}
+	if delivery.LastError == "" {
+		t.Error("the last error wasn't recorded")
+	}
+}
+
+func TestSenderRefusesInternalAddresses(t *testing.T) {
+	var received atomic.Bool
+	_, repository, webhook := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		received.Store(true)
+	})
+
+	// the sender with its own client, which doesn't dial the loopback
+	sender := NewSender(repository, DefaultInterval)
+	sender.deliver(context.Background(), claimDelivery(t, repository))
+
+	if received.Load() {
+		t.Error("the receiver on the loopback got the delivery")
+	}
+	delivery := findDelivery(t, repository, webhook)
+	if delivery.Status != models.WebhookDeliveryPending {
+		t.Errorf("status = %s, want %s", delivery.Status, models.WebhookDeliveryPending)
+	}
+	if delivery.ResponseStatus != nil {
+		t.Errorf("response status = %d, want none", *delivery.ResponseStatus)
+	}
+	if !strings.Contains(delivery.LastError, ErrAddressNotAllowed.Error()) {
+		t.Errorf("last error = %q, want %q", delivery.LastError, ErrAddressNotAllowed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{attempts: 1, want: retryDelay},
+		{attempts: 2, want: 2 * retryDelay},
+		{attempts: 3, want: 4 * retryDelay},
+		{attempts: 7, want: 64 * retryDelay},
+		{attempts: 20, want: maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
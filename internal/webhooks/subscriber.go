@@ -0,0 +1,80 @@
+// Package webhooks sends the room events to the URLs registered for them.
+// The events are turned into deliveries when the outbox dispatches them and
+// a sender posts the deliveries afterwards, so a slow or broken receiver
+// never holds back the other subscribers.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/JulioZittei/wsrs-ama-go/internal/events"
+	"github.com/JulioZittei/wsrs-ama-go/internal/models"
+	"github.com/JulioZittei/wsrs-ama-go/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// Payload is the body of a delivery. Data is the event as the services
+// published it.
+type Payload struct {
+	Kind       events.Kind  `json:"kind"`
+	RoomID     uuid.UUID    `json:"room_id"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Data       events.Event `json:"data"`
+}
+
+// Subscriber queues a delivery of each event for every webhook of its room
+// and for the global ones. The deliveries of an event are saved together,
+// so handing the event over again doesn't queue some of them twice.
+type Subscriber struct {
+	repository repositories.WebhooksRepository
+	unitOfWork repositories.UnitOfWork
+}
+
+func NewSubscriber(repository repositories.WebhooksRepository, unitOfWork repositories.UnitOfWork) *Subscriber {
+	return &Subscriber{
+		repository: repository,
+		unitOfWork: unitOfWork,
+	}
+}
+
+func (s *Subscriber) HandleEvent(ctx context.Context, event events.Event) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		webhooks, err := s.repository.FindEventWebhooks(ctx, event.Room())
+		if err != nil {
+			return err
+		}
+
+		var payload []byte
+		for _, webhook := range webhooks {
+			if !subscribed(webhook, event.Kind()) {
+				continue
+			}
+
+			if payload == nil {
+				payload, err = json.Marshal(Payload{
+					Kind:       event.Kind(),
+					RoomID:     event.Room(),
+					OccurredAt: time.Now().UTC(),
+					Data:       event,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := s.repository.SaveDelivery(ctx, webhook.ID, string(event.Kind()), payload); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// subscribed reports whether a webhook wants events of a kind. A webhook
+// without kinds wants all of them.
+func subscribed(webhook models.Webhook, kind events.Kind) bool {
+	return len(webhook.EventKinds) == 0 || slices.Contains(webhook.EventKinds, string(kind))
+}